@@ -0,0 +1,43 @@
+// Package blob keeps binary content, like the audio of recordings, apart from the dictionary store.
+package blob
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+)
+
+// ErrNotFound is returned when no content is stored under a key.
+var ErrNotFound = errors.New("blob not found")
+
+const defaultLocalDir = "blobs"
+
+// Store keeps content under keys chosen by its callers, see NewKey. Content is never changed once stored.
+type Store interface {
+	// Put stores the content read from r under key, returning its size.
+	Put(ctx context.Context, key string, r io.Reader) (int64, error)
+	// Open returns the content stored under key, which can be read in any order.
+	Open(ctx context.Context, key string) (io.ReadSeekCloser, error)
+	// Delete removes the content stored under key, deleting a missing key does nothing.
+	Delete(ctx context.Context, key string) error
+}
+
+// NewKey returns a random key, which no other content gets.
+func NewKey() string {
+	key := make([]byte, 16)
+	rand.Read(key)
+	return hex.EncodeToString(key)
+}
+
+// FromEnv opens the store configured in the environment, the directory named by BLOB_DIR,
+// which defaults to blobs.
+func FromEnv() (Store, error) {
+	dir := os.Getenv("BLOB_DIR")
+	if dir == "" {
+		dir = defaultLocalDir
+	}
+	return NewLocal(dir)
+}
@@ -0,0 +1,97 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Local keeps content as files of a directory on the local filesystem, a file per key.
+type Local struct {
+	dir string
+}
+
+// NewLocal returns a store keeping content in dir, which is created if missing.
+func NewLocal(dir string) (*Local, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create blob directory: %w", err)
+	}
+	return &Local{dir: dir}, nil
+}
+
+// path returns the file of the key. Keys are single file names, so content is never stored outside of the directory.
+func (l *Local) path(key string) (string, error) {
+	if key == "" || strings.ContainsAny(key, `/\`) || !filepath.IsLocal(key) || strings.HasPrefix(key, ".") {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+	return filepath.Join(l.dir, key), nil
+}
+
+// Put writes the content to a temporary file first, so a failed or concurrent Put never leaves partial content
+// under the key.
+func (l *Local) Put(ctx context.Context, key string, r io.Reader) (int64, error) {
+	path, err := l.path(key)
+	if err != nil {
+		return 0, err
+	}
+	file, err := os.CreateTemp(l.dir, ".upload-*")
+	if err != nil {
+		return 0, fmt.Errorf("failed to store blob %s: %w", key, err)
+	}
+	defer os.Remove(file.Name())
+
+	size, err := io.Copy(file, readerWithContext{ctx, r})
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to store blob %s: %w", key, err)
+	}
+	if err := os.Rename(file.Name(), path); err != nil {
+		return 0, fmt.Errorf("failed to store blob %s: %w", key, err)
+	}
+	return size, nil
+}
+
+func (l *Local) Open(ctx context.Context, key string) (io.ReadSeekCloser, error) {
+	path, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to open blob %s: %w", key, err)
+	}
+	return file, nil
+}
+
+func (l *Local) Delete(ctx context.Context, key string) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete blob %s: %w", key, err)
+	}
+	return nil
+}
+
+// readerWithContext stops reading once its context is done, e.g. when an upload is abandoned.
+type readerWithContext struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r readerWithContext) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
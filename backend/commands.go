@@ -0,0 +1,231 @@
+package main
+
+import (
+	"backend/database"
+	"backend/exchange"
+	"backend/graph/model"
+	"backend/store"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// runCommand runs a maintenance command given after the flags instead of starting the server.
+func runCommand(args []string) {
+	switch args[0] {
+	case "migrate":
+		runMigrate(args[1:])
+	case "import":
+		runImport(args[1:])
+	case "export":
+		runExport(args[1:])
+	case "dump":
+		runDump(args[1:])
+	case "restore":
+		runRestore(args[1:])
+	default:
+		log.Fatalf("unknown command %q, use migrate, import, export, dump or restore", args[0])
+	}
+}
+
+// runMigrate handles "migrate up", "migrate down [steps]" and "migrate status" on the database configured by DB_DRIVER.
+func runMigrate(args []string) {
+	if len(args) == 0 {
+		log.Fatal("usage: migrate up | down [steps] | status")
+	}
+	db, err := database.Dial(database.ConfigFromEnv())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := database.MigrateUp(db)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, migration := range applied {
+			fmt.Printf("applied %04d_%s\n", migration.Version, migration.Name)
+		}
+		if len(applied) == 0 {
+			fmt.Println("no pending migrations")
+		}
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				log.Fatalf("invalid number of steps %q", args[1])
+			}
+		}
+		reverted, err := database.MigrateDown(db, steps)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, migration := range reverted {
+			fmt.Printf("reverted %04d_%s\n", migration.Version, migration.Name)
+		}
+		if len(reverted) == 0 {
+			fmt.Println("no applied migrations")
+		}
+	case "status":
+		statuses, err := database.MigrationStatuses(db)
+		if err != nil {
+			log.Fatal(err)
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, status := range statuses {
+			state := "pending"
+			if status.AppliedAt != nil {
+				state = "applied " + status.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%04d_%s\t%s\n", status.Version, status.Name, state)
+		}
+		w.Flush()
+	default:
+		log.Fatalf("unknown migrate command %q, use up, down or status", args[0])
+	}
+}
+
+// runImport handles "import [-format csv|tsv|tmx|tbx] [-dry-run] FILE", loading translation pairs into the database.
+func runImport(args []string) {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	format := flags.String("format", "csv", "format of the file, csv, tsv, tmx or tbx")
+	dryRun := flags.Bool("dry-run", false, "report what would be imported without storing anything")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		log.Fatal("usage: import [-format csv|tsv|tmx|tbx] [-dry-run] FILE")
+	}
+	fileFormat := model.TranslationFileFormat(strings.ToUpper(*format))
+	if !fileFormat.IsValid() {
+		log.Fatalf("unsupported format %q, use csv, tsv, tmx or tbx", *format)
+	}
+
+	file, err := os.Open(flags.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer file.Close()
+
+	s := store.NewGorm(database.Connect(), 0)
+	report, err := exchange.ImportTranslations(context.Background(), s, file, fileFormat, *dryRun)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, issue := range report.Issues {
+		fmt.Printf("line %d: %s: %s\n", issue.Line, strings.ToLower(string(issue.Status)), issue.Message)
+	}
+	if report.DryRun {
+		fmt.Print("dry run, nothing stored: ")
+	}
+	fmt.Printf("%d created, %d duplicates, %d invalid\n", report.Created, report.Duplicates, report.Invalid)
+}
+
+// exportFormats maps the formats of the export command to the functions writing them.
+var exportFormats = map[string]func(context.Context, store.DictionaryStore, io.Writer) error{
+	"tmx": exchange.ExportTMX,
+	"tbx": exchange.ExportTBX,
+}
+
+// runExport handles "export [-format tmx|tbx|anki|csv] [-source LANGUAGE] [-target LANGUAGE] [-collection NAME] [-o FILE]",
+// writing the dictionary to FILE or to the standard output. Anki decks need the language pair, CSV files are made of
+// the translations of a collection, into the target language when it is given.
+func runExport(args []string) {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	format := flags.String("format", "tmx", "format of the file, tmx, tbx, anki or csv")
+	output := flags.String("o", "", "file to write, the standard output by default")
+	sourceLanguage := flags.String("source", "", "language of the front of anki cards")
+	targetLanguage := flags.String("target", "", "language of the back of anki cards or of the translations in csv files")
+	collection := flags.String("collection", "", "collection to export as csv")
+	flags.Parse(args)
+	if flags.NArg() != 0 {
+		log.Fatal("usage: export [-format tmx|tbx|anki|csv] [-source LANGUAGE] [-target LANGUAGE] [-collection NAME] [-o FILE]")
+	}
+	export, ok := exportFormats[strings.ToLower(*format)]
+	if strings.EqualFold(*format, "anki") {
+		if *sourceLanguage == "" || *targetLanguage == "" {
+			log.Fatal("anki export needs -source and -target languages")
+		}
+		export = func(ctx context.Context, s store.DictionaryStore, w io.Writer) error {
+			return exchange.ExportAnki(ctx, s, w, *sourceLanguage, *targetLanguage)
+		}
+	} else if strings.EqualFold(*format, "csv") {
+		if *collection == "" {
+			log.Fatal("csv export needs a -collection")
+		}
+		export = func(ctx context.Context, s store.DictionaryStore, w io.Writer) error {
+			return exchange.ExportCollection(ctx, s, w, *collection, *targetLanguage)
+		}
+	} else if !ok {
+		log.Fatalf("unsupported format %q, use tmx, tbx, anki or csv", *format)
+	}
+
+	writeOutput(*output, export)
+}
+
+// writeOutput writes the dictionary in the database with write to the file named output, or to the standard output.
+func writeOutput(output string, write func(context.Context, store.DictionaryStore, io.Writer) error) {
+	s := store.NewGorm(database.Connect(), 0)
+	if output == "" {
+		if err := write(context.Background(), s, os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	file, err := os.Create(output)
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = write(context.Background(), s, file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// runDump handles "dump [-o FILE]", writing a backup of the database as JSON Lines. The dump is read from one
+// transaction, which stays open while it is written, so no statement timeout applies to it.
+func runDump(args []string) {
+	flags := flag.NewFlagSet("dump", flag.ExitOnError)
+	output := flags.String("o", "", "file to write, the standard output by default")
+	flags.Parse(args)
+	if flags.NArg() != 0 {
+		log.Fatal("usage: dump [-o FILE], recordings are left out, back up the blob store along with the dump")
+	}
+	writeOutput(*output, exchange.Dump)
+}
+
+// runRestore handles "restore FILE", loading a dump into the database. "-" reads the dump from the standard input.
+func runRestore(args []string) {
+	if len(args) != 1 {
+		log.Fatal("usage: restore FILE, recordings are not in dumps, restore the blob store along with the dump")
+	}
+	var r io.Reader = os.Stdin
+	if args[0] != "-" {
+		file, err := os.Open(args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+		r = file
+	}
+
+	s := store.NewGorm(database.Connect(), 0)
+	stats, err := exchange.Restore(context.Background(), s, r)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("%d languages (%d new), %d words (%d new), %d senses (%d new), %d examples (%d new), %d pronunciations (%d new), %d collections (%d new), %d translations (%d new)\n",
+		stats.Languages, stats.LanguagesCreated, stats.Words, stats.WordsCreated, stats.Senses, stats.SensesCreated,
+		stats.Examples, stats.ExamplesCreated, stats.Pronunciations, stats.PronunciationsCreated,
+		stats.Collections, stats.CollectionsCreated, stats.Translations, stats.TranslationsCreated)
+}
@@ -1,48 +1,173 @@
 package database
 
 import (
-	"backend/graph/model"
+	"backend/store"
+	"context"
 	"fmt"
-	"gorm.io/driver/postgres"
-	"gorm.io/gorm"
 	"log"
+	"net/url"
 	"os"
 	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
 )
 
 var DB *gorm.DB
 
-func Connect() *gorm.DB {
-	dbString := "host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=UTC"
-	host := os.Getenv("POSTGRES_HOST")
-	user := os.Getenv("POSTGRES_USER")
-	password := os.Getenv("POSTGRES_PASSWORD")
-	dbName := os.Getenv("POSTGRES_NAME")
-	dbPort := os.Getenv("POSTGRES_PORT")
+const (
+	defaultStatementTimeout = 10 * time.Second
+	defaultSQLitePath       = "dictionary.db"
+	// sqliteBusyTimeout is how long SQLite waits for a lock held by another process, e.g. a CLI command.
+	sqliteBusyTimeout = 5 * time.Second
+)
+
+// Supported values of DB_DRIVER.
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// Config selects the database to connect to.
+type Config struct {
+	Driver string
+	// Postgres connection settings.
+	Host     string
+	Port     string
+	User     string
+	Password string
+	Name     string
+	// SQLitePath is the SQLite database file, created if missing. ":memory:" keeps the database in memory.
+	SQLitePath string
+}
+
+// ConfigFromEnv reads the configuration from DB_DRIVER, which defaults to postgres, POSTGRES_* and SQLITE_PATH.
+func ConfigFromEnv() Config {
+	config := Config{
+		Driver:     os.Getenv("DB_DRIVER"),
+		Host:       os.Getenv("POSTGRES_HOST"),
+		Port:       os.Getenv("POSTGRES_PORT"),
+		User:       os.Getenv("POSTGRES_USER"),
+		Password:   os.Getenv("POSTGRES_PASSWORD"),
+		Name:       os.Getenv("POSTGRES_NAME"),
+		SQLitePath: os.Getenv("SQLITE_PATH"),
+	}
+	if config.Driver == "" {
+		config.Driver = DriverPostgres
+	}
+	if config.SQLitePath == "" {
+		config.SQLitePath = defaultSQLitePath
+	}
+	return config
+}
 
-	dsn := fmt.Sprintf(dbString, host, user, password, dbName, dbPort)
+// StatementTimeout returns the limit of database work of a single operation, read from DB_STATEMENT_TIMEOUT,
+// e.g. "5s" or "500ms". "0" disables the limit.
+func StatementTimeout() time.Duration {
+	value := os.Getenv("DB_STATEMENT_TIMEOUT")
+	if value == "" {
+		return defaultStatementTimeout
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout < 0 {
+		log.Fatalf("invalid DB_STATEMENT_TIMEOUT %q", value)
+	}
+	return timeout
+}
 
+// Connect opens the database configured in the environment and sets DB to it.
+func Connect() *gorm.DB {
 	var err error
-	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	DB, err = Open(ConfigFromEnv())
 	if err != nil {
 		log.Fatal(err)
 	}
+	return DB
+}
 
-	fmt.Println("Connected to database")
+// Open connects to the database, applies pending migrations and registers the default languages.
+func Open(config Config) (*gorm.DB, error) {
+	db, err := Dial(config)
+	if err != nil {
+		return nil, err
+	}
 
-	sqlDB, err := DB.DB()
+	applied, err := MigrateUp(db)
 	if err != nil {
-		log.Fatal("failed to get SQL DB", err)
+		return nil, err
+	}
+	for _, migration := range applied {
+		log.Printf("applied migration %d_%s", migration.Version, migration.Name)
+	}
+
+	err = SeedLanguages(context.Background(), store.NewGorm(db, 0))
+	if err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// Dial connects to the database without touching its schema.
+func Dial(config Config) (*gorm.DB, error) {
+	var db *gorm.DB
+	var err error
+	switch config.Driver {
+	case DriverPostgres:
+		db, err = openPostgres(config)
+	case DriverSQLite:
+		db, err = openSQLite(config)
+	default:
+		return nil, fmt.Errorf("unsupported DB_DRIVER %q, use %s or %s", config.Driver, DriverPostgres, DriverSQLite)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	log.Println("Connected to database")
+	return db, nil
+}
+
+func openPostgres(config Config) (*gorm.DB, error) {
+	dbString := "host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=UTC statement_timeout=%d"
+	dsn := fmt.Sprintf(dbString, config.Host, config.User, config.Password, config.Name, config.Port, StatementTimeout().Milliseconds())
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true})
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get SQL DB: %w", err)
 	}
 
 	sqlDB.SetMaxOpenConns(100)
 	sqlDB.SetMaxIdleConns(10)
 	sqlDB.SetConnMaxLifetime(2 * time.Hour)
+	return db, nil
+}
 
-	err = DB.AutoMigrate(&model.Word{})
+// openSQLite opens the database file with foreign keys enforced and LIKE comparing case sensitively, as in Postgres.
+func openSQLite(config Config) (*gorm.DB, error) {
+	pragmas := url.Values{}
+	pragmas.Add("_pragma", "foreign_keys(1)")
+	pragmas.Add("_pragma", "case_sensitive_like(1)")
+	pragmas.Add("_pragma", fmt.Sprintf("busy_timeout(%d)", sqliteBusyTimeout.Milliseconds()))
+	pragmas.Add("_pragma", "journal_mode(WAL)")
+
+	db, err := gorm.Open(sqlite.Open(config.SQLitePath+"?"+pragmas.Encode()), &gorm.Config{TranslateError: true})
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 
-	return DB
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get SQL DB: %w", err)
+	}
+
+	// SQLite allows a single writer, a single connection queues transactions instead of failing them as busy.
+	sqlDB.SetMaxOpenConns(1)
+	return db, nil
 }
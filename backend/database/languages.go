@@ -0,0 +1,48 @@
+package database
+
+import (
+	"backend/graph/model"
+	"backend/store"
+	"context"
+	"fmt"
+)
+
+// defaultLanguages are registered on startup, more can be added with the addLanguage mutation.
+var defaultLanguages = []model.Language{
+	{Code: "ar", Name: "Arabic", NativeName: "العربية", Script: "Arab", Direction: model.TextDirectionRtl},
+	{Code: "cs", Name: "Czech", NativeName: "čeština", Script: "Latn", Direction: model.TextDirectionLtr},
+	{Code: "de", Name: "German", NativeName: "Deutsch", Script: "Latn", Direction: model.TextDirectionLtr},
+	{Code: "el", Name: "Greek", NativeName: "Ελληνικά", Script: "Grek", Direction: model.TextDirectionLtr},
+	{Code: "en", Name: "English", NativeName: "English", Script: "Latn", Direction: model.TextDirectionLtr},
+	{Code: "es", Name: "Spanish", NativeName: "español", Script: "Latn", Direction: model.TextDirectionLtr},
+	{Code: "fr", Name: "French", NativeName: "français", Script: "Latn", Direction: model.TextDirectionLtr},
+	{Code: "he", Name: "Hebrew", NativeName: "עברית", Script: "Hebr", Direction: model.TextDirectionRtl},
+	{Code: "hi", Name: "Hindi", NativeName: "हिन्दी", Script: "Deva", Direction: model.TextDirectionLtr},
+	{Code: "it", Name: "Italian", NativeName: "italiano", Script: "Latn", Direction: model.TextDirectionLtr},
+	{Code: "ja", Name: "Japanese", NativeName: "日本語", Script: "Jpan", Direction: model.TextDirectionLtr},
+	{Code: "ko", Name: "Korean", NativeName: "한국어", Script: "Kore", Direction: model.TextDirectionLtr},
+	{Code: "nl", Name: "Dutch", NativeName: "Nederlands", Script: "Latn", Direction: model.TextDirectionLtr},
+	{Code: "pl", Name: "Polish", NativeName: "polski", Script: "Latn", Direction: model.TextDirectionLtr},
+	{Code: "pt", Name: "Portuguese", NativeName: "português", Script: "Latn", Direction: model.TextDirectionLtr},
+	{Code: "ru", Name: "Russian", NativeName: "русский", Script: "Cyrl", Direction: model.TextDirectionLtr},
+	{Code: "sv", Name: "Swedish", NativeName: "svenska", Script: "Latn", Direction: model.TextDirectionLtr},
+	{Code: "tr", Name: "Turkish", NativeName: "Türkçe", Script: "Latn", Direction: model.TextDirectionLtr},
+	{Code: "uk", Name: "Ukrainian", NativeName: "українська", Script: "Cyrl", Direction: model.TextDirectionLtr},
+	{Code: "zh", Name: "Chinese", NativeName: "中文", Script: "Hans", Direction: model.TextDirectionLtr},
+}
+
+// SeedLanguages registers the default languages missing in s.
+func SeedLanguages(ctx context.Context, s store.DictionaryStore) error {
+	err := s.Transaction(ctx, func(tx store.DictionaryStore) error {
+		for _, language := range defaultLanguages {
+			if _, err := tx.AddLanguage(ctx, &language); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to seed languages: %w", err)
+	}
+	return nil
+}
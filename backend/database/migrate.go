@@ -0,0 +1,295 @@
+package database
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:embed migrations
+var migrationFiles embed.FS
+
+// migrationLockID identifies the Postgres advisory lock held while migrating,
+// so that replicas starting together apply every migration once.
+const migrationLockID = 7_318_046_215
+
+// migrationFileName matches files like "0002_words_text_trigram_index.up.sql".
+var migrationFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration is a versioned schema change, migrations of a database driver are kept in migrations/<driver>.
+// Data migrations written in Go are listed in dataMigrations instead and apply to every driver.
+type Migration struct {
+	Version int
+	Name    string
+	up      string
+	down    string
+	// apply migrates data in place of the up script. Reverting a data migration only forgets it was applied.
+	apply func(tx *gorm.DB) error
+}
+
+// dataMigrations are migrations of data which SQL cannot express, ordered among the SQL migrations by version.
+var dataMigrations = []Migration{
+	{Version: 7, Name: "normalize_words", apply: normalizeWords},
+}
+
+// MigrationStatus tells whether a migration was applied, AppliedAt is nil for pending ones.
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+type schemaMigration struct {
+	Version   int
+	Name      string
+	AppliedAt time.Time
+}
+
+// Migrations returns the migrations of the driver of db, ordered by version.
+func Migrations(db *gorm.DB) ([]Migration, error) {
+	dir := path.Join("migrations", db.Dialector.Name())
+	entries, err := fs.ReadDir(migrationFiles, dir)
+	if err != nil {
+		return nil, fmt.Errorf("no migrations for %s: %w", db.Dialector.Name(), err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		match := migrationFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("malformed migration file name %q", entry.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+		content, err := fs.ReadFile(migrationFiles, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d is named both %q and %q", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.up = string(content)
+		} else {
+			migration.down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.up == "" || migration.down == "" {
+			return nil, fmt.Errorf("migration %d needs both up and down files", migration.Version)
+		}
+		migrations = append(migrations, *migration)
+	}
+	for _, migration := range dataMigrations {
+		if clash, ok := byVersion[migration.Version]; ok {
+			return nil, fmt.Errorf("migration %d is named both %q and %q", migration.Version, clash.Name, migration.Name)
+		}
+		migrations = append(migrations, migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// MigrateUp applies pending migrations in order and returns them.
+func MigrateUp(db *gorm.DB) ([]Migration, error) {
+	migrations, err := Migrations(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []Migration
+	err = withMigrationLock(db, func(conn *gorm.DB) error {
+		done, err := appliedMigrations(conn)
+		if err != nil {
+			return err
+		}
+		for _, migration := range migrations {
+			if _, ok := done[migration.Version]; ok {
+				continue
+			}
+			ran, err := runMigration(conn, migration, true)
+			if err != nil {
+				return err
+			}
+			if ran {
+				applied = append(applied, migration)
+			}
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// MigrateDown reverts up to steps most recently applied migrations, latest first, and returns them.
+func MigrateDown(db *gorm.DB, steps int) ([]Migration, error) {
+	migrations, err := Migrations(db)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[int]Migration, len(migrations))
+	for _, migration := range migrations {
+		known[migration.Version] = migration
+	}
+
+	var reverted []Migration
+	err = withMigrationLock(db, func(conn *gorm.DB) error {
+		done, err := appliedMigrations(conn)
+		if err != nil {
+			return err
+		}
+		versions := make([]int, 0, len(done))
+		for version := range done {
+			versions = append(versions, version)
+		}
+		sort.Sort(sort.Reverse(sort.IntSlice(versions)))
+
+		for _, version := range versions {
+			if len(reverted) == steps {
+				break
+			}
+			migration, ok := known[version]
+			if !ok {
+				return fmt.Errorf("migration %d is applied but unknown to this binary, revert it with the binary which applied it", version)
+			}
+			ran, err := runMigration(conn, migration, false)
+			if err != nil {
+				return err
+			}
+			if ran {
+				reverted = append(reverted, migration)
+			}
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// MigrationStatuses lists the migrations of the driver of db along with when they were applied.
+func MigrationStatuses(db *gorm.DB) ([]MigrationStatus, error) {
+	migrations, err := Migrations(db)
+	if err != nil {
+		return nil, err
+	}
+	if err := createMigrationsTable(db); err != nil {
+		return nil, err
+	}
+	done, err := appliedMigrations(db)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, len(migrations))
+	for i, migration := range migrations {
+		statuses[i] = MigrationStatus{Migration: migration}
+		if applied, ok := done[migration.Version]; ok {
+			statuses[i].AppliedAt = &applied.AppliedAt
+		}
+	}
+	return statuses, nil
+}
+
+// withMigrationLock runs fn on a single connection. In Postgres the connection holds an advisory lock meanwhile.
+// SQLite has no such lock, there runMigration claims each migration in the transaction applying it instead.
+func withMigrationLock(db *gorm.DB, fn func(conn *gorm.DB) error) error {
+	return db.Connection(func(conn *gorm.DB) error {
+		if conn.Dialector.Name() == DriverPostgres {
+			err := conn.Exec("SELECT pg_advisory_lock(?)", migrationLockID).Error
+			if err != nil {
+				return fmt.Errorf("failed to acquire migration lock: %w", err)
+			}
+			defer conn.Exec("SELECT pg_advisory_unlock(?)", migrationLockID)
+		}
+		if err := createMigrationsTable(conn); err != nil {
+			return err
+		}
+		return fn(conn)
+	})
+}
+
+func createMigrationsTable(db *gorm.DB) error {
+	err := db.Exec("CREATE TABLE IF NOT EXISTS schema_migrations (version bigint PRIMARY KEY, name text NOT NULL, applied_at timestamp NOT NULL)").Error
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+	return nil
+}
+
+func appliedMigrations(db *gorm.DB) (map[int]schemaMigration, error) {
+	var rows []schemaMigration
+	err := db.Table("schema_migrations").Order("version").Find(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list applied migrations: %w", err)
+	}
+	applied := make(map[int]schemaMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+	return applied, nil
+}
+
+// runMigration applies or reverts the migration in a transaction. The schema_migrations row is written first,
+// which locks SQLite for writing, and the migration is skipped when another process got to it before.
+func runMigration(db *gorm.DB, migration Migration, up bool) (bool, error) {
+	ran := false
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var result *gorm.DB
+		if up {
+			result = tx.Exec("INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?) ON CONFLICT DO NOTHING",
+				migration.Version, migration.Name, time.Now().UTC())
+		} else {
+			result = tx.Exec("DELETE FROM schema_migrations WHERE version = ?", migration.Version)
+		}
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		if tx.Dialector.Name() == DriverPostgres {
+			// Migrations may rebuild large tables, they are not bound by the statement timeout of requests.
+			if err := tx.Exec("SET LOCAL statement_timeout = 0").Error; err != nil {
+				return err
+			}
+		}
+		switch {
+		case migration.apply != nil:
+			// A new session drops clauses the connection was left with, e.g. by appliedMigrations.
+			if up {
+				if err := migration.apply(tx.Session(&gorm.Session{NewDB: true})); err != nil {
+					return err
+				}
+			}
+		case up:
+			if err := tx.Exec(migration.up).Error; err != nil {
+				return err
+			}
+		default:
+			if err := tx.Exec(migration.down).Error; err != nil {
+				return err
+			}
+		}
+		ran = true
+		return nil
+	})
+	if err != nil {
+		direction := "down"
+		if up {
+			direction = "up"
+		}
+		return false, fmt.Errorf("migration %d_%s %s failed: %w", migration.Version, migration.Name, direction, err)
+	}
+	return ran, nil
+}
@@ -0,0 +1,3 @@
+-- Similarity search used by word suggestions.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX IF NOT EXISTS idx_words_text_trgm ON words USING gin (text gin_trgm_ops);
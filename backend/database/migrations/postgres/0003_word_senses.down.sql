@@ -0,0 +1,14 @@
+-- Translations between senses of the same words collapse into one translation between the words.
+DROP INDEX IF EXISTS idx_translations_translation;
+DROP INDEX IF EXISTS idx_translations_word;
+ALTER TABLE translations DROP CONSTRAINT translations_pkey;
+DELETE FROM translations AS duplicate
+	USING translations AS kept
+	WHERE duplicate.word_id = kept.word_id
+		AND duplicate.translation_id = kept.translation_id
+		AND duplicate.ctid > kept.ctid;
+ALTER TABLE translations
+	DROP COLUMN sense_id,
+	DROP COLUMN translation_sense_id,
+	ADD PRIMARY KEY (word_id, translation_id);
+DROP TABLE IF EXISTS senses;
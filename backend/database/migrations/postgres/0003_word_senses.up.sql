@@ -0,0 +1,32 @@
+-- Senses tell apart the meanings of a word, e.g. "run" as a verb and as a noun. Translations link senses,
+-- keeping the IDs of their words for lookups which do not care about senses.
+CREATE TABLE IF NOT EXISTS senses (
+	id bigserial PRIMARY KEY,
+	word_id bigint NOT NULL,
+	part_of_speech text NOT NULL DEFAULT '',
+	gloss text NOT NULL DEFAULT '',
+	register text NOT NULL DEFAULT '',
+	CONSTRAINT fk_senses_word FOREIGN KEY (word_id) REFERENCES words (id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_senses_word_meaning ON senses (word_id, part_of_speech, gloss);
+
+-- Every word gets a default sense, without part of speech and gloss, existing translations link those.
+INSERT INTO senses (word_id) SELECT id FROM words;
+
+ALTER TABLE translations
+	ADD COLUMN sense_id bigint,
+	ADD COLUMN translation_sense_id bigint;
+UPDATE translations SET
+	sense_id = (SELECT id FROM senses WHERE senses.word_id = translations.word_id),
+	translation_sense_id = (SELECT id FROM senses WHERE senses.word_id = translations.translation_id);
+ALTER TABLE translations
+	ALTER COLUMN word_id SET NOT NULL,
+	ALTER COLUMN translation_id SET NOT NULL,
+	ALTER COLUMN sense_id SET NOT NULL,
+	ALTER COLUMN translation_sense_id SET NOT NULL,
+	DROP CONSTRAINT translations_pkey,
+	ADD PRIMARY KEY (sense_id, translation_sense_id),
+	ADD CONSTRAINT fk_translations_sense FOREIGN KEY (sense_id) REFERENCES senses (id) ON DELETE CASCADE ON UPDATE CASCADE,
+	ADD CONSTRAINT fk_translations_translation_sense FOREIGN KEY (translation_sense_id) REFERENCES senses (id) ON DELETE CASCADE ON UPDATE CASCADE;
+CREATE INDEX IF NOT EXISTS idx_translations_word ON translations (word_id, translation_id);
+CREATE INDEX IF NOT EXISTS idx_translations_translation ON translations (translation_id);
@@ -0,0 +1,19 @@
+-- Examples show a word in use, in one of its senses when sense_id is set. Examples linked by
+-- translated_example_id are translations of each other, each of the two links the other.
+CREATE TABLE IF NOT EXISTS examples (
+	id bigserial PRIMARY KEY,
+	word_id bigint NOT NULL,
+	sense_id bigint,
+	text text NOT NULL,
+	language text NOT NULL,
+	source text NOT NULL DEFAULT '',
+	translated_example_id bigint,
+	CONSTRAINT fk_examples_word FOREIGN KEY (word_id) REFERENCES words (id) ON DELETE CASCADE ON UPDATE CASCADE,
+	CONSTRAINT fk_examples_sense FOREIGN KEY (sense_id) REFERENCES senses (id) ON DELETE SET NULL ON UPDATE CASCADE,
+	CONSTRAINT fk_examples_translated_example FOREIGN KEY (translated_example_id) REFERENCES examples (id) ON DELETE SET NULL ON UPDATE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_examples_word ON examples (word_id);
+
+-- Example usages become the first examples of their words, in the language of the word.
+INSERT INTO examples (word_id, text, language)
+	SELECT id, TRIM(example_usage), language FROM words WHERE TRIM(example_usage) <> '' ORDER BY id;
@@ -0,0 +1,3 @@
+-- Audio of recordings is left in the blob store.
+DROP TABLE IF EXISTS recordings;
+DROP TABLE IF EXISTS pronunciations;
@@ -0,0 +1,7 @@
+-- Words get the first of their examples in their language as example usage.
+ALTER TABLE words ADD COLUMN example_usage text;
+UPDATE words SET example_usage = COALESCE((
+	SELECT text FROM examples
+	WHERE examples.word_id = words.id AND examples.language = words.language
+	ORDER BY id LIMIT 1
+), '');
@@ -0,0 +1,10 @@
+-- Example usages are examples of their words. Usages written to words since examples were added become
+-- examples too, unless the word has the same example, then words no longer keep them.
+INSERT INTO examples (word_id, text, language)
+	SELECT id, TRIM(example_usage), language FROM words
+	WHERE TRIM(example_usage) <> '' AND NOT EXISTS (
+		SELECT 1 FROM examples
+		WHERE examples.word_id = words.id AND examples.language = words.language AND examples.text = TRIM(words.example_usage)
+	)
+	ORDER BY id;
+ALTER TABLE words DROP COLUMN example_usage;
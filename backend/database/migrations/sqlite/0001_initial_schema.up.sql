@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS words (
+	id integer PRIMARY KEY AUTOINCREMENT,
+	text text NOT NULL,
+	display_text text NOT NULL DEFAULT '',
+	language text NOT NULL,
+	example_usage text
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_text_language ON words (text, language);
+
+CREATE TABLE IF NOT EXISTS translations (
+	word_id integer,
+	translation_id integer,
+	PRIMARY KEY (word_id, translation_id),
+	CONSTRAINT fk_translations_word FOREIGN KEY (word_id) REFERENCES words (id) ON DELETE CASCADE ON UPDATE CASCADE,
+	CONSTRAINT fk_translations_translations FOREIGN KEY (translation_id) REFERENCES words (id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS languages (
+	code text PRIMARY KEY,
+	name text NOT NULL,
+	native_name text,
+	script text,
+	direction text NOT NULL DEFAULT 'LTR',
+	case_sensitive numeric NOT NULL DEFAULT false
+);
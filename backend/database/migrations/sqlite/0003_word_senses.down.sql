@@ -0,0 +1,13 @@
+-- Translations between senses of the same words collapse into one translation between the words.
+CREATE TABLE translations_by_word (
+	word_id integer,
+	translation_id integer,
+	PRIMARY KEY (word_id, translation_id),
+	CONSTRAINT fk_translations_word FOREIGN KEY (word_id) REFERENCES words (id) ON DELETE CASCADE ON UPDATE CASCADE,
+	CONSTRAINT fk_translations_translations FOREIGN KEY (translation_id) REFERENCES words (id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+INSERT INTO translations_by_word (word_id, translation_id)
+	SELECT DISTINCT word_id, translation_id FROM translations;
+DROP TABLE translations;
+ALTER TABLE translations_by_word RENAME TO translations;
+DROP TABLE IF EXISTS senses;
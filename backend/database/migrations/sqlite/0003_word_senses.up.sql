@@ -0,0 +1,36 @@
+-- Senses tell apart the meanings of a word, e.g. "run" as a verb and as a noun. Translations link senses,
+-- keeping the IDs of their words for lookups which do not care about senses.
+CREATE TABLE IF NOT EXISTS senses (
+	id integer PRIMARY KEY AUTOINCREMENT,
+	word_id integer NOT NULL,
+	part_of_speech text NOT NULL DEFAULT '',
+	gloss text NOT NULL DEFAULT '',
+	register text NOT NULL DEFAULT '',
+	CONSTRAINT fk_senses_word FOREIGN KEY (word_id) REFERENCES words (id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_senses_word_meaning ON senses (word_id, part_of_speech, gloss);
+
+-- Every word gets a default sense, without part of speech and gloss, existing translations link those.
+INSERT INTO senses (word_id) SELECT id FROM words;
+
+-- SQLite cannot change the primary key of a table, so translations are copied to a new one.
+CREATE TABLE translations_by_sense (
+	word_id integer NOT NULL,
+	translation_id integer NOT NULL,
+	sense_id integer NOT NULL,
+	translation_sense_id integer NOT NULL,
+	PRIMARY KEY (sense_id, translation_sense_id),
+	CONSTRAINT fk_translations_word FOREIGN KEY (word_id) REFERENCES words (id) ON DELETE CASCADE ON UPDATE CASCADE,
+	CONSTRAINT fk_translations_translations FOREIGN KEY (translation_id) REFERENCES words (id) ON DELETE CASCADE ON UPDATE CASCADE,
+	CONSTRAINT fk_translations_sense FOREIGN KEY (sense_id) REFERENCES senses (id) ON DELETE CASCADE ON UPDATE CASCADE,
+	CONSTRAINT fk_translations_translation_sense FOREIGN KEY (translation_sense_id) REFERENCES senses (id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+INSERT INTO translations_by_sense (word_id, translation_id, sense_id, translation_sense_id)
+	SELECT translations.word_id, translations.translation_id, sense.id, translation_sense.id
+	FROM translations
+	JOIN senses AS sense ON sense.word_id = translations.word_id
+	JOIN senses AS translation_sense ON translation_sense.word_id = translations.translation_id;
+DROP TABLE translations;
+ALTER TABLE translations_by_sense RENAME TO translations;
+CREATE INDEX IF NOT EXISTS idx_translations_word ON translations (word_id, translation_id);
+CREATE INDEX IF NOT EXISTS idx_translations_translation ON translations (translation_id);
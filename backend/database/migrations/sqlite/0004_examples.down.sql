@@ -0,0 +1,2 @@
+-- Words keep their example usages, examples added since are lost.
+DROP TABLE IF EXISTS examples;
@@ -0,0 +1,22 @@
+-- Pronunciations are IPA transcriptions of a word, each once per accent. An empty accent is not tied to any.
+CREATE TABLE IF NOT EXISTS pronunciations (
+	id integer PRIMARY KEY AUTOINCREMENT,
+	word_id integer NOT NULL,
+	ipa text NOT NULL,
+	accent text NOT NULL DEFAULT '',
+	CONSTRAINT fk_pronunciations_word FOREIGN KEY (word_id) REFERENCES words (id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_pronunciations_word_transcription ON pronunciations (word_id, ipa, accent);
+
+-- Recordings describe audio of a word, kept in the blob store under blob_key.
+CREATE TABLE IF NOT EXISTS recordings (
+	id integer PRIMARY KEY AUTOINCREMENT,
+	word_id integer NOT NULL,
+	accent text NOT NULL DEFAULT '',
+	content_type text NOT NULL,
+	size bigint NOT NULL,
+	blob_key text NOT NULL,
+	CONSTRAINT fk_recordings_word FOREIGN KEY (word_id) REFERENCES words (id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_recordings_word ON recordings (word_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_recordings_blob_key ON recordings (blob_key);
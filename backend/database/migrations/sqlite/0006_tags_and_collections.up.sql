@@ -0,0 +1,32 @@
+-- Tags label words, names are lowercase. A word has any number of tags.
+CREATE TABLE IF NOT EXISTS tags (
+	id integer PRIMARY KEY AUTOINCREMENT,
+	name text NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_name ON tags (name);
+
+CREATE TABLE IF NOT EXISTS word_tags (
+	word_id integer NOT NULL,
+	tag_id integer NOT NULL,
+	PRIMARY KEY (word_id, tag_id),
+	CONSTRAINT fk_word_tags_word FOREIGN KEY (word_id) REFERENCES words (id) ON DELETE CASCADE ON UPDATE CASCADE,
+	CONSTRAINT fk_word_tags_tag FOREIGN KEY (tag_id) REFERENCES tags (id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_word_tags_tag ON word_tags (tag_id);
+
+-- Collections are named lists of words, e.g. the words of a lesson.
+CREATE TABLE IF NOT EXISTS collections (
+	id integer PRIMARY KEY AUTOINCREMENT,
+	name text NOT NULL,
+	description text NOT NULL DEFAULT ''
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_collections_name ON collections (name);
+
+CREATE TABLE IF NOT EXISTS collection_words (
+	collection_id integer NOT NULL,
+	word_id integer NOT NULL,
+	PRIMARY KEY (collection_id, word_id),
+	CONSTRAINT fk_collection_words_collection FOREIGN KEY (collection_id) REFERENCES collections (id) ON DELETE CASCADE ON UPDATE CASCADE,
+	CONSTRAINT fk_collection_words_word FOREIGN KEY (word_id) REFERENCES words (id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_collection_words_word ON collection_words (word_id);
@@ -0,0 +1,341 @@
+package database
+
+import (
+	"backend/graph/model"
+	"backend/normalize"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type wordKey struct {
+	text     string
+	language string
+}
+
+// normalizeBatchSize is how many words normalizeWords reads at once.
+const normalizeBatchSize = 1000
+
+// storedWord is a row of the words table as normalizeWords reads it. The migration reads columns of its own
+// rather than model.Word, which follows the latest schema.
+type storedWord struct {
+	ID           int
+	Text         string
+	DisplayText  string
+	Language     string
+	ExampleUsage string
+}
+
+// normalizeWords brings words stored verbatim into the form used for word identity, see package normalize.
+// Languages of words and examples are canonicalized as well, e.g. "EN" becomes "en". Words which become equal
+// are merged into the oldest of them, which takes over their senses, examples, pronunciations, recordings, tags,
+// collections and translations. Words stored before display texts were kept get their current text as display text.
+//
+// Words are read in batches ordered by ID. Every word read is stored normalized before the next one, so the only
+// word a word can collide with is the one holding its normalized text and language: an older word normalized
+// before, which it is merged into, or a newer word stored normalized already, which is merged into it.
+func normalizeWords(tx *gorm.DB) error {
+	// Migrations run before SeedLanguages, the default languages are registered first so words resolve to them.
+	defaults := slices.Clone(defaultLanguages)
+	err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&defaults).Error
+	if err != nil {
+		return fmt.Errorf("failed to register default languages: %w", err)
+	}
+	languages, err := loadLanguages(tx)
+	if err != nil {
+		return err
+	}
+
+	merged, updated, afterID := 0, 0, 0
+	for {
+		var words []storedWord
+		err := tx.Table("words").Select("id, text, display_text, language, example_usage").
+			Where("id > ?", afterID).Order("id").Limit(normalizeBatchSize).Find(&words).Error
+		if err != nil {
+			return fmt.Errorf("failed to list words: %w", err)
+		}
+		for _, word := range words {
+			wordMerged, wordUpdated, err := normalizeWord(tx, languages, word)
+			if err != nil {
+				return err
+			}
+			merged += wordMerged
+			if wordUpdated {
+				updated++
+			}
+		}
+		if len(words) < normalizeBatchSize {
+			break
+		}
+		afterID = words[len(words)-1].ID
+	}
+
+	if err := normalizeExampleLanguages(tx, languages); err != nil {
+		return err
+	}
+
+	if merged > 0 || updated > 0 {
+		log.Printf("normalized %d words, merged %d duplicates", updated, merged)
+	}
+	return nil
+}
+
+// normalizeWord stores the word normalized, merging it with the word it collides with. It reports how many
+// words were merged and whether the word was kept and updated.
+func normalizeWord(tx *gorm.DB, languages *languageIndex, word storedWord) (int, bool, error) {
+	language, err := languages.resolve(tx, word.Language)
+	if err != nil {
+		return 0, false, err
+	}
+	code, caseSensitive := word.Language, false
+	if language != nil {
+		code, caseSensitive = language.Code, language.CaseSensitive
+	}
+	display := word.DisplayText
+	if display == "" {
+		display = normalize.Display(word.Text)
+	}
+	key := wordKey{text: normalize.Key(display, code, caseSensitive), language: code}
+	if word.Text == key.text && word.Language == key.language && word.DisplayText == display {
+		return 0, false, nil
+	}
+
+	var colliding []storedWord
+	err = tx.Table("words").Select("id, text, display_text, language, example_usage").
+		Where("text = ? AND language = ? AND id <> ?", key.text, key.language, word.ID).Find(&colliding).Error
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to search words colliding with word %d: %w", word.ID, err)
+	}
+	exampleUsage := word.ExampleUsage
+	if len(colliding) > 0 {
+		other := colliding[0]
+		if other.ID < word.ID {
+			if err := mergeWord(tx, other.ID, word.ID); err != nil {
+				return 0, false, err
+			}
+			if other.ExampleUsage == "" && word.ExampleUsage != "" {
+				err = tx.Table("words").Where("id = ?", other.ID).Update("example_usage", word.ExampleUsage).Error
+				if err != nil {
+					return 0, false, fmt.Errorf("failed to normalize word %d: %w", other.ID, err)
+				}
+			}
+			return 1, false, nil
+		}
+		if err := mergeWord(tx, word.ID, other.ID); err != nil {
+			return 0, false, err
+		}
+		if exampleUsage == "" {
+			exampleUsage = other.ExampleUsage
+		}
+	}
+
+	err = tx.Table("words").Where("id = ?", word.ID).Updates(map[string]interface{}{
+		"text":          key.text,
+		"display_text":  display,
+		"language":      key.language,
+		"example_usage": exampleUsage,
+	}).Error
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to normalize word %d: %w", word.ID, err)
+	}
+	return len(colliding), true, nil
+}
+
+// mergeWord moves senses, examples, pronunciations, recordings, tags, collections and translations of the duplicate
+// word to keeper and deletes the duplicate. Senses of the same part of speech and gloss as one of keeper are merged
+// into it, pronunciations, tags and collections keeper has already are dropped.
+func mergeWord(tx *gorm.DB, keeperID int, duplicateID int) error {
+	var senses []model.Sense
+	err := tx.Where("word_id IN ?", []int{keeperID, duplicateID}).Order("id").Find(&senses).Error
+	if err != nil {
+		return fmt.Errorf("failed to list senses of word %d: %w", duplicateID, err)
+	}
+	type meaning struct {
+		partOfSpeech model.PartOfSpeech
+		gloss        string
+	}
+	keeperSenses := make(map[meaning]int)
+	for _, sense := range senses {
+		if sense.WordID == keeperID {
+			keeperSenses[meaning{sense.PartOfSpeech, sense.Gloss}] = sense.ID
+		}
+	}
+	// senseIDs maps senses of the duplicate to the senses of keeper they become.
+	senseIDs := make(map[int]int)
+	for _, sense := range senses {
+		if sense.WordID != duplicateID {
+			continue
+		}
+		if id, ok := keeperSenses[meaning{sense.PartOfSpeech, sense.Gloss}]; ok {
+			senseIDs[sense.ID] = id
+			continue
+		}
+		err = tx.Model(&model.Sense{}).Where("id = ?", sense.ID).Update("word_id", keeperID).Error
+		if err != nil {
+			return fmt.Errorf("failed to move sense %d of word %d: %w", sense.ID, duplicateID, err)
+		}
+		senseIDs[sense.ID] = sense.ID
+	}
+
+	err = tx.Model(&model.Example{}).Where("word_id = ?", duplicateID).Update("word_id", keeperID).Error
+	if err != nil {
+		return fmt.Errorf("failed to move examples of word %d: %w", duplicateID, err)
+	}
+	for duplicateSenseID, keeperSenseID := range senseIDs {
+		if duplicateSenseID == keeperSenseID {
+			continue
+		}
+		err = tx.Model(&model.Example{}).Where("sense_id = ?", duplicateSenseID).Update("sense_id", keeperSenseID).Error
+		if err != nil {
+			return fmt.Errorf("failed to move examples of sense %d: %w", duplicateSenseID, err)
+		}
+	}
+
+	err = tx.Where("word_id = ? AND EXISTS (?)", duplicateID,
+		tx.Table("pronunciations AS kept").Select("1").
+			Where("kept.word_id = ? AND kept.ipa = pronunciations.ipa AND kept.accent = pronunciations.accent", keeperID),
+	).Delete(&model.Pronunciation{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to delete repeated pronunciations of word %d: %w", duplicateID, err)
+	}
+	err = tx.Model(&model.Pronunciation{}).Where("word_id = ?", duplicateID).Update("word_id", keeperID).Error
+	if err != nil {
+		return fmt.Errorf("failed to move pronunciations of word %d: %w", duplicateID, err)
+	}
+	err = tx.Model(&model.Recording{}).Where("word_id = ?", duplicateID).Update("word_id", keeperID).Error
+	if err != nil {
+		return fmt.Errorf("failed to move recordings of word %d: %w", duplicateID, err)
+	}
+
+	err = tx.Where("word_id = ? AND EXISTS (?)", duplicateID,
+		tx.Table("word_tags AS kept").Select("1").Where("kept.word_id = ? AND kept.tag_id = word_tags.tag_id", keeperID),
+	).Delete(&model.WordTag{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to delete repeated tags of word %d: %w", duplicateID, err)
+	}
+	err = tx.Model(&model.WordTag{}).Where("word_id = ?", duplicateID).Update("word_id", keeperID).Error
+	if err != nil {
+		return fmt.Errorf("failed to move tags of word %d: %w", duplicateID, err)
+	}
+	err = tx.Where("word_id = ? AND EXISTS (?)", duplicateID,
+		tx.Table("collection_words AS kept").Select("1").
+			Where("kept.word_id = ? AND kept.collection_id = collection_words.collection_id", keeperID),
+	).Delete(&model.CollectionWord{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to delete repeated collections of word %d: %w", duplicateID, err)
+	}
+	err = tx.Model(&model.CollectionWord{}).Where("word_id = ?", duplicateID).Update("word_id", keeperID).Error
+	if err != nil {
+		return fmt.Errorf("failed to move word %d in collections: %w", duplicateID, err)
+	}
+
+	var translations []model.Translation
+	err = tx.Where("word_id = ? OR translation_id = ?", duplicateID, duplicateID).Find(&translations).Error
+	if err != nil {
+		return fmt.Errorf("failed to list translations of word %d: %w", duplicateID, err)
+	}
+	// Translations are deleted before they are added again, as those of moved senses keep their primary key.
+	err = tx.Where("word_id = ? OR translation_id = ?", duplicateID, duplicateID).Delete(&model.Translation{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to delete translations of word %d: %w", duplicateID, err)
+	}
+
+	for _, translation := range translations {
+		if translation.TranslationID == duplicateID {
+			translation.WordID, translation.TranslationID = translation.TranslationID, translation.WordID
+			translation.SenseID, translation.TranslationSenseID = translation.TranslationSenseID, translation.SenseID
+		}
+		if translation.TranslationID == keeperID {
+			continue
+		}
+		moved := model.Translation{
+			WordID:             keeperID,
+			TranslationID:      translation.TranslationID,
+			SenseID:            senseIDs[translation.SenseID],
+			TranslationSenseID: translation.TranslationSenseID,
+		}
+		moved.SortTranslation()
+		err = tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&moved).Error
+		if err != nil {
+			return fmt.Errorf("failed to move translation of word %d: %w", duplicateID, err)
+		}
+	}
+
+	err = tx.Where("word_id = ?", duplicateID).Delete(&model.Sense{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to delete senses of word %d: %w", duplicateID, err)
+	}
+	err = tx.Delete(&model.Word{}, duplicateID).Error
+	if err != nil {
+		return fmt.Errorf("failed to delete word %d: %w", duplicateID, err)
+	}
+	return nil
+}
+
+// normalizeExampleLanguages canonicalizes the languages of examples, which are resolved like those of words.
+func normalizeExampleLanguages(tx *gorm.DB, languages *languageIndex) error {
+	var codes []string
+	err := tx.Model(&model.Example{}).Distinct("language").Pluck("language", &codes).Error
+	if err != nil {
+		return fmt.Errorf("failed to list languages of examples: %w", err)
+	}
+	for _, code := range codes {
+		language, err := languages.resolve(tx, code)
+		if err != nil {
+			return err
+		}
+		if language == nil || language.Code == code {
+			continue
+		}
+		err = tx.Model(&model.Example{}).Where("language = ?", code).Update("language", language.Code).Error
+		if err != nil {
+			return fmt.Errorf("failed to normalize languages of examples in %q: %w", code, err)
+		}
+	}
+	return nil
+}
+
+// languageIndex resolves language codes and names stored in words to registered languages.
+type languageIndex struct {
+	byCode map[string]*model.Language
+	byName map[string]*model.Language
+}
+
+func loadLanguages(tx *gorm.DB) (*languageIndex, error) {
+	var languages []*model.Language
+	err := tx.Find(&languages).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list languages: %w", err)
+	}
+	index := &languageIndex{byCode: make(map[string]*model.Language), byName: make(map[string]*model.Language)}
+	for _, language := range languages {
+		index.byCode[language.Code] = language
+		index.byName[strings.ToLower(language.Name)] = language
+	}
+	return index, nil
+}
+
+// resolve returns the language identified by code, registering it if code is a valid but unknown BCP 47 tag.
+// Returns nil for codes which are neither a tag nor a language name.
+func (index *languageIndex) resolve(tx *gorm.DB, code string) (*model.Language, error) {
+	canonical, ok := model.CanonicalLanguageCode(code)
+	if !ok {
+		return index.byName[strings.ToLower(code)], nil
+	}
+	if language, ok := index.byCode[canonical]; ok {
+		return language, nil
+	}
+
+	language := &model.Language{Code: canonical, Name: canonical, Script: model.DefaultScript(canonical)}
+	language.Direction = model.ScriptDirection(language.Script)
+	err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(language).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to register language %q: %w", canonical, err)
+	}
+	index.byCode[canonical] = language
+	return language, nil
+}
@@ -0,0 +1,83 @@
+// Package events publishes changes of the dictionary to subscribers, within the process and, with Postgres,
+// to the other replicas of the server.
+package events
+
+import (
+	"backend/graph/model"
+	"context"
+	"log"
+	"sync"
+)
+
+// subscriberBuffer is how many events a subscriber may fall behind before it is dropped.
+const subscriberBuffer = 256
+
+// Event is a change of a word or of a translation.
+type Event struct {
+	Kind model.ChangeKind `json:"kind"`
+	// Word is set for changes of words, Translation for changes of translations.
+	Word        *model.Word        `json:"word,omitempty"`
+	Translation *model.Translation `json:"translation,omitempty"`
+	// PreviousLanguage is the language an updated word was moved from, empty when its language did not change.
+	PreviousLanguage string `json:"previousLanguage,omitempty"`
+}
+
+// Publisher delivers events to subscribers.
+type Publisher interface {
+	// Publish delivers the events in order. Failures are logged, a change is not undone because its event is lost.
+	Publish(ctx context.Context, events []Event)
+}
+
+// Bus delivers events to the subscribers of this process.
+type Bus struct {
+	mu          sync.Mutex
+	subscribers map[chan Event]struct{}
+}
+
+func NewBus() *Bus {
+	return &Bus{subscribers: make(map[chan Event]struct{})}
+}
+
+// Publish delivers events to every subscriber. A subscriber which is not keeping up is dropped, closing its channel,
+// so it knows it missed events instead of silently skipping them.
+func (b *Bus) Publish(ctx context.Context, events []Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for subscriber := range b.subscribers {
+		for _, event := range events {
+			select {
+			case subscriber <- event:
+				continue
+			default:
+			}
+			log.Printf("dropping subscriber %d events behind", len(subscriber))
+			b.remove(subscriber)
+			break
+		}
+	}
+}
+
+// Subscribe returns a channel receiving the events published from now on. It is closed once ctx is done,
+// or when the subscriber is dropped for falling behind.
+func (b *Bus) Subscribe(ctx context.Context) <-chan Event {
+	subscriber := make(chan Event, subscriberBuffer)
+	b.mu.Lock()
+	b.subscribers[subscriber] = struct{}{}
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.remove(subscriber)
+	}()
+	return subscriber
+}
+
+// remove closes the channel of a subscriber unless it was removed already, b.mu must be held.
+func (b *Bus) remove(subscriber chan Event) {
+	if _, ok := b.subscribers[subscriber]; ok {
+		delete(b.subscribers, subscriber)
+		close(subscriber)
+	}
+}
@@ -0,0 +1,179 @@
+package events
+
+import (
+	"backend/graph/model"
+	"backend/store"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5/stdlib"
+	"gorm.io/gorm"
+)
+
+const (
+	// notifyChannel is the Postgres channel replicas exchange events on.
+	notifyChannel = "dictionary_changes"
+	// maxNotifyPayload stays below the 8000 bytes Postgres accepts as the payload of a notification.
+	maxNotifyPayload = 7900
+	// Delays between attempts to listen again after the connection is lost.
+	minListenRetry = time.Second
+	maxListenRetry = 30 * time.Second
+)
+
+// notification is the payload of a notification, events of a single publish split to fit the payload limit.
+type notification struct {
+	Events []Event `json:"events"`
+	// Truncated is set when a word was left out to fit but for its ID, receivers read the word again.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// Postgres publishes events with NOTIFY, so they reach subscribers of every replica listening on the database,
+// this one included. Listen delivers what is received to the bus of this process.
+type Postgres struct {
+	db  *gorm.DB
+	bus *Bus
+}
+
+// NewPostgres returns a publisher notifying through db, which must be a Postgres database.
+func NewPostgres(db *gorm.DB, bus *Bus) *Postgres {
+	return &Postgres{db: db, bus: bus}
+}
+
+func (p *Postgres) Publish(ctx context.Context, events []Event) {
+	payloads, err := notificationPayloads(events)
+	if err != nil {
+		log.Printf("failed to encode events: %v", err)
+		return
+	}
+	for i, payload := range payloads {
+		err := p.db.WithContext(ctx).Exec("SELECT pg_notify(?, ?)", notifyChannel, payload).Error
+		if err != nil {
+			// Subscribers of this replica still learn about the change, those of other replicas miss it.
+			log.Printf("failed to notify other replicas of changes: %v", err)
+			for _, payload := range payloads[i:] {
+				p.deliver(ctx, payload)
+			}
+			return
+		}
+	}
+}
+
+// notificationPayloads splits events into payloads which fit a notification.
+func notificationPayloads(events []Event) ([]string, error) {
+	var payloads []string
+	current := notification{}
+	for _, event := range events {
+		candidate := notification{Events: append(current.Events, event), Truncated: current.Truncated}
+		encoded, err := json.Marshal(candidate)
+		if err != nil {
+			return nil, err
+		}
+		if len(encoded) <= maxNotifyPayload {
+			current = candidate
+			continue
+		}
+		if len(current.Events) > 0 {
+			encoded, err := json.Marshal(current)
+			if err != nil {
+				return nil, err
+			}
+			payloads = append(payloads, string(encoded))
+		}
+
+		current = notification{Events: []Event{event}}
+		if encoded, err = json.Marshal(current); err != nil {
+			return nil, err
+		}
+		if len(encoded) > maxNotifyPayload && event.Word != nil {
+			event.Word = &model.Word{ID: event.Word.ID}
+			current = notification{Events: []Event{event}, Truncated: true}
+		}
+	}
+	if len(current.Events) > 0 {
+		encoded, err := json.Marshal(current)
+		if err != nil {
+			return nil, err
+		}
+		payloads = append(payloads, string(encoded))
+	}
+	return payloads, nil
+}
+
+// Listen delivers notifications of all replicas to the bus until ctx is done, listening again
+// whenever the connection is lost. Events published while the connection is down are missed.
+func (p *Postgres) Listen(ctx context.Context) {
+	retry := minListenRetry
+	for {
+		started := time.Now()
+		err := p.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if time.Since(started) > maxListenRetry {
+			retry = minListenRetry
+		}
+		log.Printf("listening for changes of other replicas failed, retrying in %s: %v", retry, err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(retry):
+		}
+		retry = min(2*retry, maxListenRetry)
+	}
+}
+
+func (p *Postgres) listen(ctx context.Context) error {
+	sqlDB, err := p.db.DB()
+	if err != nil {
+		return err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn any) error {
+		stdlibConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return fmt.Errorf("unexpected database driver %T, LISTEN needs pgx", driverConn)
+		}
+		pgxConn := stdlibConn.Conn()
+		if _, err := pgxConn.Exec(ctx, "LISTEN "+notifyChannel); err != nil {
+			return err
+		}
+		for {
+			received, err := pgxConn.WaitForNotification(ctx)
+			if err != nil {
+				return err
+			}
+			p.deliver(ctx, received.Payload)
+		}
+	})
+}
+
+// deliver publishes the events of a notification on the bus, reading truncated words again.
+func (p *Postgres) deliver(ctx context.Context, payload string) {
+	var received notification
+	if err := json.Unmarshal([]byte(payload), &received); err != nil {
+		log.Printf("ignoring malformed notification: %v", err)
+		return
+	}
+	if received.Truncated {
+		for i, event := range received.Events {
+			if event.Word == nil || event.Kind == model.ChangeKindDeleted {
+				continue
+			}
+			words, err := store.NewGorm(p.db, 0).FindWordsByIDs(ctx, []int{event.Word.ID})
+			if err != nil {
+				log.Printf("failed to read word %d of a notification: %v", event.Word.ID, err)
+			} else if len(words) > 0 {
+				received.Events[i].Word = words[0]
+			}
+		}
+	}
+	p.bus.Publish(ctx, received.Events)
+}
@@ -0,0 +1,172 @@
+package events
+
+import (
+	"backend/graph/model"
+	"backend/store"
+	"context"
+)
+
+// Store publishes the changes made through the store it wraps. Changes made in a transaction are published
+// once it commits, those of a rolled back transaction are never published.
+type Store struct {
+	store.DictionaryStore
+	publisher Publisher
+	// pending collects the events of the transaction the store runs in, nil outside of one.
+	pending *[]Event
+}
+
+// NewStore returns s publishing its changes to publisher.
+func NewStore(s store.DictionaryStore, publisher Publisher) *Store {
+	return &Store{DictionaryStore: s, publisher: publisher}
+}
+
+func (s *Store) Transaction(ctx context.Context, fn func(tx store.DictionaryStore) error) error {
+	if s.pending != nil {
+		return s.DictionaryStore.Transaction(ctx, func(tx store.DictionaryStore) error {
+			return fn(&Store{DictionaryStore: tx, publisher: s.publisher, pending: s.pending})
+		})
+	}
+
+	var pending []Event
+	err := s.DictionaryStore.Transaction(ctx, func(tx store.DictionaryStore) error {
+		return fn(&Store{DictionaryStore: tx, publisher: s.publisher, pending: &pending})
+	})
+	if err == nil && len(pending) > 0 {
+		// The change is made, so it is published even when the request is cancelled meanwhile.
+		s.publisher.Publish(context.WithoutCancel(ctx), pending)
+	}
+	return err
+}
+
+func (s *Store) publish(ctx context.Context, events ...Event) {
+	if s.pending != nil {
+		*s.pending = append(*s.pending, events...)
+		return
+	}
+	s.publisher.Publish(context.WithoutCancel(ctx), events)
+}
+
+func (s *Store) FindOrCreateWord(ctx context.Context, word *model.Word) (bool, error) {
+	created, err := s.DictionaryStore.FindOrCreateWord(ctx, word)
+	if created {
+		s.publish(ctx, wordEvent(model.ChangeKindCreated, word))
+	}
+	return created, err
+}
+
+func (s *Store) FindOrCreateWords(ctx context.Context, words []*model.Word) ([]bool, error) {
+	created, err := s.DictionaryStore.FindOrCreateWords(ctx, words)
+	if err != nil {
+		return nil, err
+	}
+	var events []Event
+	for i, ok := range created {
+		if ok {
+			events = append(events, wordEvent(model.ChangeKindCreated, words[i]))
+		}
+	}
+	if len(events) > 0 {
+		s.publish(ctx, events...)
+	}
+	return created, nil
+}
+
+func (s *Store) UpdateWord(ctx context.Context, word *model.Word) error {
+	previous, err := s.DictionaryStore.FindWordsByIDs(ctx, []int{word.ID})
+	if err != nil {
+		return err
+	}
+	if err := s.DictionaryStore.UpdateWord(ctx, word); err != nil {
+		return err
+	}
+	event := wordEvent(model.ChangeKindUpdated, word)
+	if len(previous) > 0 && previous[0].Language != word.Language {
+		event.PreviousLanguage = previous[0].Language
+	}
+	s.publish(ctx, event)
+	return nil
+}
+
+func (s *Store) DeleteWord(ctx context.Context, word *model.Word) error {
+	senses, err := s.DictionaryStore.SensesOf(ctx, []int{word.ID})
+	if err != nil {
+		return err
+	}
+	senseIDs := make([]int, len(senses[word.ID]))
+	for i, sense := range senses[word.ID] {
+		senseIDs[i] = sense.ID
+	}
+	translations, err := s.DictionaryStore.TranslationsOfSenses(ctx, senseIDs)
+	if err != nil {
+		return err
+	}
+	if err := s.DictionaryStore.DeleteWord(ctx, word); err != nil {
+		return err
+	}
+	events := make([]Event, 0, len(translations)+1)
+	for _, translation := range translations {
+		events = append(events, translationEvent(model.ChangeKindDeleted, translation))
+	}
+	s.publish(ctx, append(events, wordEvent(model.ChangeKindDeleted, word))...)
+	return nil
+}
+
+func (s *Store) DeleteSense(ctx context.Context, sense *model.Sense) error {
+	translations, err := s.DictionaryStore.TranslationsOfSenses(ctx, []int{sense.ID})
+	if err != nil {
+		return err
+	}
+	if err := s.DictionaryStore.DeleteSense(ctx, sense); err != nil {
+		return err
+	}
+	if len(translations) > 0 {
+		events := make([]Event, len(translations))
+		for i, translation := range translations {
+			events[i] = translationEvent(model.ChangeKindDeleted, translation)
+		}
+		s.publish(ctx, events...)
+	}
+	return nil
+}
+
+// AddTranslation stores the translation with AddTranslations, which tells whether it was created.
+func (s *Store) AddTranslation(ctx context.Context, translation model.Translation) error {
+	_, err := s.AddTranslations(ctx, []model.Translation{translation})
+	return err
+}
+
+func (s *Store) AddTranslations(ctx context.Context, translations []model.Translation) ([]bool, error) {
+	created, err := s.DictionaryStore.AddTranslations(ctx, translations)
+	if err != nil {
+		return nil, err
+	}
+	var events []Event
+	for i, ok := range created {
+		if ok {
+			events = append(events, translationEvent(model.ChangeKindCreated, translations[i]))
+		}
+	}
+	if len(events) > 0 {
+		s.publish(ctx, events...)
+	}
+	return created, nil
+}
+
+func (s *Store) DeleteTranslation(ctx context.Context, translation model.Translation) (bool, error) {
+	deleted, err := s.DictionaryStore.DeleteTranslation(ctx, translation)
+	if deleted {
+		s.publish(ctx, translationEvent(model.ChangeKindDeleted, translation))
+	}
+	return deleted, err
+}
+
+// wordEvent copies the word, which its owner may go on to modify.
+func wordEvent(kind model.ChangeKind, word *model.Word) Event {
+	c := *word
+	c.Translations = nil
+	return Event{Kind: kind, Word: &c}
+}
+
+func translationEvent(kind model.ChangeKind, translation model.Translation) Event {
+	return Event{Kind: kind, Translation: &translation}
+}
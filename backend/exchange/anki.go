@@ -0,0 +1,83 @@
+package exchange
+
+import (
+	"backend/graph/model"
+	"backend/store"
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+// ExportAnki writes a deck of flashcards in the text format imported by Anki 2.1.55 and newer.
+// Every word of sourceLanguage with translations into targetLanguage is a note of the Basic note type,
+// with the word on the front and its translations, as getTranslations returns them, on the back.
+// The examples of the word in its language are shown below the translations as context.
+// Notes are identified by the ID of the word and the target language, so importing a newer export
+// into Anki updates the notes imported before instead of adding duplicates.
+func ExportAnki(ctx context.Context, s store.DictionaryStore, w io.Writer, sourceLanguage string, targetLanguage string) error {
+	source, err := store.ResolveLanguage(ctx, s, sourceLanguage)
+	if err != nil {
+		return err
+	}
+	target, err := store.ResolveLanguage(ctx, s, targetLanguage)
+	if err != nil {
+		return err
+	}
+
+	out := newExportWriter(w)
+	out.WriteString("#separator:tab\n#html:true\n#notetype:Basic\n")
+	fmt.Fprintf(out, "#deck:Dictionary::%s to %s\n", source.Name, target.Name)
+	fmt.Fprintf(out, "#tags:dictionary %s-%s\n", source.Code, target.Code)
+	out.WriteString("#guid column:1\n#columns:GUID\tFront\tBack\n")
+	// The words of sourceLanguage are read a page at a time, see exportWordPages.
+	err = exportWordPages(ctx, s, out, store.WordFilter{Language: source.Code}, func(tx store.DictionaryStore, words []*model.Word, ids []int) error {
+		translations, err := tx.TranslationsOfWords(ctx, ids)
+		if err != nil {
+			return err
+		}
+		examples, err := exampleTexts(ctx, tx, words)
+		if err != nil {
+			return err
+		}
+		for _, word := range words {
+			var texts []string
+			for _, translation := range translations[word.ID] {
+				if translation.Language == target.Code {
+					texts = append(texts, ankiField(displayText(translation)))
+				}
+			}
+			if len(texts) == 0 {
+				continue
+			}
+
+			back := strings.Join(texts, ", ")
+			for i, example := range examples[word.ID] {
+				if i == 0 {
+					back += "<br>"
+				}
+				back += "<br><i>" + ankiField(example) + "</i>"
+			}
+			fmt.Fprintf(out, "%s\t%s\t%s\n", ankiGUID(word, target.Code), ankiField(displayText(word)), back)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return out.flush()
+}
+
+// ankiGUID identifies the note of a word in decks translating it into targetLanguage.
+func ankiGUID(word *model.Word, targetLanguage string) string {
+	return fmt.Sprintf("dictionary-%d-%s", word.ID, targetLanguage)
+}
+
+// ankiField escapes text for an HTML field. Line breaks become <br> and tabs spaces, so every note is a single line.
+func ankiField(text string) string {
+	text = html.EscapeString(text)
+	text = strings.ReplaceAll(text, "\t", " ")
+	text = strings.ReplaceAll(text, "\r\n", "<br>")
+	return strings.ReplaceAll(text, "\n", "<br>")
+}
@@ -0,0 +1,66 @@
+package exchange
+
+import (
+	"backend/graph/model"
+	"backend/store"
+	"context"
+	"encoding/csv"
+	"io"
+)
+
+// ExportCollection writes the translations of the words of the collection with given name as CSV rows in the
+// format importTranslations reads, so a collection can be shared with another dictionary. Translations are looked up
+// like getTranslations does, into targetLanguage unless it is empty. Words without translations are left out.
+func ExportCollection(ctx context.Context, s store.DictionaryStore, w io.Writer, name string, targetLanguage string) error {
+	collection, err := s.FindCollection(ctx, name)
+	if err != nil {
+		return err
+	}
+	target := ""
+	if targetLanguage != "" {
+		language, err := store.ResolveLanguage(ctx, s, targetLanguage)
+		if err != nil {
+			return err
+		}
+		target = language.Code
+	}
+
+	out := newExportWriter(w)
+	rows := csv.NewWriter(out)
+	if err := rows.Write(csvHeader); err != nil {
+		return err
+	}
+	// Like the other exports, the words of the collection are read a page at a time, see exportWordPages.
+	err = exportWordPages(ctx, s, out, store.WordFilter{CollectionID: collection.ID}, func(tx store.DictionaryStore, words []*model.Word, ids []int) error {
+		translations, err := tx.TranslationsOfWords(ctx, ids)
+		if err != nil {
+			return err
+		}
+		examples, err := exampleTexts(ctx, tx, words)
+		if err != nil {
+			return err
+		}
+		for _, word := range words {
+			// The example usage of a word is its first example in its language.
+			usage := ""
+			if len(examples[word.ID]) > 0 {
+				usage = examples[word.ID][0]
+			}
+			for _, translation := range translations[word.ID] {
+				if target != "" && translation.Language != target {
+					continue
+				}
+				err := rows.Write([]string{displayText(word), word.Language, displayText(translation), translation.Language, usage})
+				if err != nil {
+					return err
+				}
+			}
+		}
+		rows.Flush()
+		return rows.Error()
+	})
+	if err != nil {
+		return err
+	}
+	return out.flush()
+}
@@ -0,0 +1,105 @@
+package exchange
+
+import (
+	"backend/graph/model"
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// maxTSVLineLength bounds a row of a TSV file, longer rows fail the import.
+const maxTSVLineLength = 1 << 20
+
+// csvHeader names the columns of an imported row, a first row starting with it is skipped.
+var csvHeader = []string{"source_text", "source_language", "target_text", "target_language", "example_usage"}
+
+// importDelimited reads CSV or TSV rows of source text, source language, target text, target language
+// and an optional example usage, which becomes an example of the source word.
+func importDelimited(ctx context.Context, im *importer, r io.Reader, format model.TranslationFileFormat) error {
+	var records recordReader
+	if format == model.TranslationFileFormatTsv {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(nil, maxTSVLineLength)
+		records = &tsvRecords{scanner: scanner}
+	} else {
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		reader.ReuseRecord = true
+		records = csvRecords{reader}
+	}
+
+	for first := true; ; first = false {
+		record, line, err := records.read()
+		if err == io.EOF {
+			return nil
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			im.issue(parseErr.StartLine, model.ImportRowStatusInvalid, fmt.Sprintf("malformed row: %v", parseErr.Err))
+			continue
+		} else if err != nil {
+			return fmt.Errorf("failed to read import file: %w", err)
+		}
+
+		if first && isHeader(record) {
+			continue
+		}
+		if len(record) != len(csvHeader) && len(record) != len(csvHeader)-1 {
+			im.issue(line, model.ImportRowStatusInvalid, fmt.Sprintf("expected %d or %d columns, got %d", len(csvHeader)-1, len(csvHeader), len(record)))
+			continue
+		}
+		source := importedWord{text: record[0], language: record[1]}
+		if len(record) == len(csvHeader) {
+			source.examples = []string{record[4]}
+		}
+		if err := im.add(ctx, line, source, importedWord{text: record[2], language: record[3]}); err != nil {
+			return err
+		}
+	}
+}
+
+// recordReader reads rows of an import file along with the line they start on.
+type recordReader interface {
+	read() (record []string, line int, err error)
+}
+
+type csvRecords struct {
+	reader *csv.Reader
+}
+
+func (r csvRecords) read() ([]string, int, error) {
+	record, err := r.reader.Read()
+	if err != nil {
+		return nil, 0, err
+	}
+	line, _ := r.reader.FieldPos(0)
+	return record, line, nil
+}
+
+// tsvRecords reads tab separated values, which unlike CSV have no quoting: every line is a row.
+type tsvRecords struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func (r *tsvRecords) read() ([]string, int, error) {
+	for r.scanner.Scan() {
+		r.line++
+		text := strings.TrimSuffix(r.scanner.Text(), "\r")
+		if text != "" {
+			return strings.Split(text, "\t"), r.line, nil
+		}
+	}
+	if err := r.scanner.Err(); err != nil {
+		return nil, 0, err
+	}
+	return nil, 0, io.EOF
+}
+
+func isHeader(record []string) bool {
+	return len(record) > 0 && strings.EqualFold(strings.TrimSpace(strings.TrimPrefix(record[0], "\ufeff")), csvHeader[0])
+}
@@ -0,0 +1,742 @@
+package exchange
+
+import (
+	"backend/graph/model"
+	"backend/store"
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// DumpVersion is the version of the dump format written by Dump. Restore reads dumps up to this version.
+// Version 2 added senses, translations of version 1 dumps link the default senses of their words.
+// Version 3 added examples, version 4 pronunciations, version 5 tags and collections.
+const DumpVersion = 5
+
+// maxDumpLineLength bounds a line of a dump, a word with a longer example usage fails the restore.
+const maxDumpLineLength = 16 << 20
+
+// Record types of a dump, every line is a JSON object with one of them as its type.
+const (
+	dumpTypeHeader        = "dump"
+	dumpTypeLanguage      = "language"
+	dumpTypeWord          = "word"
+	dumpTypeSense         = "sense"
+	dumpTypeExample       = "example"
+	dumpTypePronunciation = "pronunciation"
+	dumpTypeTranslation   = "translation"
+	dumpTypeCollection    = "collection"
+)
+
+type dumpRecord struct {
+	Type string `json:"type"`
+}
+
+type dumpHeader struct {
+	Type    string `json:"type"`
+	Version int    `json:"version"`
+}
+
+type dumpLanguage struct {
+	Type          string              `json:"type"`
+	Code          string              `json:"code"`
+	Name          string              `json:"name"`
+	NativeName    string              `json:"nativeName,omitempty"`
+	Script        string              `json:"script,omitempty"`
+	Direction     model.TextDirection `json:"direction"`
+	CaseSensitive bool                `json:"caseSensitive,omitempty"`
+}
+
+// dumpWord is a word with the names of its tags. ExampleUsage is only read, from dumps written before example usages
+// were kept as examples, and becomes an example of the word.
+type dumpWord struct {
+	Type         string   `json:"type"`
+	ID           int      `json:"id"`
+	Text         string   `json:"text"`
+	DisplayText  string   `json:"displayText,omitempty"`
+	Language     string   `json:"language"`
+	ExampleUsage string   `json:"exampleUsage,omitempty"`
+	Tags         []string `json:"tags,omitempty"`
+}
+
+// dumpSense is a sense other than the default sense of a word, which every word has. A default sense is only
+// dumped for its register or for examples given in it.
+type dumpSense struct {
+	Type         string             `json:"type"`
+	ID           int                `json:"id"`
+	WordID       int                `json:"wordId"`
+	PartOfSpeech model.PartOfSpeech `json:"partOfSpeech,omitempty"`
+	Gloss        string             `json:"gloss,omitempty"`
+	Register     string             `json:"register,omitempty"`
+}
+
+// dumpExample is an example of a word, in the sense with SenseID unless it is zero. Examples which are translations
+// of each other are linked by the one dumped last.
+type dumpExample struct {
+	Type          string `json:"type"`
+	ID            int    `json:"id"`
+	WordID        int    `json:"wordId"`
+	SenseID       int    `json:"senseId,omitempty"`
+	Text          string `json:"text"`
+	Language      string `json:"language"`
+	Source        string `json:"source,omitempty"`
+	TranslationID int    `json:"translationId,omitempty"`
+}
+
+// dumpPronunciation is an IPA transcription of a word. Recordings are not dumped, their audio is kept apart
+// from the database.
+type dumpPronunciation struct {
+	Type   string `json:"type"`
+	WordID int    `json:"wordId"`
+	IPA    string `json:"ipa"`
+	Accent string `json:"accent,omitempty"`
+}
+
+// dumpTranslation links the default senses of its words unless sense IDs are given.
+type dumpTranslation struct {
+	Type               string `json:"type"`
+	WordID             int    `json:"wordId"`
+	TranslationID      int    `json:"translationId"`
+	SenseID            int    `json:"senseId,omitempty"`
+	TranslationSenseID int    `json:"translationSenseId,omitempty"`
+}
+
+// dumpCollection is a collection along with the IDs of its words, which are dumped before it.
+type dumpCollection struct {
+	Type        string `json:"type"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	WordIDs     []int  `json:"wordIds"`
+}
+
+// RestoreStats counts what a restore found in a dump and how much of it was new to the store.
+type RestoreStats struct {
+	Languages, LanguagesCreated           int
+	Words, WordsCreated                   int
+	Senses, SensesCreated                 int
+	Examples, ExamplesCreated             int
+	Pronunciations, PronunciationsCreated int
+	Translations, TranslationsCreated     int
+	Collections, CollectionsCreated       int
+}
+
+// Dump writes the dictionary as JSON Lines: a header with the format version, then languages, words with their
+// tags, senses, examples, pronunciations and the translations between them, and the collections of the words.
+// The dump is read from a single transaction, words a page at a time, so a dump of any size is consistent and
+// written as it is read. The transaction stays open until the dump is written, so w should not be slow to accept it,
+// and s should not limit its duration. Translations follow the page holding the later of their words.
+// Recordings are left out, their audio is kept apart from the store.
+func Dump(ctx context.Context, s store.DictionaryStore, w io.Writer) error {
+	out := bufio.NewWriter(w)
+	encoder := json.NewEncoder(out)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(dumpHeader{Type: dumpTypeHeader, Version: DumpVersion}); err != nil {
+		return err
+	}
+	err := s.Transaction(ctx, func(tx store.DictionaryStore) error {
+		if err := dumpLanguages(ctx, tx, encoder); err != nil {
+			return err
+		}
+		dumpedExamples := make(map[int]bool)
+		err := eachWordPage(ctx, tx, store.WordFilter{}, func(words []*model.Word, ids []int) error {
+			return dumpWords(ctx, tx, encoder, words, ids, dumpedExamples)
+		})
+		if err != nil {
+			return err
+		}
+		return dumpCollections(ctx, tx, encoder)
+	})
+	if err != nil {
+		return err
+	}
+	return out.Flush()
+}
+
+func dumpLanguages(ctx context.Context, s store.DictionaryStore, encoder *json.Encoder) error {
+	languages, err := s.ListLanguages(ctx)
+	if err != nil {
+		return err
+	}
+	for _, language := range languages {
+		err := encoder.Encode(dumpLanguage{
+			Type:          dumpTypeLanguage,
+			Code:          language.Code,
+			Name:          language.Name,
+			NativeName:    language.NativeName,
+			Script:        language.Script,
+			Direction:     language.Direction,
+			CaseSensitive: language.CaseSensitive,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dumpWords writes a page of words with their tags, senses, examples and pronunciations, followed by the
+// translations whose later word is on the page. dumpedExamples holds the IDs of the examples dumped before.
+func dumpWords(ctx context.Context, s store.DictionaryStore, encoder *json.Encoder, words []*model.Word, ids []int, dumpedExamples map[int]bool) error {
+	wordSenses, err := s.SensesOf(ctx, ids)
+	if err != nil {
+		return err
+	}
+	wordExamples, err := s.ExamplesOf(ctx, ids)
+	if err != nil {
+		return err
+	}
+	wordPronunciations, err := s.PronunciationsOf(ctx, ids)
+	if err != nil {
+		return err
+	}
+	wordTags, err := s.TagsOf(ctx, ids)
+	if err != nil {
+		return err
+	}
+	exampleSenses := make(map[int]bool)
+	for _, examples := range wordExamples {
+		for _, example := range examples {
+			if example.SenseID != nil {
+				exampleSenses[*example.SenseID] = true
+			}
+		}
+	}
+
+	senses := make(map[int]*model.Sense)
+	for _, word := range words {
+		record := dumpWord{
+			Type:        dumpTypeWord,
+			ID:          word.ID,
+			Text:        word.Text,
+			DisplayText: word.DisplayText,
+			Language:    word.Language,
+		}
+		for _, tag := range wordTags[word.ID] {
+			record.Tags = append(record.Tags, tag.Name)
+		}
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+		for _, sense := range wordSenses[word.ID] {
+			senses[sense.ID] = sense
+			if sense.IsDefault() && sense.Register == "" && !exampleSenses[sense.ID] {
+				continue
+			}
+			err := encoder.Encode(dumpSense{
+				Type:         dumpTypeSense,
+				ID:           sense.ID,
+				WordID:       sense.WordID,
+				PartOfSpeech: sense.PartOfSpeech,
+				Gloss:        sense.Gloss,
+				Register:     sense.Register,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		for _, example := range wordExamples[word.ID] {
+			record := dumpExample{
+				Type:     dumpTypeExample,
+				ID:       example.ID,
+				WordID:   example.WordID,
+				Text:     example.Text,
+				Language: example.Language,
+				Source:   example.Source,
+			}
+			if example.SenseID != nil {
+				record.SenseID = *example.SenseID
+			}
+			if example.TranslatedExampleID != nil && dumpedExamples[*example.TranslatedExampleID] {
+				record.TranslationID = *example.TranslatedExampleID
+			}
+			if err := encoder.Encode(record); err != nil {
+				return err
+			}
+			dumpedExamples[example.ID] = true
+		}
+		for _, pronunciation := range wordPronunciations[word.ID] {
+			err := encoder.Encode(dumpPronunciation{
+				Type:   dumpTypePronunciation,
+				WordID: pronunciation.WordID,
+				IPA:    pronunciation.IPA,
+				Accent: pronunciation.Accent,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return dumpTranslations(ctx, s, encoder, ids, senses)
+}
+
+// dumpTranslations writes the translations of the page of words with given IDs whose later word is on the page,
+// the earlier one was dumped on the page or before it. senses holds the senses of the page, those of the earlier
+// words before the page are loaded.
+func dumpTranslations(ctx context.Context, s store.DictionaryStore, encoder *json.Encoder, ids []int, senses map[int]*model.Sense) error {
+	senseIDs := make([]int, 0, len(senses))
+	for id := range senses {
+		senseIDs = append(senseIDs, id)
+	}
+	slices.Sort(senseIDs)
+	translations, err := s.TranslationsOfSenses(ctx, senseIDs)
+	if err != nil {
+		return err
+	}
+
+	onPage := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		onPage[id] = true
+	}
+	var missing []int
+	for _, translation := range translations {
+		if onPage[translation.TranslationID] && senses[translation.SenseID] == nil {
+			missing = append(missing, translation.SenseID)
+		}
+	}
+	slices.Sort(missing)
+	missing = slices.Compact(missing)
+	for start := 0; start < len(missing); start += exportBatchSize {
+		loaded, err := s.FindSensesByIDs(ctx, missing[start:min(start+exportBatchSize, len(missing))])
+		if err != nil {
+			return err
+		}
+		for _, sense := range loaded {
+			senses[sense.ID] = sense
+		}
+	}
+
+	for _, translation := range translations {
+		// Translations are stored from the word with the smaller ID, the later word is the translation.
+		if !onPage[translation.TranslationID] {
+			continue
+		}
+		sense, translationSense := senses[translation.SenseID], senses[translation.TranslationSenseID]
+		if sense == nil || translationSense == nil {
+			continue
+		}
+		record := dumpTranslation{Type: dumpTypeTranslation, WordID: translation.WordID, TranslationID: translation.TranslationID}
+		if !sense.IsDefault() {
+			record.SenseID = sense.ID
+		}
+		if !translationSense.IsDefault() {
+			record.TranslationSenseID = translationSense.ID
+		}
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dumpCollections writes the collections with the IDs of their words.
+func dumpCollections(ctx context.Context, s store.DictionaryStore, encoder *json.Encoder) error {
+	collections, err := s.ListCollections(ctx)
+	if err != nil {
+		return err
+	}
+	for _, collection := range collections {
+		record := dumpCollection{Type: dumpTypeCollection, Name: collection.Name, Description: collection.Description, WordIDs: []int{}}
+		err := eachWordPage(ctx, s, store.WordFilter{CollectionID: collection.ID}, func(words []*model.Word, ids []int) error {
+			record.WordIDs = append(record.WordIDs, ids...)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Restore reads a dump written by Dump into the store in a single transaction, so a failing restore stores nothing.
+// Words, senses and examples get new IDs, translations are remapped to them. Languages, words, senses, examples,
+// pronunciations and translations which exist are kept as they are, so a dump can be restored into a store which
+// is not empty, or restored twice. Examples exist when their word has one with the same text and language, existing
+// examples which are no translation are linked to the translation given by the dump. Default senses
+// only take the register of the dump when they have none. Collections are merged into the collection of the same name,
+// restored words are added to it.
+func Restore(ctx context.Context, s store.DictionaryStore, r io.Reader) (*RestoreStats, error) {
+	stats := &RestoreStats{}
+	err := s.Transaction(ctx, func(tx store.DictionaryStore) error {
+		rs := &restorer{s: tx, stats: stats, ids: make(map[int]int), senseIDs: make(map[int]int), exampleIDs: make(map[int]int)}
+		return rs.restore(ctx, r)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+type restorer struct {
+	s     store.DictionaryStore
+	stats *RestoreStats
+	// ids, senseIDs and exampleIDs map IDs of words, senses and examples in the dump to their IDs in the store.
+	ids          map[int]int
+	senseIDs     map[int]int
+	exampleIDs   map[int]int
+	header       bool
+	words        []dumpWord
+	translations []model.Translation
+}
+
+func (rs *restorer) restore(ctx context.Context, r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxDumpLineLength)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		if err := rs.record(ctx, line, scanner.Bytes()); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read dump: %w", err)
+	}
+	if !rs.header {
+		return errors.New("dump is empty")
+	}
+	if err := rs.flushWords(ctx); err != nil {
+		return err
+	}
+	return rs.flushTranslations(ctx)
+}
+
+func (rs *restorer) record(ctx context.Context, line int, data []byte) error {
+	var record dumpRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return fmt.Errorf("line %d: malformed record: %w", line, err)
+	}
+	if !rs.header {
+		if record.Type != dumpTypeHeader {
+			return fmt.Errorf("line %d: not a dictionary dump, expected a dump header", line)
+		}
+		var header dumpHeader
+		if err := json.Unmarshal(data, &header); err != nil {
+			return fmt.Errorf("line %d: malformed record: %w", line, err)
+		}
+		if header.Version < 1 || header.Version > DumpVersion {
+			return fmt.Errorf("unsupported dump version %d, this binary reads versions up to %d", header.Version, DumpVersion)
+		}
+		rs.header = true
+		return nil
+	}
+
+	switch record.Type {
+	case dumpTypeLanguage:
+		var language dumpLanguage
+		if err := json.Unmarshal(data, &language); err != nil {
+			return fmt.Errorf("line %d: malformed record: %w", line, err)
+		}
+		created, err := rs.s.AddLanguage(ctx, &model.Language{
+			Code:          language.Code,
+			Name:          language.Name,
+			NativeName:    language.NativeName,
+			Script:        language.Script,
+			Direction:     language.Direction,
+			CaseSensitive: language.CaseSensitive,
+		})
+		if err != nil {
+			return err
+		}
+		rs.stats.Languages++
+		if created {
+			rs.stats.LanguagesCreated++
+		}
+	case dumpTypeWord:
+		var word dumpWord
+		if err := json.Unmarshal(data, &word); err != nil {
+			return fmt.Errorf("line %d: malformed record: %w", line, err)
+		}
+		if word.Text == "" || word.Language == "" {
+			return fmt.Errorf("line %d: word without text or language", line)
+		}
+		if slices.Contains(word.Tags, "") {
+			return fmt.Errorf("line %d: word with an empty tag", line)
+		}
+		rs.words = append(rs.words, word)
+		if len(rs.words) == importBatchSize {
+			return rs.flushWords(ctx)
+		}
+	case dumpTypeSense:
+		var sense dumpSense
+		if err := json.Unmarshal(data, &sense); err != nil {
+			return fmt.Errorf("line %d: malformed record: %w", line, err)
+		}
+		if sense.PartOfSpeech != "" && !sense.PartOfSpeech.IsValid() {
+			return fmt.Errorf("line %d: unknown part of speech %q", line, sense.PartOfSpeech)
+		}
+		if err := rs.flushWords(ctx); err != nil {
+			return err
+		}
+		return rs.restoreSense(ctx, line, sense)
+	case dumpTypeExample:
+		var example dumpExample
+		if err := json.Unmarshal(data, &example); err != nil {
+			return fmt.Errorf("line %d: malformed record: %w", line, err)
+		}
+		if example.Text == "" || example.Language == "" {
+			return fmt.Errorf("line %d: example without text or language", line)
+		}
+		if err := rs.flushWords(ctx); err != nil {
+			return err
+		}
+		return rs.restoreExample(ctx, line, example)
+	case dumpTypePronunciation:
+		var pronunciation dumpPronunciation
+		if err := json.Unmarshal(data, &pronunciation); err != nil {
+			return fmt.Errorf("line %d: malformed record: %w", line, err)
+		}
+		if pronunciation.IPA == "" {
+			return fmt.Errorf("line %d: pronunciation without transcription", line)
+		}
+		if err := rs.flushWords(ctx); err != nil {
+			return err
+		}
+		return rs.restorePronunciation(ctx, line, pronunciation)
+	case dumpTypeCollection:
+		var collection dumpCollection
+		if err := json.Unmarshal(data, &collection); err != nil {
+			return fmt.Errorf("line %d: malformed record: %w", line, err)
+		}
+		if collection.Name == "" {
+			return fmt.Errorf("line %d: collection without name", line)
+		}
+		if err := rs.flushWords(ctx); err != nil {
+			return err
+		}
+		return rs.restoreCollection(ctx, line, collection)
+	case dumpTypeTranslation:
+		var translation dumpTranslation
+		if err := json.Unmarshal(data, &translation); err != nil {
+			return fmt.Errorf("line %d: malformed record: %w", line, err)
+		}
+		if err := rs.flushWords(ctx); err != nil {
+			return err
+		}
+		wordID, ok := rs.ids[translation.WordID]
+		translationID, translationOk := rs.ids[translation.TranslationID]
+		if !ok || !translationOk {
+			return fmt.Errorf("line %d: translation between words %d and %d, which are not in the dump before it", line, translation.WordID, translation.TranslationID)
+		}
+		senseID, err := rs.senseID(line, "translation", translation.SenseID)
+		if err != nil {
+			return err
+		}
+		translationSenseID, err := rs.senseID(line, "translation", translation.TranslationSenseID)
+		if err != nil {
+			return err
+		}
+		rs.translations = append(rs.translations, model.Translation{
+			WordID:             wordID,
+			TranslationID:      translationID,
+			SenseID:            senseID,
+			TranslationSenseID: translationSenseID,
+		})
+		if len(rs.translations) == importBatchSize {
+			return rs.flushTranslations(ctx)
+		}
+	default:
+		return fmt.Errorf("line %d: unknown record type %q", line, record.Type)
+	}
+	return nil
+}
+
+func (rs *restorer) restoreSense(ctx context.Context, line int, record dumpSense) error {
+	wordID, ok := rs.ids[record.WordID]
+	if !ok {
+		return fmt.Errorf("line %d: sense of word %d, which is not in the dump before it", line, record.WordID)
+	}
+	sense := &model.Sense{WordID: wordID, PartOfSpeech: record.PartOfSpeech, Gloss: record.Gloss, Register: record.Register}
+	created, err := rs.s.FindOrCreateSense(ctx, sense)
+	if err != nil {
+		return err
+	}
+	if !created && sense.IsDefault() && sense.Register == "" && record.Register != "" {
+		sense.Register = record.Register
+		if err := rs.s.UpdateSense(ctx, sense); err != nil {
+			return err
+		}
+	}
+	rs.senseIDs[record.ID] = sense.ID
+	rs.stats.Senses++
+	if created {
+		rs.stats.SensesCreated++
+	}
+	return nil
+}
+
+// senseID maps the ID of a sense in the dump, which a record of given type refers to, to its ID in the store.
+// Zero stays zero.
+func (rs *restorer) senseID(line int, recordType string, id int) (int, error) {
+	if id == 0 {
+		return 0, nil
+	}
+	restored, ok := rs.senseIDs[id]
+	if !ok {
+		return 0, fmt.Errorf("line %d: %s of sense %d, which is not in the dump before it", line, recordType, id)
+	}
+	return restored, nil
+}
+
+func (rs *restorer) restoreExample(ctx context.Context, line int, record dumpExample) error {
+	wordID, ok := rs.ids[record.WordID]
+	if !ok {
+		return fmt.Errorf("line %d: example of word %d, which is not in the dump before it", line, record.WordID)
+	}
+	senseID, err := rs.senseID(line, "example", record.SenseID)
+	if err != nil {
+		return err
+	}
+	example := &model.Example{WordID: wordID, Text: record.Text, Language: record.Language, Source: record.Source}
+	if senseID != 0 {
+		example.SenseID = &senseID
+	}
+	if record.TranslationID != 0 {
+		translatedID, ok := rs.exampleIDs[record.TranslationID]
+		if !ok {
+			return fmt.Errorf("line %d: translation of example %d, which is not in the dump before it", line, record.TranslationID)
+		}
+		example.TranslatedExampleID = &translatedID
+	}
+
+	existing, err := rs.s.ExamplesOf(ctx, []int{wordID})
+	if err != nil {
+		return err
+	}
+	rs.stats.Examples++
+	for _, stored := range existing[wordID] {
+		if stored.Text != example.Text || stored.Language != example.Language {
+			continue
+		}
+		rs.exampleIDs[record.ID] = stored.ID
+		// An existing example which is not a translation yet is linked to the translation given by the dump.
+		if stored.TranslatedExampleID == nil && example.TranslatedExampleID != nil && *example.TranslatedExampleID != stored.ID {
+			stored.TranslatedExampleID = example.TranslatedExampleID
+			return rs.s.UpdateExample(ctx, stored)
+		}
+		return nil
+	}
+	if err := rs.s.AddExample(ctx, example); err != nil {
+		return err
+	}
+	rs.exampleIDs[record.ID] = example.ID
+	rs.stats.ExamplesCreated++
+	return nil
+}
+
+func (rs *restorer) restorePronunciation(ctx context.Context, line int, record dumpPronunciation) error {
+	wordID, ok := rs.ids[record.WordID]
+	if !ok {
+		return fmt.Errorf("line %d: pronunciation of word %d, which is not in the dump before it", line, record.WordID)
+	}
+	created, err := rs.s.FindOrCreatePronunciation(ctx, &model.Pronunciation{WordID: wordID, IPA: record.IPA, Accent: record.Accent})
+	if err != nil {
+		return err
+	}
+	rs.stats.Pronunciations++
+	if created {
+		rs.stats.PronunciationsCreated++
+	}
+	return nil
+}
+
+func (rs *restorer) restoreCollection(ctx context.Context, line int, record dumpCollection) error {
+	wordIDs := make([]int, len(record.WordIDs))
+	for i, id := range record.WordIDs {
+		wordID, ok := rs.ids[id]
+		if !ok {
+			return fmt.Errorf("line %d: collection of word %d, which is not in the dump before it", line, id)
+		}
+		wordIDs[i] = wordID
+	}
+
+	collection, err := rs.s.FindCollection(ctx, record.Name)
+	if errors.Is(err, store.ErrNotFound) {
+		collection = &model.Collection{Name: record.Name, Description: record.Description}
+		if err := rs.s.AddCollection(ctx, collection); err != nil {
+			return err
+		}
+		rs.stats.CollectionsCreated++
+	} else if err != nil {
+		return err
+	}
+	rs.stats.Collections++
+	for start := 0; start < len(wordIDs); start += importBatchSize {
+		if err := rs.s.AddToCollection(ctx, collection.ID, wordIDs[start:min(start+importBatchSize, len(wordIDs))]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (rs *restorer) flushWords(ctx context.Context) error {
+	if len(rs.words) == 0 {
+		return nil
+	}
+	words := make([]*model.Word, len(rs.words))
+	for i, word := range rs.words {
+		words[i] = &model.Word{Text: word.Text, DisplayText: word.DisplayText, Language: word.Language}
+	}
+	created, err := rs.s.FindOrCreateWords(ctx, words)
+	if err != nil {
+		return err
+	}
+	usages := make([][]string, len(words))
+	for i, word := range rs.words {
+		if created[i] && word.ExampleUsage != "" {
+			usages[i] = []string{word.ExampleUsage}
+		}
+	}
+	if err := store.AddExampleTexts(ctx, rs.s, words, usages); err != nil {
+		return err
+	}
+	tagged := make(map[string][]int)
+	var tags []string
+	for i, word := range rs.words {
+		rs.ids[word.ID] = words[i].ID
+		rs.stats.Words++
+		if created[i] {
+			rs.stats.WordsCreated++
+		}
+		for _, tag := range word.Tags {
+			if tagged[tag] == nil {
+				tags = append(tags, tag)
+			}
+			tagged[tag] = append(tagged[tag], words[i].ID)
+		}
+	}
+	for _, tag := range tags {
+		if err := rs.s.TagWords(ctx, tagged[tag], []string{tag}); err != nil {
+			return err
+		}
+	}
+	rs.words = rs.words[:0]
+	return nil
+}
+
+func (rs *restorer) flushTranslations(ctx context.Context) error {
+	if len(rs.translations) == 0 {
+		return nil
+	}
+	created, err := rs.s.AddTranslations(ctx, rs.translations)
+	if err != nil {
+		return err
+	}
+	for _, ok := range created {
+		rs.stats.Translations++
+		if ok {
+			rs.stats.TranslationsCreated++
+		}
+	}
+	rs.translations = rs.translations[:0]
+	return nil
+}
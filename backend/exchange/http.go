@@ -0,0 +1,165 @@
+package exchange
+
+import (
+	"backend/graph/model"
+	"backend/store"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+// maxUploadMemory is how much of a multipart upload is kept in memory, the rest is buffered in temporary files.
+const maxUploadMemory = 32 << 20
+
+// ExportHandler serves the dictionary written by export as a download named filename.
+func ExportHandler(s store.DictionaryStore, export func(context.Context, store.DictionaryStore, io.Writer) error, contentType string, filename string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		response := &startedResponse{ResponseWriter: w}
+		if err := export(r.Context(), s, response); err != nil {
+			log.Printf("export of %s failed: %v", filename, err)
+			if !response.started {
+				w.Header().Del("Content-Disposition")
+				http.Error(w, "export failed", http.StatusInternalServerError)
+			}
+			// Otherwise the response is already under way, the download ends short.
+		}
+	})
+}
+
+// startedResponse records whether anything was written to the response.
+type startedResponse struct {
+	http.ResponseWriter
+	started bool
+}
+
+func (w *startedResponse) Write(data []byte) (int, error) {
+	w.started = true
+	return w.ResponseWriter.Write(data)
+}
+
+// AnkiHandler serves the Anki deck of the language pair given by the source and target query parameters.
+func AnkiHandler(s store.DictionaryStore) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var languages []*model.Language
+		for _, parameter := range []string{"source", "target"} {
+			code := r.URL.Query().Get(parameter)
+			if code == "" {
+				http.Error(w, fmt.Sprintf("missing %s language", parameter), http.StatusBadRequest)
+				return
+			}
+			language, err := store.ResolveLanguage(r.Context(), s, code)
+			if errors.Is(err, store.ErrUnsupportedLanguage) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			} else if err != nil {
+				log.Printf("export of anki deck failed: %v", err)
+				http.Error(w, "export failed", http.StatusInternalServerError)
+				return
+			}
+			languages = append(languages, language)
+		}
+
+		source, target := languages[0].Code, languages[1].Code
+		export := func(ctx context.Context, s store.DictionaryStore, w io.Writer) error {
+			return ExportAnki(ctx, s, w, source, target)
+		}
+		filename := fmt.Sprintf("dictionary-%s-%s.txt", source, target)
+		ExportHandler(s, export, "text/tab-separated-values; charset=utf-8", filename).ServeHTTP(w, r)
+	})
+}
+
+// CollectionHandler serves the translations of the collection given by the name query parameter as CSV,
+// into the language given by the optional target query parameter.
+func CollectionHandler(s store.DictionaryStore) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Query().Get("name")
+		if name == "" {
+			http.Error(w, "missing collection name", http.StatusBadRequest)
+			return
+		}
+		collection, err := s.FindCollection(r.Context(), name)
+		if errors.Is(err, store.ErrNotFound) {
+			http.Error(w, fmt.Sprintf("unknown collection %q", name), http.StatusNotFound)
+			return
+		} else if err != nil {
+			log.Printf("export of collection %q failed: %v", name, err)
+			http.Error(w, "export failed", http.StatusInternalServerError)
+			return
+		}
+		target := r.URL.Query().Get("target")
+		if target != "" {
+			language, err := store.ResolveLanguage(r.Context(), s, target)
+			if errors.Is(err, store.ErrUnsupportedLanguage) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			} else if err != nil {
+				log.Printf("export of collection %q failed: %v", name, err)
+				http.Error(w, "export failed", http.StatusInternalServerError)
+				return
+			}
+			target = language.Code
+		}
+
+		export := func(ctx context.Context, s store.DictionaryStore, w io.Writer) error {
+			return ExportCollection(ctx, s, w, collection.Name, target)
+		}
+		filename := fmt.Sprintf("collection-%d.csv", collection.ID)
+		ExportHandler(s, export, "text/csv; charset=utf-8", filename).ServeHTTP(w, r)
+	})
+}
+
+// ImportHandler imports translations in format posted as the request body or as the "file" field of a multipart form,
+// answering with the import report as JSON. The dryRun query parameter set to true only reports what would be stored.
+func ImportHandler(s store.DictionaryStore, format model.TranslationFileFormat) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		dryRun := false
+		if value := r.URL.Query().Get("dryRun"); value != "" {
+			var err error
+			dryRun, err = strconv.ParseBool(value)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("invalid dryRun %q", value), http.StatusBadRequest)
+				return
+			}
+		}
+
+		var body io.Reader = r.Body
+		if err := r.ParseMultipartForm(maxUploadMemory); err == nil {
+			file, _, err := r.FormFile("file")
+			if err != nil {
+				http.Error(w, "missing file field", http.StatusBadRequest)
+				return
+			}
+			defer file.Close()
+			body = file
+		} else if !errors.Is(err, http.ErrNotMultipart) {
+			http.Error(w, fmt.Sprintf("malformed upload: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		report, err := ImportTranslations(r.Context(), s, body, format, dryRun)
+		if err != nil {
+			log.Printf("import failed: %v", err)
+			http.Error(w, "import failed", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(report)
+	})
+}
@@ -0,0 +1,226 @@
+// Package exchange moves dictionary data between the store and files used by other tools.
+package exchange
+
+import (
+	"backend/graph/model"
+	"backend/store"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// importBatchSize is how many translations are stored in a single transaction.
+const importBatchSize = 500
+
+// errDryRun rolls back the transaction of a batch in a dry run.
+var errDryRun = errors.New("dry run")
+
+// ImportTranslations reads translations from r and stores them like addTranslation does, creating missing
+// words and skipping existing translations. Example usages found in the file are kept on words the import creates.
+// Translations are stored in batches, each in a transaction. In a dry run every batch is rolled back,
+// so the report tells what the import would do.
+func ImportTranslations(ctx context.Context, s store.DictionaryStore, r io.Reader, format model.TranslationFileFormat, dryRun bool) (*model.ImportReport, error) {
+	im := &importer{
+		s:              s,
+		dryRun:         dryRun,
+		report:         &model.ImportReport{DryRun: dryRun, Issues: []*model.ImportRowIssue{}},
+		languages:      make(map[string]*model.Language),
+		languageErrors: make(map[string]error),
+		lines:          make(map[pairKey]int),
+	}
+
+	var err error
+	switch format {
+	case model.TranslationFileFormatCSV, model.TranslationFileFormatTsv:
+		err = importDelimited(ctx, im, r, format)
+	case model.TranslationFileFormatTmx:
+		err = importTMX(ctx, im, r)
+	case model.TranslationFileFormatTbx:
+		err = importTBX(ctx, im, r)
+	default:
+		return nil, fmt.Errorf("unsupported import format %q", format)
+	}
+	if err == nil {
+		err = im.flush(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(im.report.Issues, func(i, j int) bool { return im.report.Issues[i].Line < im.report.Issues[j].Line })
+	return im.report, nil
+}
+
+// importedWord is a word as written in an import file, along with the texts of its examples.
+type importedWord struct {
+	text     string
+	language string
+	examples []string
+}
+
+type importRow struct {
+	line   int
+	source model.Word
+	target model.Word
+	// sourceExamples and targetExamples are added to the words when they are created.
+	sourceExamples []string
+	targetExamples []string
+}
+
+// pairKey identifies a translation by the normalized words it connects, in either direction.
+type pairKey struct {
+	firstText, firstLanguage   string
+	secondText, secondLanguage string
+}
+
+func newPairKey(source, target model.Word) pairKey {
+	if target.Language < source.Language || target.Language == source.Language && target.Text < source.Text {
+		source, target = target, source
+	}
+	return pairKey{source.Text, source.Language, target.Text, target.Language}
+}
+
+// importer validates translations read from a file and stores them in batches.
+type importer struct {
+	s      store.DictionaryStore
+	dryRun bool
+	report *model.ImportReport
+	// languages caches languages by the code written in the file, languageErrors the codes failing to resolve.
+	languages      map[string]*model.Language
+	languageErrors map[string]error
+	// lines maps every translation read so far to the line it was first read on.
+	lines map[pairKey]int
+	batch []importRow
+}
+
+func (im *importer) issue(line int, status model.ImportRowStatus, message string) {
+	im.report.Issues = append(im.report.Issues, &model.ImportRowIssue{Line: int32(line), Status: status, Message: message})
+	if status == model.ImportRowStatusDuplicate {
+		im.report.Duplicates++
+	} else {
+		im.report.Invalid++
+	}
+}
+
+// add validates and normalizes a translation read on line, storing it with the next batch.
+// Invalid and repeated translations are reported, only store errors are returned.
+func (im *importer) add(ctx context.Context, line int, source importedWord, target importedWord) error {
+	row := importRow{line: line, sourceExamples: source.examples, targetExamples: target.examples}
+	var err error
+	row.source, err = im.normalize(ctx, source)
+	if err != nil {
+		return im.invalid(line, err)
+	}
+	row.target, err = im.normalize(ctx, target)
+	if err != nil {
+		return im.invalid(line, err)
+	}
+	if row.source.Text == "" || row.target.Text == "" {
+		im.issue(line, model.ImportRowStatusInvalid, "word and language must not be empty")
+		return nil
+	}
+	if row.source.Text == row.target.Text && row.source.Language == row.target.Language {
+		im.issue(line, model.ImportRowStatusInvalid, "a word cannot be a translation of itself")
+		return nil
+	}
+
+	key := newPairKey(row.source, row.target)
+	if first, ok := im.lines[key]; ok {
+		im.issue(line, model.ImportRowStatusDuplicate, fmt.Sprintf("translation repeats line %d", first))
+		return nil
+	}
+	im.lines[key] = line
+	im.batch = append(im.batch, row)
+	if len(im.batch) == importBatchSize {
+		return im.flush(ctx)
+	}
+	return nil
+}
+
+// invalid reports a translation failing validation, errors of the store itself abort the import.
+func (im *importer) invalid(line int, err error) error {
+	if errors.Is(err, store.ErrTimeout) || errors.Is(err, context.Canceled) {
+		return err
+	}
+	im.issue(line, model.ImportRowStatusInvalid, err.Error())
+	return nil
+}
+
+func (im *importer) normalize(ctx context.Context, word importedWord) (model.Word, error) {
+	code := word.language
+	if strings.TrimSpace(code) == "" {
+		return model.Word{}, fmt.Errorf("word and language must not be empty")
+	}
+	if err, ok := im.languageErrors[code]; ok {
+		return model.Word{}, err
+	}
+	language, ok := im.languages[code]
+	if !ok {
+		var err error
+		language, err = store.ResolveLanguage(ctx, im.s, code)
+		if err != nil {
+			im.languageErrors[code] = err
+			return model.Word{}, err
+		}
+		im.languages[code] = language
+	}
+	return store.NormalizeWordIn(word.text, language), nil
+}
+
+// flush stores the queued translations in a transaction and reports the ones which existed.
+func (im *importer) flush(ctx context.Context) error {
+	if len(im.batch) == 0 {
+		return nil
+	}
+	var created []bool
+	err := im.s.Transaction(ctx, func(tx store.DictionaryStore) error {
+		words := make([]*model.Word, 0, 2*len(im.batch))
+		examples := make([][]string, 0, 2*len(im.batch))
+		for i, row := range im.batch {
+			words = append(words, &im.batch[i].source, &im.batch[i].target)
+			examples = append(examples, row.sourceExamples, row.targetExamples)
+		}
+		wordsCreated, err := tx.FindOrCreateWords(ctx, words)
+		if err != nil {
+			return err
+		}
+		// Like their other fields, words which exist keep their examples.
+		for i, ok := range wordsCreated {
+			if !ok {
+				examples[i] = nil
+			}
+		}
+		if err := store.AddExampleTexts(ctx, tx, words, examples); err != nil {
+			return err
+		}
+
+		translations := make([]model.Translation, len(im.batch))
+		for i, row := range im.batch {
+			translations[i] = model.Translation{WordID: row.source.ID, TranslationID: row.target.ID}
+		}
+		created, err = tx.AddTranslations(ctx, translations)
+		if err != nil {
+			return err
+		}
+		if im.dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return err
+	}
+
+	for i, row := range im.batch {
+		if created[i] {
+			im.report.Created++
+		} else {
+			im.issue(row.line, model.ImportRowStatusDuplicate, "translation already exists")
+		}
+	}
+	im.batch = im.batch[:0]
+	return nil
+}
@@ -0,0 +1,289 @@
+package exchange
+
+import (
+	"backend/graph/model"
+	"backend/store"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"sort"
+)
+
+const tbxHeader = `<?xml version="1.0" encoding="UTF-8"?>
+<tbx type="TBX-Basic" style="dca" xml:lang="en" xmlns="urn:iso:std:iso:30042:ed-2">
+  <tbxHeader>
+    <fileDesc>
+      <sourceDesc>
+        <p>Exported from go gqlgen app</p>
+      </sourceDesc>
+    </fileDesc>
+  </tbxHeader>
+  <text>
+    <body>
+`
+
+const tbxFooter = `    </body>
+  </text>
+</tbx>
+`
+
+// ExportTBX writes the dictionary as a TBX-Basic termbase (ISO 30042). Words connected by translations, directly
+// or through other words, are terms of one concept entry, grouped in a language section per language.
+// Examples of the words in their language are written as contexts of the terms. Every translation is kept
+// as a cross reference from the term of its word to the term of its translation, so an import restores
+// the very same translations. Concepts are found from the IDs of translated words, read a page at a time,
+// then the words of a few concepts at a time are loaded to be written, each page and group of concepts in
+// a transaction of its own, see exportWordPages. Words deleted in between are left out of their concepts.
+func ExportTBX(ctx context.Context, s store.DictionaryStore, w io.Writer) error {
+	out := newExportWriter(w)
+	out.WriteString(tbxHeader)
+	references := make(map[int][]int)
+	err := exportWordPages(ctx, s, out, store.WordFilter{}, func(tx store.DictionaryStore, words []*model.Word, ids []int) error {
+		translations, err := tx.TranslationsOfWords(ctx, ids)
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			// Translations are referenced from the word with the smaller ID, as they are stored.
+			for _, translation := range translations[id] {
+				if translation.ID > id {
+					references[id] = append(references[id], translation.ID)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	concepts := connectedWords(references)
+	for start := 0; start < len(concepts); {
+		end, size := start, 0
+		for end < len(concepts) && (end == start || size+len(concepts[end]) <= exportBatchSize) {
+			size += len(concepts[end])
+			end++
+		}
+		err := s.Transaction(ctx, func(tx store.DictionaryStore) error {
+			words, err := wordsByIDs(ctx, tx, slices.Concat(concepts[start:end]...))
+			if err != nil {
+				return err
+			}
+			examples, err := exampleTexts(ctx, tx, slices.Collect(maps.Values(words)))
+			if err != nil {
+				return err
+			}
+			for _, concept := range concepts[start:end] {
+				writeConcept(out, concept, words, examples, references)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if err := out.flush(); err != nil {
+			return err
+		}
+		start = end
+	}
+	out.WriteString(tbxFooter)
+	return out.flush()
+}
+
+// writeConcept writes the concept entry of the words with given IDs which words holds, unless it holds none of them.
+func writeConcept(out *exportWriter, concept []int, words map[int]*model.Word, examples map[int][]string, references map[int][]int) {
+	concept = slices.DeleteFunc(slices.Clone(concept), func(id int) bool { return words[id] == nil })
+	if len(concept) == 0 {
+		return
+	}
+	fmt.Fprintf(out, "      <conceptEntry id=\"c%d\">\n", concept[0])
+	sort.SliceStable(concept, func(i, j int) bool { return words[concept[i]].Language < words[concept[j]].Language })
+	for i, id := range concept {
+		word := words[id]
+		if i == 0 || words[concept[i-1]].Language != word.Language {
+			if i > 0 {
+				out.WriteString("        </langSec>\n")
+			}
+			out.WriteString(`        <langSec xml:lang="`)
+			xml.EscapeText(out, []byte(word.Language))
+			out.WriteString("\">\n")
+		}
+		targets := slices.DeleteFunc(slices.Clone(references[id]), func(target int) bool { return words[target] == nil })
+		writeTermSec(out, word, examples[id], targets)
+	}
+	out.WriteString("        </langSec>\n      </conceptEntry>\n")
+}
+
+// connectedWords groups the words of translations into connected components, ordered by their smallest word ID.
+// The word IDs of a component are sorted.
+func connectedWords(references map[int][]int) [][]int {
+	parent := make(map[int]int)
+	var find func(id int) int
+	find = func(id int) int {
+		if _, ok := parent[id]; !ok {
+			parent[id] = id
+		}
+		if parent[id] != id {
+			parent[id] = find(parent[id])
+		}
+		return parent[id]
+	}
+	for id, targets := range references {
+		for _, target := range targets {
+			first, second := find(id), find(target)
+			if first != second {
+				parent[max(first, second)] = min(first, second)
+			}
+		}
+	}
+
+	components := make(map[int][]int)
+	for id := range parent {
+		root := find(id)
+		components[root] = append(components[root], id)
+	}
+	concepts := make([][]int, 0, len(components))
+	for _, component := range components {
+		sort.Ints(component)
+		concepts = append(concepts, component)
+	}
+	sort.Slice(concepts, func(i, j int) bool { return concepts[i][0] < concepts[j][0] })
+	return concepts
+}
+
+func writeTermSec(out *exportWriter, word *model.Word, examples []string, references []int) {
+	fmt.Fprintf(out, "          <termSec id=\"t%d\">\n            <term>", word.ID)
+	xml.EscapeText(out, []byte(displayText(word)))
+	out.WriteString("</term>\n")
+	for _, example := range examples {
+		out.WriteString(`            <descrip type="context">`)
+		xml.EscapeText(out, []byte(example))
+		out.WriteString("</descrip>\n")
+	}
+	for _, target := range references {
+		fmt.Fprintf(out, "            <ref type=\"crossReference\" target=\"t%d\"/>\n", target)
+	}
+	out.WriteString("          </termSec>\n")
+}
+
+// tbxConcept is a concept entry of TBX, or a term entry of TBX 2008 whose elements are named differently.
+type tbxConcept struct {
+	LangSecs []tbxLangSec `xml:"langSec"`
+	LangSets []tbxLangSec `xml:"langSet"`
+}
+
+type tbxLangSec struct {
+	Lang     string    `xml:"lang,attr"`
+	TermSecs []tbxTerm `xml:"termSec"`
+	Tigs     []tbxTerm `xml:"tig"`
+	Ntigs    []tbxTerm `xml:"ntig"`
+}
+
+type tbxTerm struct {
+	ID          string       `xml:"id,attr"`
+	Term        string       `xml:"term"`
+	TermGrp     string       `xml:"termGrp>term"`
+	Descrips    []tbxDescrip `xml:"descrip"`
+	DescripGrps []tbxDescrip `xml:"descripGrp>descrip"`
+	Refs        []tbxRef     `xml:"ref"`
+	language    string
+}
+
+type tbxDescrip struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type tbxRef struct {
+	Type   string `xml:"type,attr"`
+	Target string `xml:"target,attr"`
+}
+
+func (c tbxConcept) terms() []tbxTerm {
+	var terms []tbxTerm
+	for _, langSec := range append(c.LangSecs, c.LangSets...) {
+		for _, term := range append(append(langSec.TermSecs, langSec.Tigs...), langSec.Ntigs...) {
+			term.language = langSec.Lang
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+func (t tbxTerm) word() importedWord {
+	var contexts []string
+	for _, descrip := range append(t.Descrips, t.DescripGrps...) {
+		if descrip.Type == "context" {
+			contexts = append(contexts, descrip.Value)
+		}
+	}
+	text := t.Term
+	if text == "" {
+		text = t.TermGrp
+	}
+	return importedWord{text: text, language: t.language, examples: contexts}
+}
+
+// importTBX reads the concept entries of a TBX file, TBX-Basic as well as the term entries of TBX 2008.
+// Cross references between terms of an entry are its translations. Entries without them, e.g. from other tools,
+// hold a translation for every pair of terms in different languages. Issues are reported at the line of the entry.
+func importTBX(ctx context.Context, im *importer, r io.Reader) error {
+	return importXML(im, r, "TBX", []string{"conceptEntry", "termEntry"}, func(decoder *xml.Decoder, start *xml.StartElement, line int) error {
+		var concept tbxConcept
+		if err := decoder.DecodeElement(&concept, start); err != nil {
+			return err
+		}
+		terms := concept.terms()
+
+		type pair struct{ source, target int }
+		var pairs []pair
+		byID := make(map[string]int)
+		for i, term := range terms {
+			if term.ID != "" {
+				byID[term.ID] = i
+			}
+		}
+		seen := make(map[pair]bool)
+		hasReferences := false
+		for i, term := range terms {
+			for _, ref := range term.Refs {
+				if ref.Type != "crossReference" {
+					continue
+				}
+				hasReferences = true
+				target, ok := byID[ref.Target]
+				if !ok {
+					im.issue(line, model.ImportRowStatusInvalid, fmt.Sprintf("reference to unknown term %q", ref.Target))
+					continue
+				}
+				if !seen[pair{i, target}] && !seen[pair{target, i}] {
+					seen[pair{i, target}] = true
+					pairs = append(pairs, pair{i, target})
+				}
+			}
+		}
+		if !hasReferences {
+			for i := range terms {
+				for j := i + 1; j < len(terms); j++ {
+					if terms[i].language != terms[j].language {
+						pairs = append(pairs, pair{i, j})
+					}
+				}
+			}
+		}
+		if len(pairs) == 0 && !hasReferences {
+			im.issue(line, model.ImportRowStatusInvalid, "expected terms in at least 2 languages")
+			return nil
+		}
+
+		for _, pair := range pairs {
+			if err := im.add(ctx, line, terms[pair.source].word(), terms[pair.target].word()); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
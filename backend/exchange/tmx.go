@@ -0,0 +1,119 @@
+package exchange
+
+import (
+	"backend/graph/model"
+	"backend/store"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"slices"
+)
+
+const tmxHeader = `<?xml version="1.0" encoding="UTF-8"?>
+<tmx version="1.4">
+  <header creationtool="go gqlgen app" creationtoolversion="1.0" datatype="plaintext" segtype="phrase" adminlang="en" srclang="*all*" o-tmf="go gqlgen app"/>
+  <body>
+`
+
+const tmxFooter = `  </body>
+</tmx>
+`
+
+// ExportTMX writes every translation as a translation unit of a TMX 1.4b document, with a variant per word
+// in the language of the word. Examples of the words in their language are written as notes of the variants,
+// an import adds them as examples of the words it creates. Words and their translations are read
+// a page at a time, see exportWordPages.
+func ExportTMX(ctx context.Context, s store.DictionaryStore, w io.Writer) error {
+	out := newExportWriter(w)
+	out.WriteString(tmxHeader)
+	err := exportWordPages(ctx, s, out, store.WordFilter{}, func(tx store.DictionaryStore, words []*model.Word, ids []int) error {
+		translations, err := tx.TranslationsOfWords(ctx, ids)
+		if err != nil {
+			return err
+		}
+		var translated []*model.Word
+		for _, word := range words {
+			for _, translation := range translations[word.ID] {
+				if translation.ID > word.ID {
+					translated = append(translated, translation)
+				}
+			}
+		}
+		examples, err := exampleTexts(ctx, tx, slices.Concat(words, translated))
+		if err != nil {
+			return err
+		}
+		for _, word := range words {
+			// Every pair of words is written once, from the word with the smaller ID.
+			for _, translation := range translations[word.ID] {
+				if translation.ID < word.ID {
+					continue
+				}
+				fmt.Fprintf(out, "    <tu tuid=\"%d-%d\">\n", word.ID, translation.ID)
+				writeTUV(out, word, examples[word.ID])
+				writeTUV(out, translation, examples[translation.ID])
+				out.WriteString("    </tu>\n")
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	out.WriteString(tmxFooter)
+	return out.flush()
+}
+
+func writeTUV(out *exportWriter, word *model.Word, examples []string) {
+	out.WriteString(`      <tuv xml:lang="`)
+	xml.EscapeText(out, []byte(word.Language))
+	out.WriteString("\">\n")
+	for _, example := range examples {
+		out.WriteString("        <note>")
+		xml.EscapeText(out, []byte(example))
+		out.WriteString("</note>\n")
+	}
+	out.WriteString("        <seg>")
+	xml.EscapeText(out, []byte(displayText(word)))
+	out.WriteString("</seg>\n      </tuv>\n")
+}
+
+// tmxUnit is a translation unit of a TMX file.
+type tmxUnit struct {
+	Variants []tmxVariant `xml:"tuv"`
+}
+
+// tmxVariant is a variant of a unit in one language. Lang matches both xml:lang and lang, used by TMX 1.1 and older.
+type tmxVariant struct {
+	Lang  string   `xml:"lang,attr"`
+	Notes []string `xml:"note"`
+	Seg   string   `xml:"seg"`
+}
+
+func (v tmxVariant) word() importedWord {
+	return importedWord{text: v.Seg, language: v.Lang, examples: v.Notes}
+}
+
+// importTMX reads the translation units of a TMX file. The variants of a unit are translations of each other,
+// so a unit of n variants holds a translation for every pair of them. Issues are reported at the line of the unit.
+func importTMX(ctx context.Context, im *importer, r io.Reader) error {
+	return importXML(im, r, "TMX", []string{"tu"}, func(decoder *xml.Decoder, start *xml.StartElement, line int) error {
+		var unit tmxUnit
+		if err := decoder.DecodeElement(&unit, start); err != nil {
+			return err
+		}
+		if len(unit.Variants) < 2 {
+			im.issue(line, model.ImportRowStatusInvalid, fmt.Sprintf("expected at least 2 variants, got %d", len(unit.Variants)))
+			return nil
+		}
+		for i, source := range unit.Variants {
+			for _, target := range unit.Variants[i+1:] {
+				if err := im.add(ctx, line, source.word(), target.word()); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,174 @@
+package exchange
+
+import (
+	"backend/graph/model"
+	"backend/store"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// exportBatchSize is how many words an export loads at once.
+const exportBatchSize = 500
+
+// eachWordPage calls fn with the words matching filter, page by page in the order of their IDs.
+func eachWordPage(ctx context.Context, s store.DictionaryStore, filter store.WordFilter, fn func(words []*model.Word, ids []int) error) error {
+	page := store.Page{Limit: exportBatchSize}
+	for {
+		words, err := s.ListWords(ctx, filter, page)
+		if err != nil {
+			return err
+		}
+		ids := wordIDs(words.Words)
+		if err := fn(words.Words, ids); err != nil {
+			return err
+		}
+		if !words.HasNextPage {
+			return nil
+		}
+		page.AfterID = ids[len(ids)-1]
+	}
+}
+
+// exportWriter holds what an export renders while a transaction is open, until flush writes it out
+// once the transaction ended.
+type exportWriter struct {
+	bytes.Buffer
+	w io.Writer
+}
+
+func newExportWriter(w io.Writer) *exportWriter {
+	return &exportWriter{w: w}
+}
+
+func (out *exportWriter) flush() error {
+	_, err := out.WriteTo(out.w)
+	return err
+}
+
+// exportWordPages calls fn with the words matching filter, page by page in the order of their IDs, and writes out
+// what fn rendered into out after each page. Every page is read in a transaction of its own, so an export
+// of any size stays within the statement timeout, and nothing is written while a transaction is open, so a slow
+// client holds up no transaction. What was rendered before, like the header of a document, is written along with
+// the first page, so an export failing at once writes nothing and can still be answered with an error.
+// Pages follow each other by word ID, a word changed during the export is written as it was when its page was read.
+func exportWordPages(ctx context.Context, s store.DictionaryStore, out *exportWriter, filter store.WordFilter, fn func(tx store.DictionaryStore, words []*model.Word, ids []int) error) error {
+	page := store.Page{Limit: exportBatchSize}
+	for {
+		var words *store.WordPage
+		err := s.Transaction(ctx, func(tx store.DictionaryStore) error {
+			var err error
+			words, err = tx.ListWords(ctx, filter, page)
+			if err != nil {
+				return err
+			}
+			return fn(tx, words.Words, wordIDs(words.Words))
+		})
+		if err != nil {
+			return err
+		}
+		if err := out.flush(); err != nil {
+			return err
+		}
+		if !words.HasNextPage {
+			return nil
+		}
+		page.AfterID = words.Words[len(words.Words)-1].ID
+	}
+}
+
+func wordIDs(words []*model.Word) []int {
+	ids := make([]int, len(words))
+	for i, word := range words {
+		ids[i] = word.ID
+	}
+	return ids
+}
+
+// wordsByIDs loads the words with given IDs, exportBatchSize of them at a time.
+func wordsByIDs(ctx context.Context, s store.DictionaryStore, ids []int) (map[int]*model.Word, error) {
+	words := make(map[int]*model.Word, len(ids))
+	for start := 0; start < len(ids); start += exportBatchSize {
+		batch, err := s.FindWordsByIDs(ctx, ids[start:min(start+exportBatchSize, len(ids))])
+		if err != nil {
+			return nil, err
+		}
+		for _, word := range batch {
+			words[word.ID] = word
+		}
+	}
+	return words, nil
+}
+
+// exampleTexts returns the texts of the examples of the words in the language of each word, by word ID,
+// loading the examples of exportBatchSize words at a time.
+func exampleTexts(ctx context.Context, s store.DictionaryStore, words []*model.Word) (map[int][]string, error) {
+	texts := make(map[int][]string, len(words))
+	for start := 0; start < len(words); start += exportBatchSize {
+		batch := words[start:min(start+exportBatchSize, len(words))]
+		ids := make([]int, len(batch))
+		for i, word := range batch {
+			ids[i] = word.ID
+		}
+		examples, err := s.ExamplesOf(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, word := range batch {
+			for _, example := range examples[word.ID] {
+				if example.Language == word.Language {
+					texts[word.ID] = append(texts[word.ID], example.Text)
+				}
+			}
+		}
+	}
+	return texts, nil
+}
+
+// displayText is the text of a word as written by users, words stored before display texts existed have none.
+func displayText(word *model.Word) string {
+	if word.DisplayText != "" {
+		return word.DisplayText
+	}
+	return word.Text
+}
+
+// importXML calls decode for every element named one of names in the XML document read from r,
+// along with the line the element starts on. A malformed document is reported as an invalid row at the line
+// of the error and ends the import of the document, keeping the translations read before.
+func importXML(im *importer, r io.Reader, format string, names []string, decode func(decoder *xml.Decoder, start *xml.StartElement, line int) error) error {
+	decoder := xml.NewDecoder(r)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		} else if err != nil && !isSyntaxError(err) {
+			return fmt.Errorf("failed to read import file: %w", err)
+		}
+		if err == nil {
+			start, ok := token.(xml.StartElement)
+			if !ok || !slices.Contains(names, start.Name.Local) {
+				continue
+			}
+			line, _ := decoder.InputPos()
+			err = decode(decoder, &start, line)
+		}
+
+		var syntaxErr *xml.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			im.issue(syntaxErr.Line, model.ImportRowStatusInvalid, fmt.Sprintf("malformed %s: %s", format, syntaxErr.Msg))
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+func isSyntaxError(err error) bool {
+	var syntaxErr *xml.SyntaxError
+	return errors.As(err, &syntaxErr)
+}
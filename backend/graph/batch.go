@@ -0,0 +1,261 @@
+package graph
+
+import (
+	"backend/graph/model"
+	"backend/store"
+	"context"
+	"errors"
+)
+
+// maxBatchSize bounds the number of items of a batch mutation.
+const maxBatchSize = 5000
+
+// errBatchRejected rolls back the transaction of an atomic batch with failed items.
+var errBatchRejected = errors.New("batch rejected")
+
+// batch runs the items of a batch mutation in a single transaction. Items failing validation are reported
+// in their results. An atomic batch with a failed item is rolled back as a whole, otherwise the other items
+// are committed. Errors of the store fail the whole request.
+type batch struct {
+	atomic bool
+	failed bool
+	// languages caches languages by the code given in the input, languageErrors the codes failing to resolve.
+	languages      map[string]*model.Language
+	languageErrors map[string]error
+}
+
+func newBatch(size int, atomic *bool) (*batch, error) {
+	if size > maxBatchSize {
+		return nil, invalidInput("batch must not have more than %d items", maxBatchSize)
+	}
+	return &batch{
+		atomic:         atomic == nil || *atomic,
+		languages:      make(map[string]*model.Language),
+		languageErrors: make(map[string]error),
+	}, nil
+}
+
+// run validates the items and applies the valid ones in a transaction, unless the batch is atomic
+// and an item failed. It reports whether the batch was committed.
+func (b *batch) run(ctx context.Context, s store.DictionaryStore, validate func(tx store.DictionaryStore) error, apply func(tx store.DictionaryStore) error) (bool, error) {
+	err := s.Transaction(ctx, func(tx store.DictionaryStore) error {
+		if err := validate(tx); err != nil {
+			return err
+		}
+		if b.failed && b.atomic {
+			return errBatchRejected
+		}
+		return apply(tx)
+	})
+	if errors.Is(err, errBatchRejected) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// itemError builds the error of a failed item, marking the batch as failed.
+func (b *batch) itemError(err error) *model.BatchItemError {
+	b.failed = true
+	return &model.BatchItemError{Code: errorCode(err), Message: err.Error()}
+}
+
+// normalize returns the normalized word of an item. Errors of invalid items are returned as item errors,
+// errors of the store as err.
+func (b *batch) normalize(ctx context.Context, tx store.DictionaryStore, text string, code string) (model.Word, *model.BatchItemError, error) {
+	if text == "" || code == "" {
+		return model.Word{}, b.itemError(invalidInput("word and language must not be empty")), nil
+	}
+	if err, ok := b.languageErrors[code]; ok {
+		return model.Word{}, b.itemError(err), nil
+	}
+	language, ok := b.languages[code]
+	if !ok {
+		var err error
+		language, err = store.ResolveLanguage(ctx, tx, code)
+		if errors.Is(err, store.ErrUnsupportedLanguage) {
+			b.languageErrors[code] = err
+			return model.Word{}, b.itemError(err), nil
+		} else if err != nil {
+			return model.Word{}, nil, err
+		}
+		b.languages[code] = language
+	}
+
+	word := store.NormalizeWordIn(text, language)
+	if word.Text == "" {
+		return model.Word{}, b.itemError(invalidInput("word and language must not be empty")), nil
+	}
+	return word, nil, nil
+}
+
+// addWords adds the words like addWord does.
+func addWords(ctx context.Context, s store.DictionaryStore, input []*model.WordInput, atomic *bool) (*model.AddWordsPayload, error) {
+	b, err := newBatch(len(input), atomic)
+	if err != nil {
+		return nil, err
+	}
+	payload := &model.AddWordsPayload{Results: make([]*model.WordResult, len(input))}
+	var words []*model.Word
+	var usages [][]string
+	var results []*model.WordResult
+	validate := func(tx store.DictionaryStore) error {
+		for i, item := range input {
+			payload.Results[i] = &model.WordResult{Index: int32(i)}
+			word, itemErr, err := b.normalize(ctx, tx, item.Text, item.Language)
+			if err != nil {
+				return err
+			}
+			if itemErr != nil {
+				payload.Results[i].Error = itemErr
+				continue
+			}
+			var usage []string
+			if item.ExampleUsage != nil {
+				usage = []string{*item.ExampleUsage}
+			}
+			words = append(words, &word)
+			usages = append(usages, usage)
+			results = append(results, payload.Results[i])
+		}
+		return nil
+	}
+	apply := func(tx store.DictionaryStore) error {
+		created, err := tx.FindOrCreateWords(ctx, words)
+		if err != nil {
+			return err
+		}
+		for i, result := range results {
+			result.Word, result.Created = words[i], created[i]
+			if !created[i] {
+				usages[i] = nil
+			}
+		}
+		return store.AddExampleTexts(ctx, tx, words, usages)
+	}
+
+	payload.Committed, err = b.run(ctx, s, validate, apply)
+	if err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// addTranslations adds the translations like addTranslation does.
+func addTranslations(ctx context.Context, s store.DictionaryStore, input []*model.TranslationInput, atomic *bool) (*model.AddTranslationsPayload, error) {
+	b, err := newBatch(len(input), atomic)
+	if err != nil {
+		return nil, err
+	}
+	payload := &model.AddTranslationsPayload{Results: make([]*model.TranslationResult, len(input))}
+	var words []*model.Word
+	var results []*model.TranslationResult
+	validate := func(tx store.DictionaryStore) error {
+		for i, item := range input {
+			payload.Results[i] = &model.TranslationResult{Index: int32(i)}
+			source, itemErr, err := b.normalize(ctx, tx, item.SourceText, item.SourceTextLanguage)
+			var translated model.Word
+			if err == nil && itemErr == nil {
+				translated, itemErr, err = b.normalize(ctx, tx, item.TranslatedText, item.TranslatedTextLanguage)
+			}
+			if err != nil {
+				return err
+			}
+			if itemErr != nil {
+				payload.Results[i].Error = itemErr
+				continue
+			}
+			words = append(words, &source, &translated)
+			results = append(results, payload.Results[i])
+		}
+		return nil
+	}
+	apply := func(tx store.DictionaryStore) error {
+		_, err := tx.FindOrCreateWords(ctx, words)
+		if err != nil {
+			return err
+		}
+		translations := make([]model.Translation, len(results))
+		for i := range results {
+			translations[i] = model.Translation{WordID: words[2*i].ID, TranslationID: words[2*i+1].ID}
+			translations[i].SortTranslation()
+		}
+		created, err := tx.AddTranslations(ctx, translations)
+		if err != nil {
+			return err
+		}
+		for i, result := range results {
+			result.Translation, result.Created = &translations[i], created[i]
+		}
+		return nil
+	}
+
+	payload.Committed, err = b.run(ctx, s, validate, apply)
+	if err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// deleteTranslations deletes the translations like deleteTranslation does, missing ones are reported as not deleted.
+func deleteTranslations(ctx context.Context, s store.DictionaryStore, input []*model.TranslationInput, atomic *bool) (*model.DeleteTranslationsPayload, error) {
+	b, err := newBatch(len(input), atomic)
+	if err != nil {
+		return nil, err
+	}
+	payload := &model.DeleteTranslationsPayload{Results: make([]*model.DeleteTranslationResult, len(input))}
+	var translations []model.Translation
+	var results []*model.DeleteTranslationResult
+	validate := func(tx store.DictionaryStore) error {
+		for i, item := range input {
+			payload.Results[i] = &model.DeleteTranslationResult{Index: int32(i)}
+			source, itemErr, err := b.normalize(ctx, tx, item.SourceText, item.SourceTextLanguage)
+			var translated model.Word
+			if err == nil && itemErr == nil {
+				translated, itemErr, err = b.normalize(ctx, tx, item.TranslatedText, item.TranslatedTextLanguage)
+			}
+			if err != nil {
+				return err
+			}
+			if itemErr != nil {
+				payload.Results[i].Error = itemErr
+				continue
+			}
+
+			sourceWord, err := tx.FindWord(ctx, source.Text, source.Language)
+			if errors.Is(err, store.ErrNotFound) {
+				continue
+			} else if err != nil {
+				return err
+			}
+			translatedWord, err := tx.FindWord(ctx, translated.Text, translated.Language)
+			if errors.Is(err, store.ErrNotFound) {
+				continue
+			} else if err != nil {
+				return err
+			}
+			translation := model.Translation{WordID: sourceWord.ID, TranslationID: translatedWord.ID}
+			translation.SortTranslation()
+			translations = append(translations, translation)
+			results = append(results, payload.Results[i])
+		}
+		return nil
+	}
+	apply := func(tx store.DictionaryStore) error {
+		for i, result := range results {
+			deleted, err := tx.DeleteTranslation(ctx, translations[i])
+			if err != nil {
+				return err
+			}
+			if deleted {
+				result.Translation, result.Deleted = &translations[i], true
+			}
+		}
+		return nil
+	}
+
+	payload.Committed, err = b.run(ctx, s, validate, apply)
+	if err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
@@ -0,0 +1,42 @@
+package graph
+
+import (
+	"backend/graph/model"
+	"backend/store"
+	"context"
+	"strings"
+)
+
+// applyCollectionChange validates the name and description given in a mutation and sets them on the
+// collection, fields left out keep their value.
+func applyCollectionChange(collection *model.Collection, name *string, description *string) error {
+	if name != nil {
+		trimmed := strings.TrimSpace(*name)
+		if trimmed == "" {
+			return invalidInput("collection name must not be empty")
+		}
+		collection.Name = trimmed
+	}
+	if description != nil {
+		collection.Description = strings.TrimSpace(*description)
+	}
+	return nil
+}
+
+// collectionByID returns the collection with given database ID, or nil when there is none.
+func collectionByID(ctx context.Context, s store.DictionaryStore, id int) (*model.Collection, error) {
+	collections, err := s.FindCollectionsByIDs(ctx, []int{id})
+	if err != nil || len(collections) == 0 {
+		return nil, err
+	}
+	return collections[0], nil
+}
+
+// findCollection returns the collection a global ID refers to, or nil when it does not exist.
+func findCollection(ctx context.Context, s store.DictionaryStore, id string) (*model.Collection, error) {
+	collectionID, err := decodeID(id, collectionTypename)
+	if err != nil {
+		return nil, err
+	}
+	return collectionByID(ctx, s, collectionID)
+}
@@ -0,0 +1,539 @@
+package graph
+
+import (
+	"backend/graph/model"
+	"backend/store"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+)
+
+const (
+	// loaderWait is how long a loader collects keys before fetching them. Fields of a list are resolved
+	// concurrently, so their loads arrive within it.
+	loaderWait = 2 * time.Millisecond
+	// maxLoaderBatch bounds the keys fetched at once, a full batch is fetched without waiting.
+	maxLoaderBatch = 500
+)
+
+// loader batches loads of values by key, so fields resolved for every item of a list are fetched together.
+// Loaded values are cached for the life of the loader, every response gets its own loaders.
+type loader[K comparable, V any] struct {
+	fetch func(ctx context.Context, keys []K) (map[K]V, error)
+
+	mu    sync.Mutex
+	cache map[K]*loaderBatch[K, V]
+	// batch collects the keys of the next fetch, nil when there are none.
+	batch *loaderBatch[K, V]
+}
+
+// loaderBatch is a single fetch of a loader, done is closed when its values are fetched.
+type loaderBatch[K comparable, V any] struct {
+	keys []K
+	// scheduled is set once a load waits for the batch, which is then fetched after loaderWait.
+	scheduled bool
+	values    map[K]V
+	err       error
+	done      chan struct{}
+}
+
+func newLoader[K comparable, V any](fetch func(ctx context.Context, keys []K) (map[K]V, error)) *loader[K, V] {
+	return &loader[K, V]{fetch: fetch, cache: make(map[K]*loaderBatch[K, V])}
+}
+
+// load returns the value of key, the zero value when the fetch does not return it.
+func (l *loader[K, V]) load(ctx context.Context, key K) (V, error) {
+	l.mu.Lock()
+	b := l.add(ctx, key)
+	if b == l.batch && !b.scheduled {
+		b.scheduled = true
+		time.AfterFunc(loaderWait, func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			l.dispatch(ctx, b)
+		})
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-b.done:
+		return b.values[key], b.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// expect adds keys which are likely to be loaded to the next fetch without fetching them. Items of a list
+// being resolved are expected by the field returning the list, so they are fetched together however
+// the loads of their fields are scheduled.
+func (l *loader[K, V]) expect(ctx context.Context, keys []K) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, key := range keys {
+		l.add(ctx, key)
+	}
+}
+
+// add returns the batch key is fetched by, adding it to the next one when it is not cached. l.mu must be held.
+func (l *loader[K, V]) add(ctx context.Context, key K) *loaderBatch[K, V] {
+	if b, ok := l.cache[key]; ok {
+		return b
+	}
+	if l.batch == nil {
+		l.batch = &loaderBatch[K, V]{done: make(chan struct{})}
+	}
+	b := l.batch
+	b.keys = append(b.keys, key)
+	l.cache[key] = b
+	if len(b.keys) == maxLoaderBatch {
+		l.dispatch(ctx, b)
+	}
+	return b
+}
+
+// dispatch starts the fetch of b unless it was started already, l.mu must be held.
+func (l *loader[K, V]) dispatch(ctx context.Context, b *loaderBatch[K, V]) {
+	if l.batch != b {
+		return
+	}
+	l.batch = nil
+	go func() {
+		b.values, b.err = l.fetch(ctx, b.keys)
+		close(b.done)
+	}()
+}
+
+// languageResult is a language resolved from a code given in a query, or why it failed to resolve.
+type languageResult struct {
+	language *model.Language
+	err      error
+}
+
+// Loaders batch the lookups of nested fields, so a page of words loads its translations
+// in the same number of queries as a single word does.
+type Loaders struct {
+	wordByID               *loader[int, *model.Word]
+	translationsByWordID   *loader[int, []*model.Word]
+	senseByID              *loader[int, *model.Sense]
+	sensesByWordID         *loader[int, []*model.Sense]
+	translationsBySenseID  *loader[int, []model.Translation]
+	exampleByID            *loader[int, *model.Example]
+	examplesByWordID       *loader[int, []*model.Example]
+	pronunciationsByWordID *loader[int, []*model.Pronunciation]
+	recordingsByWordID     *loader[int, []*model.Recording]
+	tagsByWordID           *loader[int, []*model.Tag]
+	collectionsByWordID    *loader[int, []*model.Collection]
+	languageByCode         *loader[string, languageResult]
+}
+
+// NewLoaders returns loaders reading from s, outside of any transaction.
+func NewLoaders(s store.DictionaryStore) *Loaders {
+	loaders := &Loaders{
+		wordByID: newLoader(func(ctx context.Context, ids []int) (map[int]*model.Word, error) {
+			found := make(map[int]*model.Word, len(ids))
+			err := s.Transaction(ctx, func(tx store.DictionaryStore) error {
+				words, err := tx.FindWordsByIDs(ctx, ids)
+				for _, word := range words {
+					found[word.ID] = word
+				}
+				return err
+			})
+			return found, err
+		}),
+		senseByID: newLoader(func(ctx context.Context, ids []int) (map[int]*model.Sense, error) {
+			found := make(map[int]*model.Sense, len(ids))
+			err := s.Transaction(ctx, func(tx store.DictionaryStore) error {
+				senses, err := tx.FindSensesByIDs(ctx, ids)
+				for _, sense := range senses {
+					found[sense.ID] = sense
+				}
+				return err
+			})
+			return found, err
+		}),
+		sensesByWordID: newLoader(func(ctx context.Context, ids []int) (map[int][]*model.Sense, error) {
+			var senses map[int][]*model.Sense
+			err := s.Transaction(ctx, func(tx store.DictionaryStore) error {
+				var err error
+				senses, err = tx.SensesOf(ctx, ids)
+				return err
+			})
+			return senses, err
+		}),
+		translationsBySenseID: newLoader(func(ctx context.Context, ids []int) (map[int][]model.Translation, error) {
+			bySense := make(map[int][]model.Translation, len(ids))
+			err := s.Transaction(ctx, func(tx store.DictionaryStore) error {
+				translations, err := tx.TranslationsOfSenses(ctx, ids)
+				for _, translation := range translations {
+					bySense[translation.SenseID] = append(bySense[translation.SenseID], translation)
+					bySense[translation.TranslationSenseID] = append(bySense[translation.TranslationSenseID], translation)
+				}
+				return err
+			})
+			return bySense, err
+		}),
+		exampleByID: newLoader(func(ctx context.Context, ids []int) (map[int]*model.Example, error) {
+			found := make(map[int]*model.Example, len(ids))
+			err := s.Transaction(ctx, func(tx store.DictionaryStore) error {
+				examples, err := tx.FindExamplesByIDs(ctx, ids)
+				for _, example := range examples {
+					found[example.ID] = example
+				}
+				return err
+			})
+			return found, err
+		}),
+		examplesByWordID: newLoader(func(ctx context.Context, ids []int) (map[int][]*model.Example, error) {
+			var examples map[int][]*model.Example
+			err := s.Transaction(ctx, func(tx store.DictionaryStore) error {
+				var err error
+				examples, err = tx.ExamplesOf(ctx, ids)
+				return err
+			})
+			return examples, err
+		}),
+		pronunciationsByWordID: newLoader(func(ctx context.Context, ids []int) (map[int][]*model.Pronunciation, error) {
+			var pronunciations map[int][]*model.Pronunciation
+			err := s.Transaction(ctx, func(tx store.DictionaryStore) error {
+				var err error
+				pronunciations, err = tx.PronunciationsOf(ctx, ids)
+				return err
+			})
+			return pronunciations, err
+		}),
+		recordingsByWordID: newLoader(func(ctx context.Context, ids []int) (map[int][]*model.Recording, error) {
+			var recordings map[int][]*model.Recording
+			err := s.Transaction(ctx, func(tx store.DictionaryStore) error {
+				var err error
+				recordings, err = tx.RecordingsOf(ctx, ids)
+				return err
+			})
+			return recordings, err
+		}),
+		tagsByWordID: newLoader(func(ctx context.Context, ids []int) (map[int][]*model.Tag, error) {
+			var tags map[int][]*model.Tag
+			err := s.Transaction(ctx, func(tx store.DictionaryStore) error {
+				var err error
+				tags, err = tx.TagsOf(ctx, ids)
+				return err
+			})
+			return tags, err
+		}),
+		collectionsByWordID: newLoader(func(ctx context.Context, ids []int) (map[int][]*model.Collection, error) {
+			var collections map[int][]*model.Collection
+			err := s.Transaction(ctx, func(tx store.DictionaryStore) error {
+				var err error
+				collections, err = tx.CollectionsOf(ctx, ids)
+				return err
+			})
+			return collections, err
+		}),
+		languageByCode: newLoader(func(ctx context.Context, codes []string) (map[string]languageResult, error) {
+			resolved := make(map[string]languageResult, len(codes))
+			err := s.Transaction(ctx, func(tx store.DictionaryStore) error {
+				for _, code := range codes {
+					language, err := store.ResolveLanguage(ctx, tx, code)
+					if err != nil && !errors.Is(err, store.ErrUnsupportedLanguage) {
+						return err
+					}
+					resolved[code] = languageResult{language: language, err: err}
+				}
+				return nil
+			})
+			return resolved, err
+		}),
+	}
+	// The fetch of translations expects the translations of what it fetched, so it is set once loaders exist.
+	loaders.translationsByWordID = newLoader(func(ctx context.Context, ids []int) (map[int][]*model.Word, error) {
+		var translated map[int][]*model.Word
+		err := s.Transaction(ctx, func(tx store.DictionaryStore) error {
+			var err error
+			translated, err = tx.TranslationsOfWords(ctx, ids)
+			return err
+		})
+		// Translations of the translations are what a nested translations field loads next.
+		for _, words := range translated {
+			loaders.expectTranslations(ctx, words)
+		}
+		return translated, err
+	})
+	return loaders
+}
+
+// expectTranslations expects loads of the translations of words, see loader.expect.
+func (loaders *Loaders) expectTranslations(ctx context.Context, words []*model.Word) {
+	ids := make([]int, len(words))
+	for i, word := range words {
+		ids[i] = word.ID
+	}
+	loaders.translationsByWordID.expect(ctx, ids)
+}
+
+type loadersKey struct{}
+
+// WithLoaders returns a context whose resolvers batch their lookups with loaders.
+func WithLoaders(ctx context.Context, loaders *Loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, loaders)
+}
+
+// loadersFor returns the loaders of ctx, nil outside of a response built by LoaderMiddleware.
+func loadersFor(ctx context.Context) *Loaders {
+	loaders, _ := ctx.Value(loadersKey{}).(*Loaders)
+	return loaders
+}
+
+// LoaderMiddleware gives every response its own loaders, so nothing is cached across requests
+// or across the events of a subscription.
+func LoaderMiddleware(s store.DictionaryStore) graphql.ResponseMiddleware {
+	return func(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+		return next(WithLoaders(ctx, NewLoaders(s)))
+	}
+}
+
+// expectTranslations expects loads of the translations of words returned by a list field.
+func expectTranslations(ctx context.Context, words []*model.Word) {
+	if loaders := loadersFor(ctx); loaders != nil {
+		loaders.expectTranslations(ctx, words)
+	}
+}
+
+// loadWord returns the word with given database ID, or nil when there is none.
+func loadWord(ctx context.Context, s store.DictionaryStore, id int) (*model.Word, error) {
+	if loaders := loadersFor(ctx); loaders != nil {
+		return loaders.wordByID.load(ctx, id)
+	}
+	var word *model.Word
+	err := s.Transaction(ctx, func(tx store.DictionaryStore) error {
+		var err error
+		word, err = wordByID(ctx, tx, id)
+		return err
+	})
+	return word, err
+}
+
+// loadWords returns the words with given database IDs in the order of ids, leaving out missing ones.
+func loadWords(ctx context.Context, s store.DictionaryStore, ids []int) ([]*model.Word, error) {
+	loaders := loadersFor(ctx)
+	if loaders == nil {
+		var words []*model.Word
+		err := s.Transaction(ctx, func(tx store.DictionaryStore) error {
+			var err error
+			words, err = tx.FindWordsByIDs(ctx, ids)
+			return err
+		})
+		return words, err
+	}
+	loaders.wordByID.expect(ctx, ids)
+	words := make([]*model.Word, 0, len(ids))
+	for _, id := range ids {
+		word, err := loaders.wordByID.load(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if word != nil {
+			words = append(words, word)
+		}
+	}
+	return words, nil
+}
+
+// loadTranslations returns the translations of the word with given ID, ordered by ID.
+func loadTranslations(ctx context.Context, s store.DictionaryStore, id int) ([]*model.Word, error) {
+	if loaders := loadersFor(ctx); loaders != nil {
+		return loaders.translationsByWordID.load(ctx, id)
+	}
+	var translations []*model.Word
+	err := s.Transaction(ctx, func(tx store.DictionaryStore) error {
+		var err error
+		translations, err = tx.TranslationsOf(ctx, id)
+		return err
+	})
+	return translations, err
+}
+
+// loadLanguage resolves a language given in a query like store.ResolveLanguage does.
+func loadLanguage(ctx context.Context, s store.DictionaryStore, code string) (*model.Language, error) {
+	if loaders := loadersFor(ctx); loaders != nil {
+		result, err := loaders.languageByCode.load(ctx, code)
+		if err != nil {
+			return nil, err
+		}
+		return result.language, result.err
+	}
+	var language *model.Language
+	err := s.Transaction(ctx, func(tx store.DictionaryStore) error {
+		var err error
+		language, err = store.ResolveLanguage(ctx, tx, code)
+		return err
+	})
+	return language, err
+}
+
+// loadSense returns the sense with given database ID, or nil when there is none.
+func loadSense(ctx context.Context, s store.DictionaryStore, id int) (*model.Sense, error) {
+	senses, err := loadSensesByIDs(ctx, s, []int{id})
+	if err != nil || len(senses) == 0 {
+		return nil, err
+	}
+	return senses[0], nil
+}
+
+// loadSensesByIDs returns the senses with given database IDs in the order of ids, leaving out missing ones.
+func loadSensesByIDs(ctx context.Context, s store.DictionaryStore, ids []int) ([]*model.Sense, error) {
+	loaders := loadersFor(ctx)
+	if loaders == nil {
+		var senses []*model.Sense
+		err := s.Transaction(ctx, func(tx store.DictionaryStore) error {
+			var err error
+			senses, err = tx.FindSensesByIDs(ctx, ids)
+			return err
+		})
+		return senses, err
+	}
+	loaders.senseByID.expect(ctx, ids)
+	senses := make([]*model.Sense, 0, len(ids))
+	for _, id := range ids {
+		sense, err := loaders.senseByID.load(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if sense != nil {
+			senses = append(senses, sense)
+		}
+	}
+	return senses, nil
+}
+
+// loadSenses returns the senses of the word with given ID, ordered by ID.
+func loadSenses(ctx context.Context, s store.DictionaryStore, wordID int) ([]*model.Sense, error) {
+	if loaders := loadersFor(ctx); loaders != nil {
+		return loaders.sensesByWordID.load(ctx, wordID)
+	}
+	var senses []*model.Sense
+	err := s.Transaction(ctx, func(tx store.DictionaryStore) error {
+		bySense, err := tx.SensesOf(ctx, []int{wordID})
+		senses = bySense[wordID]
+		return err
+	})
+	return senses, err
+}
+
+// loadSenseTranslations returns the translations linking any of the senses with given IDs.
+func loadSenseTranslations(ctx context.Context, s store.DictionaryStore, senseIDs []int) ([]model.Translation, error) {
+	loaders := loadersFor(ctx)
+	if loaders == nil {
+		var translations []model.Translation
+		err := s.Transaction(ctx, func(tx store.DictionaryStore) error {
+			var err error
+			translations, err = tx.TranslationsOfSenses(ctx, senseIDs)
+			return err
+		})
+		return translations, err
+	}
+	loaders.translationsBySenseID.expect(ctx, senseIDs)
+	seen := make(map[model.Translation]bool)
+	var translations []model.Translation
+	for _, id := range senseIDs {
+		linked, err := loaders.translationsBySenseID.load(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		for _, translation := range linked {
+			if !seen[translation] {
+				seen[translation] = true
+				translations = append(translations, translation)
+			}
+		}
+	}
+	return translations, nil
+}
+
+// loadExample returns the example with given database ID, or nil when there is none.
+func loadExample(ctx context.Context, s store.DictionaryStore, id int) (*model.Example, error) {
+	if loaders := loadersFor(ctx); loaders != nil {
+		return loaders.exampleByID.load(ctx, id)
+	}
+	var example *model.Example
+	err := s.Transaction(ctx, func(tx store.DictionaryStore) error {
+		examples, err := tx.FindExamplesByIDs(ctx, []int{id})
+		if len(examples) > 0 {
+			example = examples[0]
+		}
+		return err
+	})
+	return example, err
+}
+
+// loadExamples returns the examples of the word with given ID, ordered by ID.
+func loadExamples(ctx context.Context, s store.DictionaryStore, wordID int) ([]*model.Example, error) {
+	if loaders := loadersFor(ctx); loaders != nil {
+		return loaders.examplesByWordID.load(ctx, wordID)
+	}
+	var examples []*model.Example
+	err := s.Transaction(ctx, func(tx store.DictionaryStore) error {
+		byWord, err := tx.ExamplesOf(ctx, []int{wordID})
+		examples = byWord[wordID]
+		return err
+	})
+	return examples, err
+}
+
+// loadPronunciations returns the pronunciations of the word with given ID, ordered by ID.
+func loadPronunciations(ctx context.Context, s store.DictionaryStore, wordID int) ([]*model.Pronunciation, error) {
+	if loaders := loadersFor(ctx); loaders != nil {
+		return loaders.pronunciationsByWordID.load(ctx, wordID)
+	}
+	var pronunciations []*model.Pronunciation
+	err := s.Transaction(ctx, func(tx store.DictionaryStore) error {
+		byWord, err := tx.PronunciationsOf(ctx, []int{wordID})
+		pronunciations = byWord[wordID]
+		return err
+	})
+	return pronunciations, err
+}
+
+// loadRecordings returns the recordings of the word with given ID, ordered by ID.
+func loadRecordings(ctx context.Context, s store.DictionaryStore, wordID int) ([]*model.Recording, error) {
+	if loaders := loadersFor(ctx); loaders != nil {
+		return loaders.recordingsByWordID.load(ctx, wordID)
+	}
+	var recordings []*model.Recording
+	err := s.Transaction(ctx, func(tx store.DictionaryStore) error {
+		byWord, err := tx.RecordingsOf(ctx, []int{wordID})
+		recordings = byWord[wordID]
+		return err
+	})
+	return recordings, err
+}
+
+// loadTags returns the tags of the word with given ID, ordered by name.
+func loadTags(ctx context.Context, s store.DictionaryStore, wordID int) ([]*model.Tag, error) {
+	if loaders := loadersFor(ctx); loaders != nil {
+		return loaders.tagsByWordID.load(ctx, wordID)
+	}
+	var tags []*model.Tag
+	err := s.Transaction(ctx, func(tx store.DictionaryStore) error {
+		byWord, err := tx.TagsOf(ctx, []int{wordID})
+		tags = byWord[wordID]
+		return err
+	})
+	return tags, err
+}
+
+// loadCollections returns the collections the word with given ID is in, ordered by name.
+func loadCollections(ctx context.Context, s store.DictionaryStore, wordID int) ([]*model.Collection, error) {
+	if loaders := loadersFor(ctx); loaders != nil {
+		return loaders.collectionsByWordID.load(ctx, wordID)
+	}
+	var collections []*model.Collection
+	err := s.Transaction(ctx, func(tx store.DictionaryStore) error {
+		byWord, err := tx.CollectionsOf(ctx, []int{wordID})
+		collections = byWord[wordID]
+		return err
+	})
+	return collections, err
+}
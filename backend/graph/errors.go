@@ -0,0 +1,66 @@
+package graph
+
+import (
+	"backend/graph/model"
+	"backend/store"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// codedError is an error whose code is known where it is raised, like invalid arguments.
+type codedError struct {
+	code    model.ErrorCode
+	message string
+}
+
+func (e *codedError) Error() string {
+	return e.message
+}
+
+// invalidInput builds the error of arguments failing validation.
+func invalidInput(format string, args ...interface{}) error {
+	return &codedError{code: model.ErrorCodeValidation, message: fmt.Sprintf(format, args...)}
+}
+
+// conflict builds the error of a change which would duplicate a unique record.
+func conflict(format string, args ...interface{}) error {
+	return &codedError{code: model.ErrorCodeConflict, message: fmt.Sprintf(format, args...)}
+}
+
+// errorCode classifies err for clients, errors which are not expected from valid requests are INTERNAL.
+func errorCode(err error) model.ErrorCode {
+	var coded *codedError
+	switch {
+	case errors.As(err, &coded):
+		return coded.code
+	case errors.Is(err, store.ErrTimeout) || errors.Is(err, context.DeadlineExceeded):
+		return model.ErrorCodeTimeout
+	case errors.Is(err, store.ErrNotFound):
+		return model.ErrorCodeNotFound
+	case errors.Is(err, store.ErrConflict):
+		return model.ErrorCodeConflict
+	case errors.Is(err, store.ErrUnsupportedLanguage):
+		return model.ErrorCodeValidation
+	default:
+		return model.ErrorCodeInternal
+	}
+}
+
+// ErrorPresenter adds the code of the error to its "code" extension, see ErrorCode in the schema.
+// Errors raised by gqlgen itself, like GRAPHQL_VALIDATION_FAILED, keep their code. Requests cancelled
+// by the client get no code, nobody reads their response.
+func ErrorPresenter(ctx context.Context, err error) *gqlerror.Error {
+	presented := graphql.DefaultErrorPresenter(ctx, err)
+	if _, ok := presented.Extensions["code"]; ok || errors.Is(err, context.Canceled) {
+		return presented
+	}
+	if presented.Extensions == nil {
+		presented.Extensions = map[string]interface{}{}
+	}
+	presented.Extensions["code"] = errorCode(err).String()
+	return presented
+}
@@ -0,0 +1,121 @@
+package graph
+
+import (
+	"backend/graph/model"
+	"backend/store"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+)
+
+// exampleChange holds the fields of an example given in a mutation, fields left out keep their value.
+// A sense or translation given as null is removed from the example.
+type exampleChange struct {
+	text          *string
+	language      *string
+	source        *string
+	senseID       graphql.Omittable[*string]
+	translationID graphql.Omittable[*string]
+}
+
+// applyExampleChange validates the change and sets it on the example, whose word must be set.
+// The sense has to be one of the word, the translation an example in another language.
+func applyExampleChange(ctx context.Context, s store.DictionaryStore, example *model.Example, change exampleChange) error {
+	if change.text != nil {
+		text := strings.TrimSpace(*change.text)
+		if text == "" {
+			return invalidInput("example must not be empty")
+		}
+		example.Text = text
+	}
+	if change.source != nil {
+		example.Source = strings.TrimSpace(*change.source)
+	}
+	if change.language != nil {
+		language, err := store.ResolveLanguage(ctx, s, *change.language)
+		if err != nil {
+			return err
+		}
+		example.Language = language.Code
+	}
+
+	if id, ok := change.senseID.ValueOK(); ok {
+		example.SenseID = nil
+		if id != nil {
+			sense, err := findSense(ctx, s, *id)
+			if err != nil {
+				return err
+			}
+			if sense == nil {
+				return fmt.Errorf("sense %s is missing in database: %w", *id, store.ErrNotFound)
+			}
+			if sense.WordID != example.WordID {
+				return invalidInput("sense %s is not a sense of the word of the example", *id)
+			}
+			example.SenseID = &sense.ID
+		}
+	}
+
+	if id, ok := change.translationID.ValueOK(); ok {
+		example.TranslatedExampleID = nil
+		if id != nil {
+			translatedID, err := decodeID(*id, exampleTypename)
+			if err != nil {
+				return err
+			}
+			if translatedID == example.ID {
+				return invalidInput("an example cannot be a translation of itself")
+			}
+			example.TranslatedExampleID = &translatedID
+		}
+	}
+	if example.TranslatedExampleID == nil {
+		return nil
+	}
+	translated, err := findExampleByID(ctx, s, *example.TranslatedExampleID)
+	if err != nil {
+		return err
+	}
+	if translated == nil {
+		return fmt.Errorf("example %s is missing in database: %w", globalID(exampleTypename, *example.TranslatedExampleID), store.ErrNotFound)
+	}
+	if translated.Language == example.Language {
+		return invalidInput("an example in %q cannot be a translation of an example in the same language", example.Language)
+	}
+	return nil
+}
+
+// findExample returns the example a global ID refers to, or nil when it does not exist.
+func findExample(ctx context.Context, s store.DictionaryStore, id string) (*model.Example, error) {
+	exampleID, err := decodeID(id, exampleTypename)
+	if err != nil {
+		return nil, err
+	}
+	return findExampleByID(ctx, s, exampleID)
+}
+
+// findExampleByID returns the example with given database ID, or nil when there is none.
+func findExampleByID(ctx context.Context, s store.DictionaryStore, id int) (*model.Example, error) {
+	examples, err := s.FindExamplesByIDs(ctx, []int{id})
+	if err != nil || len(examples) == 0 {
+		return nil, err
+	}
+	return examples[0], nil
+}
+
+// examplesOfSense returns the examples of the word of the sense given in that sense, ordered by ID.
+func examplesOfSense(ctx context.Context, s store.DictionaryStore, sense *model.Sense) ([]*model.Example, error) {
+	examples, err := loadExamples(ctx, s, sense.WordID)
+	if err != nil {
+		return nil, err
+	}
+	inSense := make([]*model.Example, 0, len(examples))
+	for _, example := range examples {
+		if example.SenseID != nil && *example.SenseID == sense.ID {
+			inSense = append(inSense, example)
+		}
+	}
+	return inSense, nil
+}
@@ -0,0 +1,13 @@
+package model
+
+// Example is a sentence showing a word in use, in one of its senses when SenseID is set. Examples in different
+// languages are linked as translations of each other, each of the two links the other.
+type Example struct {
+	ID                  int    `json:"id" gorm:"primaryKey;autoIncrement"`
+	WordID              int    `json:"wordID" gorm:"not null;index:idx_examples_word"`
+	SenseID             *int   `json:"senseID"`
+	Text                string `json:"text" gorm:"not null"`
+	Language            string `json:"language" gorm:"not null"`
+	Source              string `json:"source" gorm:"not null;default:''"`
+	TranslatedExampleID *int   `json:"translatedExampleID"`
+}
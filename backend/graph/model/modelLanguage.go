@@ -0,0 +1,55 @@
+package model
+
+import (
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+type Language struct {
+	Code          string        `json:"code" gorm:"primaryKey"`
+	Name          string        `json:"name" gorm:"not null"`
+	NativeName    string        `json:"nativeName"`
+	Script        string        `json:"script"`
+	Direction     TextDirection `json:"direction" gorm:"not null;default:LTR"`
+	CaseSensitive bool          `json:"caseSensitive" gorm:"not null;default:false"`
+}
+
+// rtlScripts lists ISO 15924 scripts written from right to left.
+var rtlScripts = map[string]bool{
+	"Adlm": true, "Arab": true, "Hebr": true, "Mand": true, "Nkoo": true, "Rohg": true, "Samr": true, "Syrc": true, "Thaa": true,
+}
+
+// CanonicalLanguageCode returns the canonical BCP 47 form of code, e.g. "en" for "EN" or "eng" and "en-GB" for "en_gb".
+func CanonicalLanguageCode(code string) (string, bool) {
+	tag, err := language.Parse(strings.TrimSpace(code))
+	if err != nil || tag == language.Und {
+		return "", false
+	}
+	return tag.String(), true
+}
+
+// CanonicalScript returns the canonical ISO 15924 form of script, e.g. "Latn" for "latn".
+func CanonicalScript(script string) (string, bool) {
+	parsed, err := language.ParseScript(strings.TrimSpace(script))
+	if err != nil {
+		return "", false
+	}
+	return parsed.String(), true
+}
+
+// DefaultScript returns the script most likely used to write the language with given canonical code.
+func DefaultScript(code string) string {
+	script, confidence := language.Make(code).Script()
+	if confidence == language.No {
+		return ""
+	}
+	return script.String()
+}
+
+func ScriptDirection(script string) TextDirection {
+	if rtlScripts[script] {
+		return TextDirectionRtl
+	}
+	return TextDirectionLtr
+}
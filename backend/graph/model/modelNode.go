@@ -0,0 +1,20 @@
+package model
+
+// Node is an object the node query fetches by its global ID.
+type Node interface {
+	IsNode()
+}
+
+func (Word) IsNode() {}
+
+func (Sense) IsNode() {}
+
+func (Example) IsNode() {}
+
+func (Pronunciation) IsNode() {}
+
+func (Recording) IsNode() {}
+
+func (Tag) IsNode() {}
+
+func (Collection) IsNode() {}
@@ -0,0 +1,21 @@
+package model
+
+// Pronunciation is an IPA transcription of a word, in the accent or region named by Accent when it is set.
+// A word has any number of them, but each transcription once per accent.
+type Pronunciation struct {
+	ID     int    `json:"id" gorm:"primaryKey;autoIncrement"`
+	WordID int    `json:"wordID" gorm:"not null;uniqueIndex:idx_pronunciations_word_transcription"`
+	IPA    string `json:"ipa" gorm:"column:ipa;not null;uniqueIndex:idx_pronunciations_word_transcription"`
+	Accent string `json:"accent" gorm:"not null;default:'';uniqueIndex:idx_pronunciations_word_transcription"`
+}
+
+// Recording is audio of a word spoken in the accent named by Accent when it is set. The audio itself is kept
+// in a blob store under BlobKey, the database only describes it.
+type Recording struct {
+	ID          int    `json:"id" gorm:"primaryKey;autoIncrement"`
+	WordID      int    `json:"wordID" gorm:"not null;index:idx_recordings_word"`
+	Accent      string `json:"accent" gorm:"not null;default:''"`
+	ContentType string `json:"contentType" gorm:"not null"`
+	Size        int64  `json:"size" gorm:"not null"`
+	BlobKey     string `json:"-" gorm:"not null;uniqueIndex:idx_recordings_blob_key"`
+}
@@ -0,0 +1,16 @@
+package model
+
+// Sense is a meaning of a word, translations link senses rather than words. Every word has a default sense
+// without part of speech and gloss, linked by translations added between words.
+type Sense struct {
+	ID           int          `json:"id" gorm:"primaryKey;autoIncrement"`
+	WordID       int          `json:"wordID" gorm:"not null;uniqueIndex:idx_senses_word_meaning"`
+	PartOfSpeech PartOfSpeech `json:"partOfSpeech" gorm:"not null;default:'';uniqueIndex:idx_senses_word_meaning"`
+	Gloss        string       `json:"gloss" gorm:"not null;default:'';uniqueIndex:idx_senses_word_meaning"`
+	Register     string       `json:"register" gorm:"not null;default:''"`
+}
+
+// IsDefault reports whether the sense is the default sense of its word.
+func (sense *Sense) IsDefault() bool {
+	return sense.PartOfSpeech == "" && sense.Gloss == ""
+}
@@ -0,0 +1,27 @@
+package model
+
+// Tag labels words, e.g. "travel" or "medical". Names are lowercase and unique, a word has any number of tags.
+type Tag struct {
+	ID   int    `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name string `json:"name" gorm:"not null;uniqueIndex:idx_tags_name"`
+}
+
+// WordTag links a word to one of its tags.
+type WordTag struct {
+	WordID int `gorm:"primaryKey"`
+	TagID  int `gorm:"primaryKey"`
+}
+
+// Collection is a named list of words put together by users, e.g. the words of a lesson.
+// Names are unique, a word is in any number of collections.
+type Collection struct {
+	ID          int    `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name        string `json:"name" gorm:"not null;uniqueIndex:idx_collections_name"`
+	Description string `json:"description" gorm:"not null;default:''"`
+}
+
+// CollectionWord links a collection to one of its words.
+type CollectionWord struct {
+	CollectionID int `gorm:"primaryKey"`
+	WordID       int `gorm:"primaryKey"`
+}
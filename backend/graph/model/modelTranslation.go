@@ -1,12 +1,17 @@
 package model
 
+// Translation links a sense of a word to a sense of another word. Translations between words, without sense IDs,
+// link the default senses of the words.
 type Translation struct {
-	WordID        int `json:"wordID" gorm:"column:word_id;primaryKey"`
-	TranslationID int `json:"translationID" gorm:"column:translation_id;primaryKey"`
+	WordID             int `json:"wordID" gorm:"column:word_id"`
+	TranslationID      int `json:"translationID" gorm:"column:translation_id"`
+	SenseID            int `json:"senseID,omitempty" gorm:"column:sense_id;primaryKey"`
+	TranslationSenseID int `json:"translationSenseID,omitempty" gorm:"column:translation_sense_id;primaryKey"`
 }
 
 func (translation *Translation) SortTranslation() {
 	if translation.WordID > translation.TranslationID {
 		translation.WordID, translation.TranslationID = translation.TranslationID, translation.WordID
+		translation.SenseID, translation.TranslationSenseID = translation.TranslationSenseID, translation.SenseID
 	}
 }
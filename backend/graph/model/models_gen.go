@@ -2,8 +2,465 @@
 
 package model
 
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/99designs/gqlgen/graphql"
+)
+
+type AddTranslationsPayload struct {
+	Committed bool                 `json:"committed"`
+	Results   []*TranslationResult `json:"results"`
+}
+
+type AddWordsPayload struct {
+	Committed bool          `json:"committed"`
+	Results   []*WordResult `json:"results"`
+}
+
+type BatchItemError struct {
+	Code    ErrorCode `json:"code"`
+	Message string    `json:"message"`
+}
+
+type CollectionInput struct {
+	Name        string  `json:"name"`
+	Description *string `json:"description,omitempty"`
+}
+
+type DeleteTranslationResult struct {
+	Index       int32           `json:"index"`
+	Translation *Translation    `json:"translation,omitempty"`
+	Deleted     bool            `json:"deleted"`
+	Error       *BatchItemError `json:"error,omitempty"`
+}
+
+type DeleteTranslationsPayload struct {
+	Committed bool                       `json:"committed"`
+	Results   []*DeleteTranslationResult `json:"results"`
+}
+
+type ExampleInput struct {
+	Text          string  `json:"text"`
+	Language      *string `json:"language,omitempty"`
+	Source        *string `json:"source,omitempty"`
+	SenseID       *string `json:"senseId,omitempty"`
+	TranslationID *string `json:"translationId,omitempty"`
+}
+
+type ImportReport struct {
+	DryRun     bool              `json:"dryRun"`
+	Created    int32             `json:"created"`
+	Duplicates int32             `json:"duplicates"`
+	Invalid    int32             `json:"invalid"`
+	Issues     []*ImportRowIssue `json:"issues"`
+}
+
+type ImportRowIssue struct {
+	Line    int32           `json:"line"`
+	Status  ImportRowStatus `json:"status"`
+	Message string          `json:"message"`
+}
+
 type Mutation struct {
 }
 
+type PageInfo struct {
+	HasNextPage     bool    `json:"hasNextPage"`
+	HasPreviousPage bool    `json:"hasPreviousPage"`
+	StartCursor     *string `json:"startCursor,omitempty"`
+	EndCursor       *string `json:"endCursor,omitempty"`
+}
+
+type PronunciationInput struct {
+	IPA    string  `json:"ipa"`
+	Accent *string `json:"accent,omitempty"`
+}
+
 type Query struct {
 }
+
+type SenseInput struct {
+	PartOfSpeech *PartOfSpeech `json:"partOfSpeech,omitempty"`
+	Gloss        *string       `json:"gloss,omitempty"`
+	Register     *string       `json:"register,omitempty"`
+}
+
+type Subscription struct {
+}
+
+type TranslationChange struct {
+	Kind        ChangeKind   `json:"kind"`
+	Translation *Translation `json:"translation"`
+}
+
+type TranslationInput struct {
+	SourceText             string `json:"sourceText"`
+	SourceTextLanguage     string `json:"sourceTextLanguage"`
+	TranslatedText         string `json:"translatedText"`
+	TranslatedTextLanguage string `json:"translatedTextLanguage"`
+}
+
+type TranslationPath struct {
+	Word *Word   `json:"word"`
+	Path []*Word `json:"path"`
+	Hops int32   `json:"hops"`
+}
+
+type TranslationResult struct {
+	Index       int32           `json:"index"`
+	Translation *Translation    `json:"translation,omitempty"`
+	Created     bool            `json:"created"`
+	Error       *BatchItemError `json:"error,omitempty"`
+}
+
+type UpdateCollectionInput struct {
+	Name        *string `json:"name,omitempty"`
+	Description *string `json:"description,omitempty"`
+}
+
+type UpdateExampleInput struct {
+	Text          *string                    `json:"text,omitempty"`
+	Language      *string                    `json:"language,omitempty"`
+	Source        *string                    `json:"source,omitempty"`
+	SenseID       graphql.Omittable[*string] `json:"senseId,omitempty"`
+	TranslationID graphql.Omittable[*string] `json:"translationId,omitempty"`
+}
+
+type UpdatePronunciationInput struct {
+	IPA    *string `json:"ipa,omitempty"`
+	Accent *string `json:"accent,omitempty"`
+}
+
+type UpdateWordInput struct {
+	Text         *string `json:"text,omitempty"`
+	Language     *string `json:"language,omitempty"`
+	ExampleUsage *string `json:"exampleUsage,omitempty"`
+}
+
+type WordChange struct {
+	Kind ChangeKind `json:"kind"`
+	Word *Word      `json:"word"`
+}
+
+type WordConnection struct {
+	Edges      []*WordEdge `json:"edges"`
+	PageInfo   *PageInfo   `json:"pageInfo"`
+	TotalCount int32       `json:"totalCount"`
+}
+
+type WordEdge struct {
+	Cursor string `json:"cursor"`
+	Node   *Word  `json:"node"`
+}
+
+type WordFilter struct {
+	Language     *string       `json:"language,omitempty"`
+	TextPrefix   *string       `json:"textPrefix,omitempty"`
+	PartOfSpeech *PartOfSpeech `json:"partOfSpeech,omitempty"`
+	Tags         []string      `json:"tags,omitempty"`
+	Collection   *string       `json:"collection,omitempty"`
+}
+
+type WordInput struct {
+	Text         string  `json:"text"`
+	Language     string  `json:"language"`
+	ExampleUsage *string `json:"exampleUsage,omitempty"`
+}
+
+type WordKeyInput struct {
+	Text     string `json:"text"`
+	Language string `json:"language"`
+}
+
+type WordResult struct {
+	Index   int32           `json:"index"`
+	Word    *Word           `json:"word,omitempty"`
+	Created bool            `json:"created"`
+	Error   *BatchItemError `json:"error,omitempty"`
+}
+
+type WordSuggestion struct {
+	Word     *Word `json:"word"`
+	Distance int32 `json:"distance"`
+}
+
+type ChangeKind string
+
+const (
+	ChangeKindCreated ChangeKind = "CREATED"
+	ChangeKindUpdated ChangeKind = "UPDATED"
+	ChangeKindDeleted ChangeKind = "DELETED"
+)
+
+var AllChangeKind = []ChangeKind{
+	ChangeKindCreated,
+	ChangeKindUpdated,
+	ChangeKindDeleted,
+}
+
+func (e ChangeKind) IsValid() bool {
+	switch e {
+	case ChangeKindCreated, ChangeKindUpdated, ChangeKindDeleted:
+		return true
+	}
+	return false
+}
+
+func (e ChangeKind) String() string {
+	return string(e)
+}
+
+func (e *ChangeKind) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ChangeKind(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ChangeKind", str)
+	}
+	return nil
+}
+
+func (e ChangeKind) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type ErrorCode string
+
+const (
+	ErrorCodeNotFound   ErrorCode = "NOT_FOUND"
+	ErrorCodeValidation ErrorCode = "VALIDATION"
+	ErrorCodeConflict   ErrorCode = "CONFLICT"
+	ErrorCodeTimeout    ErrorCode = "TIMEOUT"
+	ErrorCodeInternal   ErrorCode = "INTERNAL"
+)
+
+var AllErrorCode = []ErrorCode{
+	ErrorCodeNotFound,
+	ErrorCodeValidation,
+	ErrorCodeConflict,
+	ErrorCodeTimeout,
+	ErrorCodeInternal,
+}
+
+func (e ErrorCode) IsValid() bool {
+	switch e {
+	case ErrorCodeNotFound, ErrorCodeValidation, ErrorCodeConflict, ErrorCodeTimeout, ErrorCodeInternal:
+		return true
+	}
+	return false
+}
+
+func (e ErrorCode) String() string {
+	return string(e)
+}
+
+func (e *ErrorCode) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ErrorCode(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ErrorCode", str)
+	}
+	return nil
+}
+
+func (e ErrorCode) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type ImportRowStatus string
+
+const (
+	ImportRowStatusDuplicate ImportRowStatus = "DUPLICATE"
+	ImportRowStatusInvalid   ImportRowStatus = "INVALID"
+)
+
+var AllImportRowStatus = []ImportRowStatus{
+	ImportRowStatusDuplicate,
+	ImportRowStatusInvalid,
+}
+
+func (e ImportRowStatus) IsValid() bool {
+	switch e {
+	case ImportRowStatusDuplicate, ImportRowStatusInvalid:
+		return true
+	}
+	return false
+}
+
+func (e ImportRowStatus) String() string {
+	return string(e)
+}
+
+func (e *ImportRowStatus) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ImportRowStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ImportRowStatus", str)
+	}
+	return nil
+}
+
+func (e ImportRowStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type PartOfSpeech string
+
+const (
+	PartOfSpeechNoun         PartOfSpeech = "NOUN"
+	PartOfSpeechVerb         PartOfSpeech = "VERB"
+	PartOfSpeechAdjective    PartOfSpeech = "ADJECTIVE"
+	PartOfSpeechAdverb       PartOfSpeech = "ADVERB"
+	PartOfSpeechPronoun      PartOfSpeech = "PRONOUN"
+	PartOfSpeechPreposition  PartOfSpeech = "PREPOSITION"
+	PartOfSpeechConjunction  PartOfSpeech = "CONJUNCTION"
+	PartOfSpeechInterjection PartOfSpeech = "INTERJECTION"
+	PartOfSpeechDeterminer   PartOfSpeech = "DETERMINER"
+	PartOfSpeechNumeral      PartOfSpeech = "NUMERAL"
+	PartOfSpeechParticle     PartOfSpeech = "PARTICLE"
+	PartOfSpeechPhrase       PartOfSpeech = "PHRASE"
+)
+
+var AllPartOfSpeech = []PartOfSpeech{
+	PartOfSpeechNoun,
+	PartOfSpeechVerb,
+	PartOfSpeechAdjective,
+	PartOfSpeechAdverb,
+	PartOfSpeechPronoun,
+	PartOfSpeechPreposition,
+	PartOfSpeechConjunction,
+	PartOfSpeechInterjection,
+	PartOfSpeechDeterminer,
+	PartOfSpeechNumeral,
+	PartOfSpeechParticle,
+	PartOfSpeechPhrase,
+}
+
+func (e PartOfSpeech) IsValid() bool {
+	switch e {
+	case PartOfSpeechNoun, PartOfSpeechVerb, PartOfSpeechAdjective, PartOfSpeechAdverb, PartOfSpeechPronoun, PartOfSpeechPreposition, PartOfSpeechConjunction, PartOfSpeechInterjection, PartOfSpeechDeterminer, PartOfSpeechNumeral, PartOfSpeechParticle, PartOfSpeechPhrase:
+		return true
+	}
+	return false
+}
+
+func (e PartOfSpeech) String() string {
+	return string(e)
+}
+
+func (e *PartOfSpeech) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = PartOfSpeech(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid PartOfSpeech", str)
+	}
+	return nil
+}
+
+func (e PartOfSpeech) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type TextDirection string
+
+const (
+	TextDirectionLtr TextDirection = "LTR"
+	TextDirectionRtl TextDirection = "RTL"
+)
+
+var AllTextDirection = []TextDirection{
+	TextDirectionLtr,
+	TextDirectionRtl,
+}
+
+func (e TextDirection) IsValid() bool {
+	switch e {
+	case TextDirectionLtr, TextDirectionRtl:
+		return true
+	}
+	return false
+}
+
+func (e TextDirection) String() string {
+	return string(e)
+}
+
+func (e *TextDirection) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = TextDirection(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid TextDirection", str)
+	}
+	return nil
+}
+
+func (e TextDirection) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type TranslationFileFormat string
+
+const (
+	TranslationFileFormatCSV TranslationFileFormat = "CSV"
+	TranslationFileFormatTsv TranslationFileFormat = "TSV"
+	TranslationFileFormatTmx TranslationFileFormat = "TMX"
+	TranslationFileFormatTbx TranslationFileFormat = "TBX"
+)
+
+var AllTranslationFileFormat = []TranslationFileFormat{
+	TranslationFileFormatCSV,
+	TranslationFileFormatTsv,
+	TranslationFileFormatTmx,
+	TranslationFileFormatTbx,
+}
+
+func (e TranslationFileFormat) IsValid() bool {
+	switch e {
+	case TranslationFileFormatCSV, TranslationFileFormatTsv, TranslationFileFormatTmx, TranslationFileFormatTbx:
+		return true
+	}
+	return false
+}
+
+func (e TranslationFileFormat) String() string {
+	return string(e)
+}
+
+func (e *TranslationFileFormat) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = TranslationFileFormat(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid TranslationFileFormat", str)
+	}
+	return nil
+}
+
+func (e TranslationFileFormat) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
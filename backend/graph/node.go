@@ -0,0 +1,210 @@
+package graph
+
+import (
+	"backend/graph/model"
+	"backend/store"
+	"context"
+	"encoding/base64"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Type names of the objects implementing Node, which prefix their global IDs.
+const (
+	wordTypename          = "Word"
+	senseTypename         = "Sense"
+	exampleTypename       = "Example"
+	pronunciationTypename = "Pronunciation"
+	recordingTypename     = "Recording"
+	tagTypename           = "Tag"
+	collectionTypename    = "Collection"
+)
+
+// globalID encodes the ID of an object of given type as the opaque ID exposed by the schema.
+func globalID(typename string, id int) string {
+	return base64.StdEncoding.EncodeToString([]byte(typename + ":" + strconv.Itoa(id)))
+}
+
+// decodeGlobalID returns the type name and the ID of the object a global ID refers to.
+func decodeGlobalID(id string) (string, int, error) {
+	raw, err := base64.StdEncoding.DecodeString(id)
+	if err != nil {
+		return "", 0, invalidInput("invalid ID %q", id)
+	}
+	typename, value, ok := strings.Cut(string(raw), ":")
+	if !ok || typename == "" {
+		return "", 0, invalidInput("invalid ID %q", id)
+	}
+	databaseID, err := strconv.Atoi(value)
+	if err != nil || databaseID <= 0 {
+		return "", 0, invalidInput("invalid ID %q", id)
+	}
+	return typename, databaseID, nil
+}
+
+// decodeID returns the ID of the object of given type a global ID refers to, IDs of other types are invalid.
+func decodeID(id, typename string) (int, error) {
+	idTypename, databaseID, err := decodeGlobalID(id)
+	if err != nil {
+		return 0, err
+	}
+	if idTypename != typename {
+		noun := strings.ToLower(typename)
+		article := "a"
+		if strings.ContainsRune("aeiou", rune(noun[0])) {
+			article = "an"
+		}
+		return 0, invalidInput("ID %q does not refer to %s %s", id, article, noun)
+	}
+	return databaseID, nil
+}
+
+// wordByID returns the word with given database ID, or nil when there is none.
+func wordByID(ctx context.Context, s store.DictionaryStore, id int) (*model.Word, error) {
+	words, err := s.FindWordsByIDs(ctx, []int{id})
+	if err != nil || len(words) == 0 {
+		return nil, err
+	}
+	return words[0], nil
+}
+
+// node returns the object a global ID refers to, or nil when it does not exist.
+func node(ctx context.Context, s store.DictionaryStore, id string) (model.Node, error) {
+	typename, databaseID, err := decodeGlobalID(id)
+	if err != nil {
+		return nil, err
+	}
+	switch typename {
+	case wordTypename:
+		word, err := loadWord(ctx, s, databaseID)
+		if word == nil {
+			return nil, err
+		}
+		return word, nil
+	case senseTypename:
+		sense, err := loadSense(ctx, s, databaseID)
+		if sense == nil {
+			return nil, err
+		}
+		return sense, nil
+	case exampleTypename:
+		example, err := loadExample(ctx, s, databaseID)
+		if example == nil {
+			return nil, err
+		}
+		return example, nil
+	case pronunciationTypename:
+		pronunciation, err := pronunciationByID(ctx, s, databaseID)
+		if pronunciation == nil {
+			return nil, err
+		}
+		return pronunciation, nil
+	case recordingTypename:
+		recording, err := recordingByID(ctx, s, databaseID)
+		if recording == nil {
+			return nil, err
+		}
+		return recording, nil
+	case tagTypename:
+		tag, err := tagByID(ctx, s, databaseID)
+		if tag == nil {
+			return nil, err
+		}
+		return tag, nil
+	case collectionTypename:
+		collection, err := collectionByID(ctx, s, databaseID)
+		if collection == nil {
+			return nil, err
+		}
+		return collection, nil
+	default:
+		return nil, invalidInput("ID %q refers to unknown type %q", id, typename)
+	}
+}
+
+// translationsOf returns the translations of the word, in the language with given code when it is given,
+// of its senses of given part of speech when it is given. Translations preloaded into the Translations
+// of the word are used instead of querying the store, unless they are filtered by part of speech.
+func translationsOf(ctx context.Context, s store.DictionaryStore, word *model.Word, language *string, partOfSpeech *model.PartOfSpeech) ([]*model.Word, error) {
+	var translations []*model.Word
+	var err error
+	switch {
+	case partOfSpeech != nil:
+		translations, err = translationsInSenses(ctx, s, word.ID, *partOfSpeech)
+	case word.Translations != nil:
+		translations = word.Translations
+	default:
+		translations, err = loadTranslations(ctx, s, word.ID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if language == nil {
+		return translations, nil
+	}
+
+	resolved, err := loadLanguage(ctx, s, *language)
+	if err != nil {
+		return nil, err
+	}
+	filtered := make([]*model.Word, 0, len(translations))
+	for _, translation := range translations {
+		if translation.Language == resolved.Code {
+			filtered = append(filtered, translation)
+		}
+	}
+	return filtered, nil
+}
+
+// translationsInSenses returns the words which translations of the senses of the word with given part of speech
+// point to, ordered by ID.
+func translationsInSenses(ctx context.Context, s store.DictionaryStore, wordID int, partOfSpeech model.PartOfSpeech) ([]*model.Word, error) {
+	senses, err := loadSenses(ctx, s, wordID)
+	if err != nil {
+		return nil, err
+	}
+	var senseIDs []int
+	for _, sense := range senses {
+		if sense.PartOfSpeech == partOfSpeech {
+			senseIDs = append(senseIDs, sense.ID)
+		}
+	}
+	translations, err := loadSenseTranslations(ctx, s, senseIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[int]bool)
+	var ids []int
+	for _, translation := range translations {
+		id := translation.TranslationID
+		if id == wordID {
+			id = translation.WordID
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	return loadWords(ctx, s, ids)
+}
+
+// translatedSenses returns the senses translations of the sense point to, ordered by ID.
+func translatedSenses(ctx context.Context, s store.DictionaryStore, sense *model.Sense) ([]*model.Sense, error) {
+	translations, err := loadSenseTranslations(ctx, s, []int{sense.ID})
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int, 0, len(translations))
+	for _, translation := range translations {
+		if translation.SenseID == sense.ID {
+			ids = append(ids, translation.TranslationSenseID)
+		} else {
+			ids = append(ids, translation.SenseID)
+		}
+	}
+	sort.Ints(ids)
+	return loadSensesByIDs(ctx, s, ids)
+}
@@ -0,0 +1,89 @@
+package graph
+
+import (
+	"backend/graph/model"
+	"backend/store"
+	"encoding/base64"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+	wordCursorKey   = "word:"
+)
+
+// pageArgs holds the Relay connection arguments of a paginated field.
+type pageArgs struct {
+	first  *int32
+	after  *string
+	last   *int32
+	before *string
+}
+
+// page validates the arguments and converts them to the page of words they select.
+func (args pageArgs) page() (store.Page, error) {
+	var page store.Page
+	var err error
+	if args.first != nil && args.last != nil {
+		return page, invalidInput("first and last must not be used together")
+	}
+	size := int32(defaultPageSize)
+	if args.first != nil {
+		size = *args.first
+	} else if args.last != nil {
+		size = *args.last
+		page.Backward = true
+	}
+	if size < 0 {
+		return page, invalidInput("page size must not be negative")
+	}
+	if size > maxPageSize {
+		return page, invalidInput("page size must not exceed %d", maxPageSize)
+	}
+	page.Limit = int(size)
+
+	if args.after != nil {
+		if page.AfterID, err = decodeCursor(*args.after); err != nil {
+			return page, err
+		}
+	}
+	if args.before != nil {
+		if page.BeforeID, err = decodeCursor(*args.before); err != nil {
+			return page, err
+		}
+	}
+	return page, nil
+}
+
+func encodeCursor(id int) string {
+	return base64.StdEncoding.EncodeToString([]byte(wordCursorKey + strconv.Itoa(id)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	raw, err := base64.StdEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(raw), wordCursorKey) {
+		return 0, invalidInput("invalid cursor %q", cursor)
+	}
+	id, err := strconv.Atoi(strings.TrimPrefix(string(raw), wordCursorKey))
+	if err != nil || id <= 0 {
+		return 0, invalidInput("invalid cursor %q", cursor)
+	}
+	return id, nil
+}
+
+// wordConnection converts a page of words ordered by ID to a Relay connection.
+// Cursors are keyset based, so pages stay stable while words are added or removed.
+func wordConnection(page *store.WordPage) *model.WordConnection {
+	pageInfo := &model.PageInfo{HasNextPage: page.HasNextPage, HasPreviousPage: page.HasPreviousPage}
+	edges := make([]*model.WordEdge, len(page.Words))
+	for i, word := range page.Words {
+		edges[i] = &model.WordEdge{Cursor: encodeCursor(word.ID), Node: word}
+	}
+	if len(edges) > 0 {
+		pageInfo.StartCursor = &edges[0].Cursor
+		pageInfo.EndCursor = &edges[len(edges)-1].Cursor
+	}
+	return &model.WordConnection{Edges: edges, PageInfo: pageInfo, TotalCount: int32(page.Total)}
+}
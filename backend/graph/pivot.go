@@ -0,0 +1,55 @@
+package graph
+
+import (
+	"backend/graph/model"
+	"backend/store"
+	"context"
+	"fmt"
+)
+
+const (
+	defaultMaxHops = 2
+	maxMaxHops     = 4
+)
+
+// translateVia finds words in targetLanguage reachable from source through at most maxHops translations.
+// Every candidate is returned once, with the shortest path leading to it.
+func translateVia(ctx context.Context, s store.DictionaryStore, source *model.Word, targetLanguage string, maxHops int) ([]*model.TranslationPath, error) {
+	if maxHops < 1 || maxHops > maxMaxHops {
+		return nil, invalidInput("maxHops must be between 1 and %d", maxMaxHops)
+	}
+
+	paths, err := s.TranslationPaths(ctx, source.ID, targetLanguage, maxHops)
+	if err != nil {
+		return nil, err
+	}
+	var pathWordIDs []int
+	for _, path := range paths {
+		pathWordIDs = append(pathWordIDs, path...)
+	}
+	words, err := s.FindWordsByIDs(ctx, pathWordIDs)
+	if err != nil {
+		return nil, err
+	}
+	wordsByID := make(map[int]*model.Word, len(words))
+	for _, word := range words {
+		wordsByID[word.ID] = word
+	}
+
+	result := make([]*model.TranslationPath, 0, len(paths))
+	for _, path := range paths {
+		pathWords := make([]*model.Word, len(path))
+		for i, id := range path {
+			pathWords[i] = wordsByID[id]
+			if pathWords[i] == nil {
+				return nil, fmt.Errorf("word %d on translation path is missing in database", id)
+			}
+		}
+		result = append(result, &model.TranslationPath{
+			Word: pathWords[len(pathWords)-1],
+			Path: pathWords,
+			Hops: int32(len(path) - 1),
+		})
+	}
+	return result, nil
+}
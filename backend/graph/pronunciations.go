@@ -0,0 +1,42 @@
+package graph
+
+import (
+	"backend/graph/model"
+	"backend/store"
+	"context"
+	"strings"
+)
+
+// applyPronunciationChange validates the transcription and accent given in a mutation and sets them on the
+// pronunciation, fields left out keep their value.
+func applyPronunciationChange(pronunciation *model.Pronunciation, ipa *string, accent *string) error {
+	if ipa != nil {
+		transcription := strings.TrimSpace(*ipa)
+		if transcription == "" {
+			return invalidInput("transcription must not be empty")
+		}
+		pronunciation.IPA = transcription
+	}
+	if accent != nil {
+		pronunciation.Accent = strings.TrimSpace(*accent)
+	}
+	return nil
+}
+
+// pronunciationByID returns the pronunciation with given database ID, or nil when there is none.
+func pronunciationByID(ctx context.Context, s store.DictionaryStore, id int) (*model.Pronunciation, error) {
+	pronunciations, err := s.FindPronunciationsByIDs(ctx, []int{id})
+	if err != nil || len(pronunciations) == 0 {
+		return nil, err
+	}
+	return pronunciations[0], nil
+}
+
+// findPronunciation returns the pronunciation a global ID refers to, or nil when it does not exist.
+func findPronunciation(ctx context.Context, s store.DictionaryStore, id string) (*model.Pronunciation, error) {
+	pronunciationID, err := decodeID(id, pronunciationTypename)
+	if err != nil {
+		return nil, err
+	}
+	return pronunciationByID(ctx, s, pronunciationID)
+}
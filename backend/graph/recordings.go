@@ -0,0 +1,133 @@
+package graph
+
+import (
+	"backend/blob"
+	"backend/graph/model"
+	"backend/store"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+)
+
+// RecordingPattern is the pattern of the URLs RecordingHandler serves audio of recordings at, ending with
+// the global ID of the recording.
+const RecordingPattern = recordingPath + "{id}"
+
+const recordingPath = "/audio/"
+
+// maxRecordingSize bounds the size of uploaded audio, a recording is a word or a phrase.
+const maxRecordingSize = 10 << 20
+
+// recordingURL returns the path the audio of the recording is served at.
+func recordingURL(recording *model.Recording) string {
+	return recordingPath + url.PathEscape(globalID(recordingTypename, recording.ID))
+}
+
+// recordingContentType returns the media type of uploaded audio. The type the client gave is used when it is
+// audio, otherwise the type is sniffed from the content, which fails unless it is audio.
+func recordingContentType(file graphql.Upload) (string, error) {
+	if mediaType, _, err := mime.ParseMediaType(file.ContentType); err == nil && strings.HasPrefix(mediaType, "audio/") {
+		return mediaType, nil
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file.File, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return "", err
+	}
+	if _, err := file.File.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	mediaType, _, _ := mime.ParseMediaType(http.DetectContentType(head[:n]))
+	switch {
+	case strings.HasPrefix(mediaType, "audio/"):
+		return mediaType, nil
+	case mediaType == "application/ogg":
+		return "audio/ogg", nil
+	}
+	return "", invalidInput("file %q is not audio", file.Filename)
+}
+
+// recordingByID returns the recording with given database ID, or nil when there is none.
+func recordingByID(ctx context.Context, s store.DictionaryStore, id int) (*model.Recording, error) {
+	recordings, err := s.FindRecordingsByIDs(ctx, []int{id})
+	if err != nil || len(recordings) == 0 {
+		return nil, err
+	}
+	return recordings[0], nil
+}
+
+// findRecording returns the recording a global ID refers to, or nil when it does not exist.
+func findRecording(ctx context.Context, s store.DictionaryStore, id string) (*model.Recording, error) {
+	recordingID, err := decodeID(id, recordingTypename)
+	if err != nil {
+		return nil, err
+	}
+	return recordingByID(ctx, s, recordingID)
+}
+
+// deleteAudio removes the audio of deleted recordings from the blob store. The recordings are gone already,
+// so failures only leave unused blobs behind and are logged.
+func deleteAudio(ctx context.Context, blobs blob.Store, recordings []*model.Recording) {
+	if blobs == nil {
+		return
+	}
+	for _, recording := range recordings {
+		if err := blobs.Delete(context.WithoutCancel(ctx), recording.BlobKey); err != nil {
+			log.Printf("failed to delete audio of recording %d: %v", recording.ID, err)
+		}
+	}
+}
+
+// RecordingHandler streams the audio of recordings from blobs. Range requests are supported, so players
+// can seek without downloading the whole recording.
+func RecordingHandler(s store.DictionaryStore, blobs blob.Store) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		id, err := decodeID(r.PathValue("id"), recordingTypename)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		recording, err := recordingByID(r.Context(), s, id)
+		if err != nil {
+			log.Printf("lookup of recording %d failed: %v", id, err)
+			http.Error(w, "lookup failed", http.StatusInternalServerError)
+			return
+		}
+		if recording == nil {
+			http.NotFound(w, r)
+			return
+		}
+
+		audio, err := blobs.Open(r.Context(), recording.BlobKey)
+		if errors.Is(err, blob.ErrNotFound) {
+			log.Printf("audio of recording %d is missing in blob store", id)
+			http.NotFound(w, r)
+			return
+		} else if err != nil {
+			log.Printf("reading audio of recording %d failed: %v", id, err)
+			http.Error(w, "reading audio failed", http.StatusInternalServerError)
+			return
+		}
+		defer audio.Close()
+
+		// Audio never changes once uploaded, its key identifies the content.
+		w.Header().Set("Content-Type", recording.ContentType)
+		w.Header().Set("ETag", strconv.Quote(recording.BlobKey))
+		http.ServeContent(w, r, "", time.Time{}, audio)
+	})
+}
@@ -1,11 +1,19 @@
 package graph
 
-import "gorm.io/gorm"
+import (
+	"backend/blob"
+	"backend/events"
+	"backend/store"
+)
 
 // This file will not be regenerated automatically.
 //
 // It serves as dependency injection for your app, add any dependencies you require here.
 
 type Resolver struct {
-	DB *gorm.DB
+	Store store.DictionaryStore
+	// Events delivers the changes subscriptions report, nil disables subscriptions.
+	Events *events.Bus
+	// Blobs keeps the audio of recordings, nil disables uploading recordings.
+	Blobs blob.Store
 }
@@ -5,211 +5,1275 @@ package graph
 // Code generated by github.com/99designs/gqlgen version v0.17.66
 
 import (
+	"backend/blob"
+	"backend/events"
+	"backend/exchange"
 	"backend/graph/model"
+	"backend/normalize"
+	"backend/store"
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
-	"gorm.io/gorm"
-	"gorm.io/gorm/clause"
+	"github.com/99designs/gqlgen/graphql"
 )
 
-// AddTranslation is the resolver for the addTranslation field.
-func (r *mutationResolver) AddTranslation(ctx context.Context, sourceText string, sourceTextLanguage string, translatedText string, translatedTextLanguage string) (*model.Translation, error) {
-	var sourceWord model.Word
-	var translatedWord model.Word
+// ID is the resolver for the id field.
+func (r *collectionResolver) ID(ctx context.Context, obj *model.Collection) (string, error) {
+	return globalID(collectionTypename, obj.ID), nil
+}
 
-	tx := r.DB.Begin()
-	defer func() {
-		tx.Rollback()
-	}()
-	if sourceText == "" || sourceTextLanguage == "" || translatedText == "" || translatedTextLanguage == "" {
-		return nil, fmt.Errorf("word and language must not be empty")
+// Words is the resolver for the words field.
+func (r *collectionResolver) Words(ctx context.Context, obj *model.Collection, first *int32, after *string, last *int32, before *string) (*model.WordConnection, error) {
+	page, err := pageArgs{first: first, after: after, last: last, before: before}.page()
+	if err != nil {
+		return nil, err
 	}
 
-	sourceWord = model.Word{Text: sourceText, Language: sourceTextLanguage}
-	err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&sourceWord).Error
+	var words *store.WordPage
+	err = r.Store.Transaction(ctx, func(tx store.DictionaryStore) error {
+		var err error
+		words, err = tx.ListWords(ctx, store.WordFilter{CollectionID: obj.ID}, page)
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("an error occurred while inserting source word: %w", err)
+		return nil, err
+	}
+	expectTranslations(ctx, words.Words)
+	return wordConnection(words), nil
+}
+
+// ID is the resolver for the id field.
+func (r *exampleResolver) ID(ctx context.Context, obj *model.Example) (string, error) {
+	return globalID(exampleTypename, obj.ID), nil
+}
+
+// Word is the resolver for the word field.
+func (r *exampleResolver) Word(ctx context.Context, obj *model.Example) (*model.Word, error) {
+	word, err := loadWord(ctx, r.Store, obj.WordID)
+	if err == nil && word == nil {
+		return nil, fmt.Errorf("word of example %d is missing in database: %w", obj.ID, store.ErrNotFound)
 	}
+	return word, err
+}
 
-	if sourceWord.ID == 0 {
-		err = tx.First(&sourceWord, "text = ? AND language = ?", sourceText, sourceTextLanguage).Error
-		if err != nil {
-			return nil, fmt.Errorf("an error occurred while selecting source word: %w", err)
+// Sense is the resolver for the sense field.
+func (r *exampleResolver) Sense(ctx context.Context, obj *model.Example) (*model.Sense, error) {
+	if obj.SenseID == nil {
+		return nil, nil
+	}
+	return loadSense(ctx, r.Store, *obj.SenseID)
+}
+
+// Translation is the resolver for the translation field.
+func (r *exampleResolver) Translation(ctx context.Context, obj *model.Example) (*model.Example, error) {
+	if obj.TranslatedExampleID == nil {
+		return nil, nil
+	}
+	return loadExample(ctx, r.Store, *obj.TranslatedExampleID)
+}
+
+// AddLanguage is the resolver for the addLanguage field.
+func (r *mutationResolver) AddLanguage(ctx context.Context, code string, name string, nativeName *string, script *string, direction *model.TextDirection, caseSensitive *bool) (*model.Language, error) {
+	canonical, ok := model.CanonicalLanguageCode(code)
+	if !ok {
+		return nil, invalidInput("%q is not a valid BCP 47 language code", code)
+	}
+	if name == "" {
+		return nil, invalidInput("language name must not be empty")
+	}
+
+	language := model.Language{Code: canonical, Name: name, Script: model.DefaultScript(canonical)}
+	if nativeName != nil {
+		language.NativeName = *nativeName
+	}
+	if script != nil {
+		language.Script, ok = model.CanonicalScript(*script)
+		if !ok {
+			return nil, invalidInput("%q is not a valid ISO 15924 script", *script)
 		}
 	}
+	language.Direction = model.ScriptDirection(language.Script)
+	if direction != nil {
+		language.Direction = *direction
+	}
+	if caseSensitive != nil {
+		language.CaseSensitive = *caseSensitive
+	}
 
-	translatedWord = model.Word{Text: translatedText, Language: translatedTextLanguage}
-	err = tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&translatedWord).Error
+	err := r.Store.Transaction(ctx, func(tx store.DictionaryStore) error {
+		_, err := tx.AddLanguage(ctx, &language)
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("an error occurred while inserting translated word: %w", err)
+		return nil, err
+	}
+	return &language, nil
+}
+
+// AddTranslation is the resolver for the addTranslation field.
+func (r *mutationResolver) AddTranslation(ctx context.Context, sourceText string, sourceTextLanguage string, translatedText string, translatedTextLanguage string) (*model.Translation, error) {
+	if sourceText == "" || sourceTextLanguage == "" || translatedText == "" || translatedTextLanguage == "" {
+		return nil, invalidInput("word and language must not be empty")
 	}
 
-	if translatedWord.ID == 0 {
-		err = tx.First(&translatedWord, "text = ? AND language = ?", translatedText, translatedTextLanguage).Error
+	var sortedTranslation model.Translation
+	err := r.Store.Transaction(ctx, func(tx store.DictionaryStore) error {
+		sourceWord, err := store.NormalizeWord(ctx, tx, sourceText, sourceTextLanguage)
 		if err != nil {
-			return nil, fmt.Errorf("an error occurred while selecting translated word: %w", err)
+			return err
+		}
+		translatedWord, err := store.NormalizeWord(ctx, tx, translatedText, translatedTextLanguage)
+		if err != nil {
+			return err
+		}
+		if sourceWord.Text == "" || translatedWord.Text == "" {
+			return invalidInput("word and language must not be empty")
 		}
-	}
 
-	sortedTranslation := model.Translation{WordID: translatedWord.ID, TranslationID: sourceWord.ID}
-	sortedTranslation.SortTranslation()
+		_, err = tx.FindOrCreateWord(ctx, &sourceWord)
+		if err != nil {
+			return fmt.Errorf("an error occurred while inserting source word: %w", err)
+		}
+		_, err = tx.FindOrCreateWord(ctx, &translatedWord)
+		if err != nil {
+			return fmt.Errorf("an error occurred while inserting translated word: %w", err)
+		}
 
-	err = tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&sortedTranslation).Error
+		// AddTranslations sets the default senses the translation links.
+		translations := []model.Translation{{WordID: translatedWord.ID, TranslationID: sourceWord.ID}}
+		translations[0].SortTranslation()
+		if _, err := tx.AddTranslations(ctx, translations); err != nil {
+			return err
+		}
+		sortedTranslation = translations[0]
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("database error while inserting translation: %w", err)
+		return nil, err
 	}
-	tx.Commit()
-
 	return &sortedTranslation, nil
 }
 
 // AddWord is the resolver for the addWord field.
 func (r *mutationResolver) AddWord(ctx context.Context, text string, language string, exampleUsage string) (*model.Word, error) {
-	var addedWord model.Word
-	tx := r.DB.Begin()
-	defer func() {
-		tx.Rollback()
-	}()
 	if text == "" || language == "" {
-		return nil, fmt.Errorf("word and language must not be empty")
+		return nil, invalidInput("word and language must not be empty")
 	}
 
-	addedWord = model.Word{Text: text, Language: language, ExampleUsage: exampleUsage}
-	err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&addedWord).Error
+	var addedWord model.Word
+	err := r.Store.Transaction(ctx, func(tx store.DictionaryStore) error {
+		var err error
+		addedWord, err = store.NormalizeWord(ctx, tx, text, language)
+		if err != nil {
+			return err
+		}
+		if addedWord.Text == "" {
+			return invalidInput("word and language must not be empty")
+		}
 
+		created, err := tx.FindOrCreateWord(ctx, &addedWord)
+		if err != nil || !created {
+			return err
+		}
+		return store.AddExampleTexts(ctx, tx, []*model.Word{&addedWord}, [][]string{{exampleUsage}})
+	})
 	if err != nil {
-		return nil, fmt.Errorf("error inserting translated word: %w", err)
+		return nil, err
 	}
-
-	tx.Commit()
 	return &addedWord, nil
 }
 
 // DeleteWord is the resolver for the deleteWord field.
 func (r *mutationResolver) DeleteWord(ctx context.Context, text string, language string) (*model.Word, error) {
-	var deletedWord model.Word
-	tx := r.DB.Begin()
-	defer func() {
-		tx.Rollback()
-	}()
-	err := tx.Where("text = ? and language = ?", text, language).First(&deletedWord).Error
-	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return &model.Word{}, nil
-	} else if err != nil {
-		return nil, fmt.Errorf("database error while finding word: %w", err)
+	var deletedWord *model.Word
+	var recordings []*model.Recording
+	err := r.Store.Transaction(ctx, func(tx store.DictionaryStore) error {
+		key, err := store.NormalizeWord(ctx, tx, text, language)
+		if err != nil {
+			return err
+		}
+		word, err := tx.FindWord(ctx, key.Text, key.Language)
+		if errors.Is(err, store.ErrNotFound) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		byWord, err := tx.RecordingsOf(ctx, []int{word.ID})
+		if err != nil {
+			return err
+		}
+		deletedWord, recordings = word, byWord[word.ID]
+		return tx.DeleteWord(ctx, word)
+	})
+	if err != nil {
+		return nil, err
 	}
-	err = tx.Select(clause.Associations).Delete(&deletedWord).Error
+	deleteAudio(ctx, r.Blobs, recordings)
+	return deletedWord, nil
+}
+
+// UpdateWord is the resolver for the updateWord field.
+func (r *mutationResolver) UpdateWord(ctx context.Context, id *string, key *model.WordKeyInput, input model.UpdateWordInput) (*model.Word, error) {
+	if input.Text != nil && *input.Text == "" {
+		return nil, invalidInput("word must not be empty")
+	}
+	if input.Language != nil && *input.Language == "" {
+		return nil, invalidInput("language must not be empty")
+	}
+
+	var word *model.Word
+	err := r.Store.Transaction(ctx, func(tx store.DictionaryStore) error {
+		var err error
+		word, err = findWord(ctx, tx, id, key)
+		if err != nil {
+			return err
+		}
+
+		text := word.DisplayText
+		if text == "" {
+			text = word.Text
+		}
+		if input.Text != nil {
+			text = *input.Text
+		}
+		language := word.Language
+		if input.Language != nil {
+			language = *input.Language
+		}
+		// The text is normalized again when the language changes, which may compare case differently.
+		if input.Text != nil || input.Language != nil {
+			updated, err := store.NormalizeWord(ctx, tx, text, language)
+			if err != nil {
+				return err
+			}
+			if updated.Text == "" {
+				return invalidInput("word must not be empty")
+			}
+			existing, err := tx.FindWord(ctx, updated.Text, updated.Language)
+			if err == nil && existing.ID != word.ID {
+				return conflict("word %q already exists in language %q", existing.DisplayText, existing.Language)
+			} else if err != nil && !errors.Is(err, store.ErrNotFound) {
+				return err
+			}
+			word.Text, word.DisplayText, word.Language = updated.Text, updated.DisplayText, updated.Language
+		}
+		if err := tx.UpdateWord(ctx, word); err != nil {
+			return err
+		}
+		if input.ExampleUsage != nil {
+			return store.SetExampleUsage(ctx, tx, word, *input.ExampleUsage)
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("database error while removing word: %w", err)
+		return nil, err
 	}
+	return word, nil
+}
 
-	tx.Commit()
-	return &deletedWord, nil
+// DeleteTranslation is the resolver for the deleteTranslation field.
+func (r *mutationResolver) DeleteTranslation(ctx context.Context, sourceText string, sourceTextLanguage string, translatedText string, translatedTextLanguage string) (*model.Translation, error) {
+	var resultTranslation *model.Translation
+	err := r.Store.Transaction(ctx, func(tx store.DictionaryStore) error {
+		sourceKey, err := store.NormalizeWord(ctx, tx, sourceText, sourceTextLanguage)
+		if err != nil {
+			return err
+		}
+		translatedKey, err := store.NormalizeWord(ctx, tx, translatedText, translatedTextLanguage)
+		if err != nil {
+			return err
+		}
+
+		sourceWord, err := tx.FindWord(ctx, sourceKey.Text, sourceKey.Language)
+		if errors.Is(err, store.ErrNotFound) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		translatedWord, err := tx.FindWord(ctx, translatedKey.Text, translatedKey.Language)
+		if errors.Is(err, store.ErrNotFound) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		sortedTranslation := model.Translation{WordID: sourceWord.ID, TranslationID: translatedWord.ID}
+		sortedTranslation.SortTranslation()
+		deleted, err := tx.DeleteTranslation(ctx, sortedTranslation)
+		if deleted {
+			resultTranslation = &sortedTranslation
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resultTranslation, nil
 }
 
-// UpdateWord is the resolver for the updateWord field.
-func (r *mutationResolver) UpdateWord(ctx context.Context, sourceText string, sourceLanguage string, updatedText string, updatedExampleUsage string) (*model.Word, error) {
-	if sourceText == "" || sourceLanguage == "" {
-		return nil, fmt.Errorf("word and language must not be empty")
+// ImportTranslations is the resolver for the importTranslations field.
+func (r *mutationResolver) ImportTranslations(ctx context.Context, file graphql.Upload, format *model.TranslationFileFormat, dryRun *bool) (*model.ImportReport, error) {
+	fileFormat := model.TranslationFileFormatCSV
+	if format != nil {
+		fileFormat = *format
+	}
+	return exchange.ImportTranslations(ctx, r.Store, file.File, fileFormat, dryRun != nil && *dryRun)
+}
+
+// AddWords is the resolver for the addWords field.
+func (r *mutationResolver) AddWords(ctx context.Context, input []*model.WordInput, atomic *bool) (*model.AddWordsPayload, error) {
+	return addWords(ctx, r.Store, input, atomic)
+}
+
+// AddTranslations is the resolver for the addTranslations field.
+func (r *mutationResolver) AddTranslations(ctx context.Context, input []*model.TranslationInput, atomic *bool) (*model.AddTranslationsPayload, error) {
+	return addTranslations(ctx, r.Store, input, atomic)
+}
+
+// DeleteTranslations is the resolver for the deleteTranslations field.
+func (r *mutationResolver) DeleteTranslations(ctx context.Context, input []*model.TranslationInput, atomic *bool) (*model.DeleteTranslationsPayload, error) {
+	return deleteTranslations(ctx, r.Store, input, atomic)
+}
+
+// AddSense is the resolver for the addSense field.
+func (r *mutationResolver) AddSense(ctx context.Context, wordID string, input model.SenseInput) (*model.Sense, error) {
+	id, err := decodeID(wordID, wordTypename)
+	if err != nil {
+		return nil, err
+	}
+	sense := &model.Sense{WordID: id}
+	applySenseInput(sense, input)
+
+	err = r.Store.Transaction(ctx, func(tx store.DictionaryStore) error {
+		word, err := wordByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if word == nil {
+			return fmt.Errorf("word %s is missing in database: %w", wordID, store.ErrNotFound)
+		}
+		_, err = tx.FindOrCreateSense(ctx, sense)
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
-	var word model.Word
-	tx := r.DB.Begin()
-	defer func() {
-		tx.Rollback()
-	}()
+	return sense, nil
+}
 
-	err := tx.Where("text = ? and language = ?", sourceText, sourceLanguage).First(&word).Error
+// UpdateSense is the resolver for the updateSense field.
+func (r *mutationResolver) UpdateSense(ctx context.Context, id string, input model.SenseInput) (*model.Sense, error) {
+	var sense *model.Sense
+	err := r.Store.Transaction(ctx, func(tx store.DictionaryStore) error {
+		var err error
+		sense, err = findSense(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if sense == nil {
+			return fmt.Errorf("sense %s is missing in database: %w", id, store.ErrNotFound)
+		}
+		applySenseInput(sense, input)
+		return tx.UpdateSense(ctx, sense)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("word is missing in database: %w", err)
+		return nil, err
 	}
+	return sense, nil
+}
 
-	word.Text = updatedText
-	word.ExampleUsage = updatedExampleUsage
-	err = tx.Save(&word).Error
+// DeleteSense is the resolver for the deleteSense field.
+func (r *mutationResolver) DeleteSense(ctx context.Context, id string) (*model.Sense, error) {
+	var deletedSense *model.Sense
+	err := r.Store.Transaction(ctx, func(tx store.DictionaryStore) error {
+		sense, err := findSense(ctx, tx, id)
+		if sense == nil {
+			return err
+		}
+		deletedSense = sense
+		return tx.DeleteSense(ctx, sense)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("database error while updating word: %w", err)
+		return nil, err
 	}
+	return deletedSense, nil
+}
 
-	tx.Commit()
-	return &word, nil
+// AddSenseTranslation is the resolver for the addSenseTranslation field.
+func (r *mutationResolver) AddSenseTranslation(ctx context.Context, senseID string, translatedSenseID string) (*model.Translation, error) {
+	var translation *model.Translation
+	err := r.Store.Transaction(ctx, func(tx store.DictionaryStore) error {
+		var err error
+		translation, err = senseTranslation(ctx, tx, senseID, translatedSenseID)
+		if err != nil {
+			return err
+		}
+		if translation == nil {
+			return fmt.Errorf("sense is missing in database: %w", store.ErrNotFound)
+		}
+		return tx.AddTranslation(ctx, *translation)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return translation, nil
 }
 
-// DeleteTranslation is the resolver for the deleteTranslation field.
-func (r *mutationResolver) DeleteTranslation(ctx context.Context, sourceText string, sourceTextLanguage string, translatedText string, translatedTextLanguage string) (*model.Translation, error) {
-	var sourceWord, translatedWord model.Word
-	var resultTranslation model.Translation
+// DeleteSenseTranslation is the resolver for the deleteSenseTranslation field.
+func (r *mutationResolver) DeleteSenseTranslation(ctx context.Context, senseID string, translatedSenseID string) (*model.Translation, error) {
+	var resultTranslation *model.Translation
+	err := r.Store.Transaction(ctx, func(tx store.DictionaryStore) error {
+		translation, err := senseTranslation(ctx, tx, senseID, translatedSenseID)
+		if translation == nil {
+			return err
+		}
+		deleted, err := tx.DeleteTranslation(ctx, *translation)
+		if deleted {
+			resultTranslation = translation
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resultTranslation, nil
+}
+
+// AddExample is the resolver for the addExample field.
+func (r *mutationResolver) AddExample(ctx context.Context, wordID string, input model.ExampleInput) (*model.Example, error) {
+	id, err := decodeID(wordID, wordTypename)
+	if err != nil {
+		return nil, err
+	}
+
+	var example *model.Example
+	err = r.Store.Transaction(ctx, func(tx store.DictionaryStore) error {
+		word, err := wordByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if word == nil {
+			return fmt.Errorf("word %s is missing in database: %w", wordID, store.ErrNotFound)
+		}
+		example = &model.Example{WordID: word.ID, Language: word.Language}
+		err = applyExampleChange(ctx, tx, example, exampleChange{
+			text:          &input.Text,
+			language:      input.Language,
+			source:        input.Source,
+			senseID:       graphql.OmittableOf(input.SenseID),
+			translationID: graphql.OmittableOf(input.TranslationID),
+		})
+		if err != nil {
+			return err
+		}
+		return tx.AddExample(ctx, example)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return example, nil
+}
+
+// UpdateExample is the resolver for the updateExample field.
+func (r *mutationResolver) UpdateExample(ctx context.Context, id string, input model.UpdateExampleInput) (*model.Example, error) {
+	var example *model.Example
+	err := r.Store.Transaction(ctx, func(tx store.DictionaryStore) error {
+		var err error
+		example, err = findExample(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if example == nil {
+			return fmt.Errorf("example %s is missing in database: %w", id, store.ErrNotFound)
+		}
+		err = applyExampleChange(ctx, tx, example, exampleChange{
+			text:          input.Text,
+			language:      input.Language,
+			source:        input.Source,
+			senseID:       input.SenseID,
+			translationID: input.TranslationID,
+		})
+		if err != nil {
+			return err
+		}
+		return tx.UpdateExample(ctx, example)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return example, nil
+}
+
+// DeleteExample is the resolver for the deleteExample field.
+func (r *mutationResolver) DeleteExample(ctx context.Context, id string) (*model.Example, error) {
+	var deletedExample *model.Example
+	err := r.Store.Transaction(ctx, func(tx store.DictionaryStore) error {
+		example, err := findExample(ctx, tx, id)
+		if example == nil {
+			return err
+		}
+		deletedExample = example
+		return tx.DeleteExample(ctx, example)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deletedExample, nil
+}
 
-	tx := r.DB.Begin()
-	defer func() { tx.Rollback() }()
+// AddPronunciation is the resolver for the addPronunciation field.
+func (r *mutationResolver) AddPronunciation(ctx context.Context, wordID string, input model.PronunciationInput) (*model.Pronunciation, error) {
+	id, err := decodeID(wordID, wordTypename)
+	if err != nil {
+		return nil, err
+	}
+	pronunciation := &model.Pronunciation{WordID: id}
+	if err := applyPronunciationChange(pronunciation, &input.IPA, input.Accent); err != nil {
+		return nil, err
+	}
 
-	err := tx.First(&sourceWord, model.Word{Text: sourceText, Language: sourceTextLanguage}).Error
+	err = r.Store.Transaction(ctx, func(tx store.DictionaryStore) error {
+		word, err := wordByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if word == nil {
+			return fmt.Errorf("word %s is missing in database: %w", wordID, store.ErrNotFound)
+		}
+		_, err = tx.FindOrCreatePronunciation(ctx, pronunciation)
+		return err
+	})
 	if err != nil {
-		return &model.Translation{}, nil
+		return nil, err
 	}
+	return pronunciation, nil
+}
 
-	err = tx.First(&translatedWord, model.Word{Text: translatedText, Language: translatedTextLanguage}).Error
+// UpdatePronunciation is the resolver for the updatePronunciation field.
+func (r *mutationResolver) UpdatePronunciation(ctx context.Context, id string, input model.UpdatePronunciationInput) (*model.Pronunciation, error) {
+	var pronunciation *model.Pronunciation
+	err := r.Store.Transaction(ctx, func(tx store.DictionaryStore) error {
+		var err error
+		pronunciation, err = findPronunciation(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if pronunciation == nil {
+			return fmt.Errorf("pronunciation %s is missing in database: %w", id, store.ErrNotFound)
+		}
+		if err := applyPronunciationChange(pronunciation, input.IPA, input.Accent); err != nil {
+			return err
+		}
+		return tx.UpdatePronunciation(ctx, pronunciation)
+	})
 	if err != nil {
-		return &model.Translation{}, nil
+		return nil, err
 	}
+	return pronunciation, nil
+}
 
-	sortedTranslation := model.Translation{WordID: sourceWord.ID, TranslationID: translatedWord.ID}
-	sortedTranslation.SortTranslation()
+// DeletePronunciation is the resolver for the deletePronunciation field.
+func (r *mutationResolver) DeletePronunciation(ctx context.Context, id string) (*model.Pronunciation, error) {
+	var deletedPronunciation *model.Pronunciation
+	err := r.Store.Transaction(ctx, func(tx store.DictionaryStore) error {
+		pronunciation, err := findPronunciation(ctx, tx, id)
+		if pronunciation == nil {
+			return err
+		}
+		deletedPronunciation = pronunciation
+		return tx.DeletePronunciation(ctx, pronunciation)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deletedPronunciation, nil
+}
 
-	result := tx.Where("word_id = ? AND translation_id = ?", sortedTranslation.WordID, sortedTranslation.TranslationID).Delete(&resultTranslation)
-	if result.Error != nil {
-		return nil, fmt.Errorf("database error while deleting translation: %w", result.Error)
+// UploadRecording is the resolver for the uploadRecording field.
+func (r *mutationResolver) UploadRecording(ctx context.Context, wordID string, file graphql.Upload, accent *string) (*model.Recording, error) {
+	if r.Blobs == nil {
+		return nil, invalidInput("uploading recordings is disabled, no blob store is configured")
+	}
+	id, err := decodeID(wordID, wordTypename)
+	if err != nil {
+		return nil, err
+	}
+	if file.Size == 0 {
+		return nil, invalidInput("recording must not be empty")
+	}
+	if file.Size > maxRecordingSize {
+		return nil, invalidInput("recording must not exceed %d bytes", maxRecordingSize)
+	}
+	contentType, err := recordingContentType(file)
+	if err != nil {
+		return nil, err
 	}
 
-	tx.Commit()
-	return &resultTranslation, nil
+	recording := &model.Recording{WordID: id, ContentType: contentType, BlobKey: blob.NewKey()}
+	if accent != nil {
+		recording.Accent = strings.TrimSpace(*accent)
+	}
+	recording.Size, err = r.Blobs.Put(ctx, recording.BlobKey, file.File)
+	if err != nil {
+		return nil, err
+	}
+	err = r.Store.Transaction(ctx, func(tx store.DictionaryStore) error {
+		word, err := wordByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if word == nil {
+			return fmt.Errorf("word %s is missing in database: %w", wordID, store.ErrNotFound)
+		}
+		return tx.AddRecording(ctx, recording)
+	})
+	if err != nil {
+		deleteAudio(ctx, r.Blobs, []*model.Recording{recording})
+		return nil, err
+	}
+	return recording, nil
+}
+
+// DeleteRecording is the resolver for the deleteRecording field.
+func (r *mutationResolver) DeleteRecording(ctx context.Context, id string) (*model.Recording, error) {
+	var deletedRecording *model.Recording
+	err := r.Store.Transaction(ctx, func(tx store.DictionaryStore) error {
+		recording, err := findRecording(ctx, tx, id)
+		if recording == nil {
+			return err
+		}
+		deletedRecording = recording
+		return tx.DeleteRecording(ctx, recording)
+	})
+	if err != nil || deletedRecording == nil {
+		return nil, err
+	}
+	deleteAudio(ctx, r.Blobs, []*model.Recording{deletedRecording})
+	return deletedRecording, nil
+}
+
+// TagWords is the resolver for the tagWords field.
+func (r *mutationResolver) TagWords(ctx context.Context, wordIds []string, tags []string) ([]*model.Word, error) {
+	names, err := tagNames(tags)
+	if err != nil {
+		return nil, err
+	}
+
+	var words []*model.Word
+	err = r.Store.Transaction(ctx, func(tx store.DictionaryStore) error {
+		var err error
+		words, err = existingWords(ctx, tx, wordIds)
+		if err != nil {
+			return err
+		}
+		return tx.TagWords(ctx, wordIDsOf(words), names)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return words, nil
+}
+
+// UntagWords is the resolver for the untagWords field.
+func (r *mutationResolver) UntagWords(ctx context.Context, wordIds []string, tags []string) ([]*model.Word, error) {
+	names, err := tagNames(tags)
+	if err != nil {
+		return nil, err
+	}
+
+	var words []*model.Word
+	err = r.Store.Transaction(ctx, func(tx store.DictionaryStore) error {
+		var err error
+		words, err = existingWords(ctx, tx, wordIds)
+		if err != nil {
+			return err
+		}
+		return tx.UntagWords(ctx, wordIDsOf(words), names)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return words, nil
+}
+
+// DeleteTag is the resolver for the deleteTag field.
+func (r *mutationResolver) DeleteTag(ctx context.Context, id string) (*model.Tag, error) {
+	var deletedTag *model.Tag
+	err := r.Store.Transaction(ctx, func(tx store.DictionaryStore) error {
+		tag, err := findTag(ctx, tx, id)
+		if tag == nil {
+			return err
+		}
+		deletedTag = tag
+		return tx.DeleteTag(ctx, tag)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deletedTag, nil
+}
+
+// AddCollection is the resolver for the addCollection field.
+func (r *mutationResolver) AddCollection(ctx context.Context, input model.CollectionInput) (*model.Collection, error) {
+	collection := &model.Collection{}
+	if err := applyCollectionChange(collection, &input.Name, input.Description); err != nil {
+		return nil, err
+	}
+
+	err := r.Store.Transaction(ctx, func(tx store.DictionaryStore) error {
+		return tx.AddCollection(ctx, collection)
+	})
+	if errors.Is(err, store.ErrConflict) {
+		return nil, conflict("collection %q exists already", collection.Name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return collection, nil
+}
+
+// UpdateCollection is the resolver for the updateCollection field.
+func (r *mutationResolver) UpdateCollection(ctx context.Context, id string, input model.UpdateCollectionInput) (*model.Collection, error) {
+	var collection *model.Collection
+	err := r.Store.Transaction(ctx, func(tx store.DictionaryStore) error {
+		var err error
+		collection, err = findCollection(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if collection == nil {
+			return fmt.Errorf("collection %s is missing in database: %w", id, store.ErrNotFound)
+		}
+		if err := applyCollectionChange(collection, input.Name, input.Description); err != nil {
+			return err
+		}
+		return tx.UpdateCollection(ctx, collection)
+	})
+	if errors.Is(err, store.ErrConflict) {
+		return nil, conflict("collection %q exists already", collection.Name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return collection, nil
+}
+
+// DeleteCollection is the resolver for the deleteCollection field.
+func (r *mutationResolver) DeleteCollection(ctx context.Context, id string) (*model.Collection, error) {
+	var deletedCollection *model.Collection
+	err := r.Store.Transaction(ctx, func(tx store.DictionaryStore) error {
+		collection, err := findCollection(ctx, tx, id)
+		if collection == nil {
+			return err
+		}
+		deletedCollection = collection
+		return tx.DeleteCollection(ctx, collection)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deletedCollection, nil
+}
+
+// AddToCollection is the resolver for the addToCollection field.
+func (r *mutationResolver) AddToCollection(ctx context.Context, collectionID string, wordIds []string) (*model.Collection, error) {
+	var collection *model.Collection
+	err := r.Store.Transaction(ctx, func(tx store.DictionaryStore) error {
+		var err error
+		collection, err = findCollection(ctx, tx, collectionID)
+		if err != nil {
+			return err
+		}
+		if collection == nil {
+			return fmt.Errorf("collection %s is missing in database: %w", collectionID, store.ErrNotFound)
+		}
+		words, err := existingWords(ctx, tx, wordIds)
+		if err != nil {
+			return err
+		}
+		return tx.AddToCollection(ctx, collection.ID, wordIDsOf(words))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return collection, nil
+}
+
+// RemoveFromCollection is the resolver for the removeFromCollection field.
+func (r *mutationResolver) RemoveFromCollection(ctx context.Context, collectionID string, wordIds []string) (*model.Collection, error) {
+	var collection *model.Collection
+	err := r.Store.Transaction(ctx, func(tx store.DictionaryStore) error {
+		var err error
+		collection, err = findCollection(ctx, tx, collectionID)
+		if err != nil {
+			return err
+		}
+		if collection == nil {
+			return fmt.Errorf("collection %s is missing in database: %w", collectionID, store.ErrNotFound)
+		}
+		words, err := existingWords(ctx, tx, wordIds)
+		if err != nil {
+			return err
+		}
+		return tx.RemoveFromCollection(ctx, collection.ID, wordIDsOf(words))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return collection, nil
+}
+
+// ID is the resolver for the id field.
+func (r *pronunciationResolver) ID(ctx context.Context, obj *model.Pronunciation) (string, error) {
+	return globalID(pronunciationTypename, obj.ID), nil
+}
+
+// Word is the resolver for the word field.
+func (r *pronunciationResolver) Word(ctx context.Context, obj *model.Pronunciation) (*model.Word, error) {
+	word, err := loadWord(ctx, r.Store, obj.WordID)
+	if err == nil && word == nil {
+		return nil, fmt.Errorf("word of pronunciation %d is missing in database: %w", obj.ID, store.ErrNotFound)
+	}
+	return word, err
+}
+
+// Node is the resolver for the node field.
+func (r *queryResolver) Node(ctx context.Context, id string) (model.Node, error) {
+	return node(ctx, r.Store, id)
+}
+
+// Word is the resolver for the word field.
+func (r *queryResolver) Word(ctx context.Context, id string) (*model.Word, error) {
+	wordID, err := decodeID(id, wordTypename)
+	if err != nil {
+		return nil, err
+	}
+	return loadWord(ctx, r.Store, wordID)
 }
 
 // GetTranslations is the resolver for the getTranslations field.
-func (r *queryResolver) GetTranslations(ctx context.Context, textToTranslate string, language string) ([]*model.Word, error) {
-	var word model.Word
+func (r *queryResolver) GetTranslations(ctx context.Context, textToTranslate string, language string, partOfSpeech *model.PartOfSpeech) ([]*model.Word, error) {
 	var translatedWords []*model.Word
-	var translations []*model.Translation
-	var translatedWordIDS []int
+	err := r.Store.Transaction(ctx, func(tx store.DictionaryStore) error {
+		key, err := store.NormalizeWord(ctx, tx, textToTranslate, language)
+		if err != nil {
+			return err
+		}
+		word, err := tx.FindWord(ctx, key.Text, key.Language)
+		if err != nil {
+			return wordNotFound(ctx, tx, key, err)
+		}
 
-	tx := r.DB.Begin()
-	defer func() {
-		tx.Rollback()
-	}()
+		if partOfSpeech != nil {
+			translatedWords, err = translationsInSenses(ctx, tx, word.ID, *partOfSpeech)
+			return err
+		}
+		translatedWords, err = tx.TranslationsOf(ctx, word.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	expectTranslations(ctx, translatedWords)
+	return translatedWords, nil
+}
+
+// TranslationsConnection is the resolver for the translationsConnection field.
+func (r *queryResolver) TranslationsConnection(ctx context.Context, textToTranslate string, language string, partOfSpeech *model.PartOfSpeech, first *int32, after *string, last *int32, before *string) (*model.WordConnection, error) {
+	page, err := pageArgs{first: first, after: after, last: last, before: before}.page()
+	if err != nil {
+		return nil, err
+	}
+
+	var words *store.WordPage
+	err = r.Store.Transaction(ctx, func(tx store.DictionaryStore) error {
+		key, err := store.NormalizeWord(ctx, tx, textToTranslate, language)
+		if err != nil {
+			return err
+		}
+		word, err := tx.FindWord(ctx, key.Text, key.Language)
+		if err != nil {
+			return wordNotFound(ctx, tx, key, err)
+		}
 
-	err := tx.Where("text = ? and language = ?", textToTranslate, language).First(&word).Error
+		filter := store.WordFilter{TranslationOf: word.ID}
+		if partOfSpeech != nil {
+			filter.PartOfSpeech = *partOfSpeech
+		}
+		words, err = tx.ListWords(ctx, filter, page)
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("give word is not in database: %w", err)
+		return nil, err
 	}
+	expectTranslations(ctx, words.Words)
+	return wordConnection(words), nil
+}
 
-	err = tx.Where("translation_id = ? or word_id = ?", word.ID, word.ID).Find(&translations).Error
+// Words is the resolver for the words field.
+func (r *queryResolver) Words(ctx context.Context, first *int32, after *string, last *int32, before *string, filter *model.WordFilter) (*model.WordConnection, error) {
+	page, err := pageArgs{first: first, after: after, last: last, before: before}.page()
 	if err != nil {
-		return nil, fmt.Errorf("no translations of given word were found: %w", err)
+		return nil, err
 	}
 
-	for _, t := range translations {
-		if word.ID == t.WordID {
-			translatedWordIDS = append(translatedWordIDS, t.TranslationID)
-		} else {
-			translatedWordIDS = append(translatedWordIDS, t.WordID)
+	var words *store.WordPage
+	err = r.Store.Transaction(ctx, func(tx store.DictionaryStore) error {
+		var wordFilter store.WordFilter
+		if filter != nil {
+			code, caseSensitive := "und", false
+			if filter.Language != nil {
+				language, err := store.ResolveLanguage(ctx, tx, *filter.Language)
+				if err != nil {
+					return err
+				}
+				wordFilter.Language = language.Code
+				code, caseSensitive = language.Code, language.CaseSensitive
+			}
+			if filter.TextPrefix != nil {
+				wordFilter.TextPrefix = normalize.Key(*filter.TextPrefix, code, caseSensitive)
+			}
+			if filter.PartOfSpeech != nil {
+				wordFilter.PartOfSpeech = *filter.PartOfSpeech
+			}
+			tags, err := filterTagNames(filter.Tags)
+			if err != nil {
+				return err
+			}
+			wordFilter.Tags = tags
+			if filter.Collection != nil {
+				collection, err := findCollection(ctx, tx, *filter.Collection)
+				if err != nil {
+					return err
+				}
+				if collection == nil {
+					return fmt.Errorf("collection %s is missing in database: %w", *filter.Collection, store.ErrNotFound)
+				}
+				wordFilter.CollectionID = collection.ID
+			}
 		}
+
+		var err error
+		words, err = tx.ListWords(ctx, wordFilter, page)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	expectTranslations(ctx, words.Words)
+	return wordConnection(words), nil
+}
+
+// SuggestWords is the resolver for the suggestWords field.
+func (r *queryResolver) SuggestWords(ctx context.Context, text string, language string, maxDistance *int32, limit *int32) ([]*model.WordSuggestion, error) {
+	distance, size := defaultMaxDistance, defaultSuggestionLimit
+	if maxDistance != nil {
+		distance = int(*maxDistance)
+	}
+	if limit != nil {
+		size = int(*limit)
 	}
 
-	err = tx.Where("id in (?)", translatedWordIDS).Find(&translatedWords).Error
+	var suggestions []*model.WordSuggestion
+	err := r.Store.Transaction(ctx, func(tx store.DictionaryStore) error {
+		key, err := store.NormalizeWord(ctx, tx, text, language)
+		if err != nil {
+			return err
+		}
+		suggestions, err = suggestWords(ctx, tx, key.Text, key.Language, distance, size)
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("database error while searching translation: %w", err)
+		return nil, err
 	}
+	return suggestions, nil
+}
 
-	tx.Commit()
-	return translatedWords, nil
+// TranslateVia is the resolver for the translateVia field.
+func (r *queryResolver) TranslateVia(ctx context.Context, text string, language string, targetLanguage string, maxHops *int32) ([]*model.TranslationPath, error) {
+	hops := defaultMaxHops
+	if maxHops != nil {
+		hops = int(*maxHops)
+	}
+
+	var paths []*model.TranslationPath
+	err := r.Store.Transaction(ctx, func(tx store.DictionaryStore) error {
+		key, err := store.NormalizeWord(ctx, tx, text, language)
+		if err != nil {
+			return err
+		}
+		target, err := store.ResolveLanguage(ctx, tx, targetLanguage)
+		if err != nil {
+			return err
+		}
+		word, err := tx.FindWord(ctx, key.Text, key.Language)
+		if err != nil {
+			return wordNotFound(ctx, tx, key, err)
+		}
+
+		paths, err = translateVia(ctx, tx, word, target.Code, hops)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return paths, nil
+}
+
+// Languages is the resolver for the languages field.
+func (r *queryResolver) Languages(ctx context.Context) ([]*model.Language, error) {
+	var languages []*model.Language
+	err := r.Store.Transaction(ctx, func(tx store.DictionaryStore) error {
+		var err error
+		languages, err = tx.ListLanguages(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return languages, nil
+}
+
+// Tags is the resolver for the tags field.
+func (r *queryResolver) Tags(ctx context.Context) ([]*model.Tag, error) {
+	var tags []*model.Tag
+	err := r.Store.Transaction(ctx, func(tx store.DictionaryStore) error {
+		var err error
+		tags, err = tx.ListTags(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// Collections is the resolver for the collections field.
+func (r *queryResolver) Collections(ctx context.Context) ([]*model.Collection, error) {
+	var collections []*model.Collection
+	err := r.Store.Transaction(ctx, func(tx store.DictionaryStore) error {
+		var err error
+		collections, err = tx.ListCollections(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return collections, nil
 }
 
+// ID is the resolver for the id field.
+func (r *recordingResolver) ID(ctx context.Context, obj *model.Recording) (string, error) {
+	return globalID(recordingTypename, obj.ID), nil
+}
+
+// Word is the resolver for the word field.
+func (r *recordingResolver) Word(ctx context.Context, obj *model.Recording) (*model.Word, error) {
+	word, err := loadWord(ctx, r.Store, obj.WordID)
+	if err == nil && word == nil {
+		return nil, fmt.Errorf("word of recording %d is missing in database: %w", obj.ID, store.ErrNotFound)
+	}
+	return word, err
+}
+
+// Size is the resolver for the size field.
+func (r *recordingResolver) Size(ctx context.Context, obj *model.Recording) (int32, error) {
+	// Uploads are limited to maxRecordingSize, which fits Int.
+	return int32(obj.Size), nil
+}
+
+// URL is the resolver for the url field.
+func (r *recordingResolver) URL(ctx context.Context, obj *model.Recording) (string, error) {
+	return recordingURL(obj), nil
+}
+
+// ID is the resolver for the id field.
+func (r *senseResolver) ID(ctx context.Context, obj *model.Sense) (string, error) {
+	return globalID(senseTypename, obj.ID), nil
+}
+
+// Word is the resolver for the word field.
+func (r *senseResolver) Word(ctx context.Context, obj *model.Sense) (*model.Word, error) {
+	word, err := loadWord(ctx, r.Store, obj.WordID)
+	if err == nil && word == nil {
+		return nil, fmt.Errorf("word of sense %d is missing in database: %w", obj.ID, store.ErrNotFound)
+	}
+	return word, err
+}
+
+// PartOfSpeech is the resolver for the partOfSpeech field.
+func (r *senseResolver) PartOfSpeech(ctx context.Context, obj *model.Sense) (*model.PartOfSpeech, error) {
+	if obj.PartOfSpeech == "" {
+		return nil, nil
+	}
+	return &obj.PartOfSpeech, nil
+}
+
+// Translations is the resolver for the translations field.
+func (r *senseResolver) Translations(ctx context.Context, obj *model.Sense) ([]*model.Sense, error) {
+	return translatedSenses(ctx, r.Store, obj)
+}
+
+// Examples is the resolver for the examples field.
+func (r *senseResolver) Examples(ctx context.Context, obj *model.Sense) ([]*model.Example, error) {
+	return examplesOfSense(ctx, r.Store, obj)
+}
+
+// WordChanged is the resolver for the wordChanged field.
+func (r *subscriptionResolver) WordChanged(ctx context.Context, language *string) (<-chan *model.WordChange, error) {
+	code := ""
+	if language != nil {
+		resolved, err := loadLanguage(ctx, r.Store, *language)
+		if err != nil {
+			return nil, err
+		}
+		code = resolved.Code
+	}
+	return subscribe(ctx, r.Events, func(event events.Event) *model.WordChange {
+		if event.Word == nil || code != "" && event.Word.Language != code && event.PreviousLanguage != code {
+			return nil
+		}
+		return &model.WordChange{Kind: event.Kind, Word: event.Word}
+	})
+}
+
+// TranslationChanged is the resolver for the translationChanged field.
+func (r *subscriptionResolver) TranslationChanged(ctx context.Context, wordID *string) (<-chan *model.TranslationChange, error) {
+	id := 0
+	if wordID != nil {
+		var err error
+		if id, err = decodeID(*wordID, wordTypename); err != nil {
+			return nil, err
+		}
+	}
+	return subscribe(ctx, r.Events, func(event events.Event) *model.TranslationChange {
+		if event.Translation == nil || id != 0 && event.Translation.WordID != id && event.Translation.TranslationID != id {
+			return nil
+		}
+		return &model.TranslationChange{Kind: event.Kind, Translation: event.Translation}
+	})
+}
+
+// ID is the resolver for the id field.
+func (r *tagResolver) ID(ctx context.Context, obj *model.Tag) (string, error) {
+	return globalID(tagTypename, obj.ID), nil
+}
+
+// WordID is the resolver for the wordID field.
+func (r *translationResolver) WordID(ctx context.Context, obj *model.Translation) (string, error) {
+	return globalID(wordTypename, obj.WordID), nil
+}
+
+// TranslationID is the resolver for the translationID field.
+func (r *translationResolver) TranslationID(ctx context.Context, obj *model.Translation) (string, error) {
+	return globalID(wordTypename, obj.TranslationID), nil
+}
+
+// SenseID is the resolver for the senseID field.
+func (r *translationResolver) SenseID(ctx context.Context, obj *model.Translation) (*string, error) {
+	return senseGlobalID(obj.SenseID), nil
+}
+
+// TranslationSenseID is the resolver for the translationSenseID field.
+func (r *translationResolver) TranslationSenseID(ctx context.Context, obj *model.Translation) (*string, error) {
+	return senseGlobalID(obj.TranslationSenseID), nil
+}
+
+// ID is the resolver for the id field.
+func (r *wordResolver) ID(ctx context.Context, obj *model.Word) (string, error) {
+	return globalID(wordTypename, obj.ID), nil
+}
+
+// ExampleUsage is the resolver for the exampleUsage field.
+func (r *wordResolver) ExampleUsage(ctx context.Context, obj *model.Word) (string, error) {
+	examples, err := loadExamples(ctx, r.Store, obj.ID)
+	if err != nil {
+		return "", err
+	}
+	if usage := store.ExampleUsage(obj, examples); usage != nil {
+		return usage.Text, nil
+	}
+	return "", nil
+}
+
+// Senses is the resolver for the senses field.
+func (r *wordResolver) Senses(ctx context.Context, obj *model.Word) ([]*model.Sense, error) {
+	return loadSenses(ctx, r.Store, obj.ID)
+}
+
+// Examples is the resolver for the examples field.
+func (r *wordResolver) Examples(ctx context.Context, obj *model.Word) ([]*model.Example, error) {
+	return loadExamples(ctx, r.Store, obj.ID)
+}
+
+// Pronunciations is the resolver for the pronunciations field.
+func (r *wordResolver) Pronunciations(ctx context.Context, obj *model.Word) ([]*model.Pronunciation, error) {
+	return loadPronunciations(ctx, r.Store, obj.ID)
+}
+
+// Recordings is the resolver for the recordings field.
+func (r *wordResolver) Recordings(ctx context.Context, obj *model.Word) ([]*model.Recording, error) {
+	return loadRecordings(ctx, r.Store, obj.ID)
+}
+
+// Tags is the resolver for the tags field.
+func (r *wordResolver) Tags(ctx context.Context, obj *model.Word) ([]*model.Tag, error) {
+	return loadTags(ctx, r.Store, obj.ID)
+}
+
+// Collections is the resolver for the collections field.
+func (r *wordResolver) Collections(ctx context.Context, obj *model.Word) ([]*model.Collection, error) {
+	return loadCollections(ctx, r.Store, obj.ID)
+}
+
+// Translations is the resolver for the translations field.
+func (r *wordResolver) Translations(ctx context.Context, obj *model.Word, language *string, partOfSpeech *model.PartOfSpeech) ([]*model.Word, error) {
+	return translationsOf(ctx, r.Store, obj, language, partOfSpeech)
+}
+
+// Collection returns CollectionResolver implementation.
+func (r *Resolver) Collection() CollectionResolver { return &collectionResolver{r} }
+
+// Example returns ExampleResolver implementation.
+func (r *Resolver) Example() ExampleResolver { return &exampleResolver{r} }
+
 // Mutation returns MutationResolver implementation.
 func (r *Resolver) Mutation() MutationResolver { return &mutationResolver{r} }
 
+// Pronunciation returns PronunciationResolver implementation.
+func (r *Resolver) Pronunciation() PronunciationResolver { return &pronunciationResolver{r} }
+
 // Query returns QueryResolver implementation.
 func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
 
+// Recording returns RecordingResolver implementation.
+func (r *Resolver) Recording() RecordingResolver { return &recordingResolver{r} }
+
+// Sense returns SenseResolver implementation.
+func (r *Resolver) Sense() SenseResolver { return &senseResolver{r} }
+
+// Subscription returns SubscriptionResolver implementation.
+func (r *Resolver) Subscription() SubscriptionResolver { return &subscriptionResolver{r} }
+
+// Tag returns TagResolver implementation.
+func (r *Resolver) Tag() TagResolver { return &tagResolver{r} }
+
+// Translation returns TranslationResolver implementation.
+func (r *Resolver) Translation() TranslationResolver { return &translationResolver{r} }
+
+// Word returns WordResolver implementation.
+func (r *Resolver) Word() WordResolver { return &wordResolver{r} }
+
+type collectionResolver struct{ *Resolver }
+type exampleResolver struct{ *Resolver }
 type mutationResolver struct{ *Resolver }
+type pronunciationResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
+type recordingResolver struct{ *Resolver }
+type senseResolver struct{ *Resolver }
+type subscriptionResolver struct{ *Resolver }
+type tagResolver struct{ *Resolver }
+type translationResolver struct{ *Resolver }
+type wordResolver struct{ *Resolver }
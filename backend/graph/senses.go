@@ -0,0 +1,72 @@
+package graph
+
+import (
+	"backend/graph/model"
+	"backend/store"
+	"context"
+	"strings"
+)
+
+// applySenseInput sets the fields given in input on the sense, fields left out keep their value.
+func applySenseInput(sense *model.Sense, input model.SenseInput) {
+	if input.PartOfSpeech != nil {
+		sense.PartOfSpeech = *input.PartOfSpeech
+	}
+	if input.Gloss != nil {
+		sense.Gloss = strings.TrimSpace(*input.Gloss)
+	}
+	if input.Register != nil {
+		sense.Register = strings.TrimSpace(*input.Register)
+	}
+}
+
+// findSense returns the sense a global ID refers to, or nil when it does not exist.
+func findSense(ctx context.Context, s store.DictionaryStore, id string) (*model.Sense, error) {
+	senseID, err := decodeID(id, senseTypename)
+	if err != nil {
+		return nil, err
+	}
+	senses, err := s.FindSensesByIDs(ctx, []int{senseID})
+	if err != nil || len(senses) == 0 {
+		return nil, err
+	}
+	return senses[0], nil
+}
+
+// senseTranslation returns the translation between the senses global IDs refer to, nil when either sense
+// does not exist. Senses of a single word cannot be translations of each other.
+func senseTranslation(ctx context.Context, s store.DictionaryStore, senseID string, translatedSenseID string) (*model.Translation, error) {
+	sense, err := findSense(ctx, s, senseID)
+	if err != nil {
+		return nil, err
+	}
+	translatedSense, err := findSense(ctx, s, translatedSenseID)
+	if err != nil {
+		return nil, err
+	}
+	if sense == nil || translatedSense == nil {
+		return nil, nil
+	}
+	if sense.WordID == translatedSense.WordID {
+		return nil, invalidInput("senses of a single word cannot be translations of each other")
+	}
+
+	translation := model.Translation{
+		WordID:             sense.WordID,
+		TranslationID:      translatedSense.WordID,
+		SenseID:            sense.ID,
+		TranslationSenseID: translatedSense.ID,
+	}
+	translation.SortTranslation()
+	return &translation, nil
+}
+
+// senseGlobalID returns the global ID of the sense with given database ID, nil for zero IDs of translations
+// between words.
+func senseGlobalID(id int) *string {
+	if id == 0 {
+		return nil
+	}
+	encoded := globalID(senseTypename, id)
+	return &encoded
+}
@@ -0,0 +1,32 @@
+package graph
+
+import (
+	"backend/events"
+	"context"
+	"errors"
+)
+
+// subscribe streams the events of bus converted by convert until ctx is done, skipping those it returns nil for.
+// The stream ends early when the subscriber falls behind and is dropped by the bus, the client subscribes again.
+func subscribe[T any](ctx context.Context, bus *events.Bus, convert func(event events.Event) *T) (<-chan *T, error) {
+	if bus == nil {
+		return nil, errors.New("subscriptions are not enabled")
+	}
+	received := bus.Subscribe(ctx)
+	changes := make(chan *T, 1)
+	go func() {
+		defer close(changes)
+		for event := range received {
+			change := convert(event)
+			if change == nil {
+				continue
+			}
+			select {
+			case changes <- change:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return changes, nil
+}
@@ -0,0 +1,82 @@
+package graph
+
+import (
+	"backend/graph/model"
+	"backend/store"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/agnivade/levenshtein"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+const (
+	defaultMaxDistance     = 2
+	maxMaxDistance         = 5
+	defaultSuggestionLimit = 5
+	maxSuggestionLimit     = 50
+	// candidatesPerSuggestion is how many candidates are ranked by edit distance for every returned suggestion.
+	candidatesPerSuggestion = 10
+)
+
+// suggestWords returns words of the given language close to normalized text, ranked by Levenshtein distance.
+// Candidates are preselected by the store and ranked here.
+func suggestWords(ctx context.Context, s store.DictionaryStore, text string, language string, maxDistance int, limit int) ([]*model.WordSuggestion, error) {
+	if maxDistance < 0 || maxDistance > maxMaxDistance {
+		return nil, invalidInput("maxDistance must be between 0 and %d", maxMaxDistance)
+	}
+	if limit < 0 || limit > maxSuggestionLimit {
+		return nil, invalidInput("limit must be between 0 and %d", maxSuggestionLimit)
+	}
+	if text == "" || limit == 0 {
+		return []*model.WordSuggestion{}, nil
+	}
+
+	candidates, err := s.SimilarWords(ctx, text, language, maxDistance, limit*candidatesPerSuggestion)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := make([]*model.WordSuggestion, 0, len(candidates))
+	for _, candidate := range candidates {
+		distance := levenshtein.ComputeDistance(text, candidate.Text)
+		if distance <= maxDistance {
+			suggestions = append(suggestions, &model.WordSuggestion{Word: candidate, Distance: int32(distance)})
+		}
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		if suggestions[i].Distance != suggestions[j].Distance {
+			return suggestions[i].Distance < suggestions[j].Distance
+		}
+		return suggestions[i].Word.Text < suggestions[j].Word.Text
+	})
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions, nil
+}
+
+// wordNotFound builds the error returned when the word with normalized key is missing.
+// For a plain miss it lists similar words in the message and in the "suggestions" extension.
+func wordNotFound(ctx context.Context, s store.DictionaryStore, key model.Word, err error) error {
+	if !errors.Is(err, store.ErrNotFound) {
+		return err
+	}
+	suggestions, suggestErr := suggestWords(ctx, s, key.Text, key.Language, defaultMaxDistance, defaultSuggestionLimit)
+	if suggestErr != nil || len(suggestions) == 0 {
+		return fmt.Errorf("give word is not in database: %w", err)
+	}
+
+	texts := make([]string, len(suggestions))
+	for i, suggestion := range suggestions {
+		texts[i] = suggestion.Word.DisplayText
+	}
+	return &gqlerror.Error{
+		Err:        err,
+		Message:    fmt.Sprintf("give word is not in database, did you mean: %s?", strings.Join(texts, ", ")),
+		Extensions: map[string]interface{}{"suggestions": texts},
+	}
+}
@@ -0,0 +1,102 @@
+package graph
+
+import (
+	"backend/graph/model"
+	"backend/store"
+	"context"
+	"fmt"
+	"strings"
+)
+
+// tagNames validates the names of tags given in a mutation, returning them trimmed and lowercased
+// without repetitions.
+func tagNames(names []string) ([]string, error) {
+	if len(names) == 0 {
+		return nil, invalidInput("tags must not be empty")
+	}
+	seen := make(map[string]bool, len(names))
+	normalized := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			return nil, invalidInput("tag must not be empty")
+		}
+		if !seen[name] {
+			seen[name] = true
+			normalized = append(normalized, name)
+		}
+	}
+	return normalized, nil
+}
+
+// filterTagNames normalizes the tags of a word filter like tagNames, an empty list matches every word.
+func filterTagNames(names []string) ([]string, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	return tagNames(names)
+}
+
+// existingWords returns the words global IDs refer to, in the order of the IDs without repetitions.
+// Every word must exist.
+func existingWords(ctx context.Context, s store.DictionaryStore, ids []string) ([]*model.Word, error) {
+	if len(ids) > maxBatchSize {
+		return nil, invalidInput("words must not be more than %d", maxBatchSize)
+	}
+	wordIDs := make([]int, 0, len(ids))
+	seen := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		wordID, err := decodeID(id, wordTypename)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[wordID] {
+			seen[wordID] = true
+			wordIDs = append(wordIDs, wordID)
+		}
+	}
+
+	found, err := s.FindWordsByIDs(ctx, wordIDs)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int]*model.Word, len(found))
+	for _, word := range found {
+		byID[word.ID] = word
+	}
+	words := make([]*model.Word, len(wordIDs))
+	for i, wordID := range wordIDs {
+		if byID[wordID] == nil {
+			return nil, fmt.Errorf("word %s is missing in database: %w", globalID(wordTypename, wordID), store.ErrNotFound)
+		}
+		words[i] = byID[wordID]
+	}
+	return words, nil
+}
+
+// wordIDsOf returns the database IDs of the words.
+func wordIDsOf(words []*model.Word) []int {
+	ids := make([]int, len(words))
+	for i, word := range words {
+		ids[i] = word.ID
+	}
+	return ids
+}
+
+// tagByID returns the tag with given database ID, or nil when there is none.
+func tagByID(ctx context.Context, s store.DictionaryStore, id int) (*model.Tag, error) {
+	tags, err := s.FindTagsByIDs(ctx, []int{id})
+	if err != nil || len(tags) == 0 {
+		return nil, err
+	}
+	return tags[0], nil
+}
+
+// findTag returns the tag a global ID refers to, or nil when it does not exist.
+func findTag(ctx context.Context, s store.DictionaryStore, id string) (*model.Tag, error) {
+	tagID, err := decodeID(id, tagTypename)
+	if err != nil {
+		return nil, err
+	}
+	return tagByID(ctx, s, tagID)
+}
@@ -0,0 +1,42 @@
+package graph
+
+import (
+	"backend/graph/model"
+	"backend/store"
+	"context"
+	"fmt"
+)
+
+// findWord returns the word identified either by its ID or by its text and language.
+func findWord(ctx context.Context, s store.DictionaryStore, id *string, key *model.WordKeyInput) (*model.Word, error) {
+	if (id == nil) == (key == nil) {
+		return nil, invalidInput("either id or key must be given")
+	}
+	if id != nil {
+		wordID, err := decodeID(*id, wordTypename)
+		if err != nil {
+			return nil, err
+		}
+		word, err := wordByID(ctx, s, wordID)
+		if err != nil {
+			return nil, err
+		}
+		if word == nil {
+			return nil, fmt.Errorf("word %s is missing in database: %w", *id, store.ErrNotFound)
+		}
+		return word, nil
+	}
+
+	if key.Text == "" || key.Language == "" {
+		return nil, invalidInput("word and language must not be empty")
+	}
+	normalized, err := store.NormalizeWord(ctx, s, key.Text, key.Language)
+	if err != nil {
+		return nil, err
+	}
+	word, err := s.FindWord(ctx, normalized.Text, normalized.Language)
+	if err != nil {
+		return nil, fmt.Errorf("word is missing in database: %w", err)
+	}
+	return word, nil
+}
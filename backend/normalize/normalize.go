@@ -0,0 +1,26 @@
+// Package normalize turns user supplied text into the forms words are stored and looked up under.
+package normalize
+
+import (
+	"strings"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Display returns text in Unicode NFC, trimmed, with every run of inner whitespace replaced by a single space.
+// It is the form in which a word is shown to users.
+func Display(text string) string {
+	return strings.Join(strings.Fields(norm.NFC.String(text)), " ")
+}
+
+// Key returns the form of text which identifies a word within its language.
+// It is the display form, case folded with the rules of given language unless the language is case sensitive.
+func Key(text string, code string, caseSensitive bool) string {
+	display := Display(text)
+	if caseSensitive {
+		return display
+	}
+	return norm.NFC.String(cases.Lower(language.Make(code)).String(display))
+}
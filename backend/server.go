@@ -1,11 +1,19 @@
 package main
 
 import (
+	"backend/blob"
 	"backend/database"
+	"backend/events"
+	"backend/exchange"
 	"backend/graph"
+	"backend/graph/model"
+	"backend/store"
+	"context"
+	"flag"
 	"log"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/99designs/gqlgen/graphql/handler"
 	"github.com/99designs/gqlgen/graphql/handler/extension"
@@ -18,17 +26,33 @@ import (
 const defaultPort = "8080"
 
 func main() {
+	storeKind := flag.String("store", "database", `where the dictionary is kept, "database" configured by DB_DRIVER or "memory", which is lost on exit`)
+	flag.Parse()
+	if flag.NArg() > 0 {
+		runCommand(flag.Args())
+		return
+	}
+
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = defaultPort
 	}
-	db := database.Connect()
-	resolver := &graph.Resolver{DB: db}
+	bus := events.NewBus()
+	s := openStore(*storeKind, bus)
+	blobs, err := blob.FromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	resolver := &graph.Resolver{Store: s, Events: bus, Blobs: blobs}
 	srv := handler.New(graph.NewExecutableSchema(graph.Config{Resolvers: resolver}))
+	srv.SetErrorPresenter(graph.ErrorPresenter)
+	srv.AroundResponses(graph.LoaderMiddleware(s))
 
 	srv.AddTransport(transport.Options{})
 	srv.AddTransport(transport.GET{})
 	srv.AddTransport(transport.POST{})
+	srv.AddTransport(transport.MultipartForm{})
+	srv.AddTransport(transport.Websocket{KeepAlivePingInterval: 10 * time.Second})
 
 	srv.SetQueryCache(lru.New[*ast.QueryDocument](1000))
 
@@ -39,7 +63,40 @@ func main() {
 
 	http.Handle("/", playground.Handler("GraphQL playground", "/query"))
 	http.Handle("/query", srv)
+	http.Handle(graph.RecordingPattern, graph.RecordingHandler(s, blobs))
+	http.Handle("/export/tmx", exchange.ExportHandler(s, exchange.ExportTMX, "application/x-tmx+xml", "dictionary.tmx"))
+	http.Handle("/import/tmx", exchange.ImportHandler(s, model.TranslationFileFormatTmx))
+	http.Handle("/export/tbx", exchange.ExportHandler(s, exchange.ExportTBX, "application/x-tbx+xml", "dictionary.tbx"))
+	http.Handle("/import/tbx", exchange.ImportHandler(s, model.TranslationFileFormatTbx))
+	http.Handle("/export/anki", exchange.AnkiHandler(s))
+	http.Handle("/export/collection", exchange.CollectionHandler(s))
 
 	log.Printf("connect to http://localhost:%s/ for GraphQL playground", port)
 	log.Fatal(http.ListenAndServe(":"+port, nil))
 }
+
+// openStore opens the store of given kind, publishing its changes to bus. With Postgres, changes are
+// exchanged with the other replicas using the database too.
+func openStore(kind string, bus *events.Bus) store.DictionaryStore {
+	switch kind {
+	case "database":
+		db := database.Connect()
+		var publisher events.Publisher = bus
+		if db.Dialector.Name() == database.DriverPostgres {
+			postgres := events.NewPostgres(db, bus)
+			go postgres.Listen(context.Background())
+			publisher = postgres
+		}
+		return events.NewStore(store.NewGorm(db, database.StatementTimeout()), publisher)
+	case "memory":
+		s := store.NewMemory(database.StatementTimeout())
+		if err := database.SeedLanguages(context.Background(), s); err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("using in-memory store, data is lost on exit")
+		return events.NewStore(s, bus)
+	default:
+		log.Fatalf("unknown store %q, use database or memory", kind)
+		return nil
+	}
+}
@@ -0,0 +1,83 @@
+package store
+
+import (
+	"backend/graph/model"
+	"context"
+	"strings"
+)
+
+// ExampleUsage returns the example usage of the word among its examples: the first of them in the language
+// of the word, or nil when there is none.
+func ExampleUsage(word *model.Word, examples []*model.Example) *model.Example {
+	for _, example := range examples {
+		if example.Language == word.Language {
+			return example
+		}
+	}
+	return nil
+}
+
+// AddExampleTexts adds texts[i] as examples of words[i] in the language of the word, leaving out blank texts
+// and those the word has an example of already.
+func AddExampleTexts(ctx context.Context, s DictionaryStore, words []*model.Word, texts [][]string) error {
+	var ids []int
+	for i, word := range words {
+		if len(texts[i]) > 0 {
+			ids = append(ids, word.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	examples, err := s.ExamplesOf(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	for i, word := range words {
+		for _, text := range texts[i] {
+			text = strings.TrimSpace(text)
+			if text == "" || hasExample(examples[word.ID], text, word.Language) {
+				continue
+			}
+			example := &model.Example{WordID: word.ID, Text: text, Language: word.Language}
+			if err := s.AddExample(ctx, example); err != nil {
+				return err
+			}
+			// Words and texts given more than once are added once.
+			examples[word.ID] = append(examples[word.ID], example)
+		}
+	}
+	return nil
+}
+
+// SetExampleUsage replaces the text of the example usage of the word, adding one when the word has none.
+// A blank text deletes the example usage.
+func SetExampleUsage(ctx context.Context, s DictionaryStore, word *model.Word, text string) error {
+	examples, err := s.ExamplesOf(ctx, []int{word.ID})
+	if err != nil {
+		return err
+	}
+	text = strings.TrimSpace(text)
+	usage := ExampleUsage(word, examples[word.ID])
+	switch {
+	case usage == nil && text == "":
+		return nil
+	case usage == nil:
+		return s.AddExample(ctx, &model.Example{WordID: word.ID, Text: text, Language: word.Language})
+	case text == "":
+		return s.DeleteExample(ctx, usage)
+	default:
+		usage.Text = text
+		return s.UpdateExample(ctx, usage)
+	}
+}
+
+func hasExample(examples []*model.Example, text string, language string) bool {
+	for _, example := range examples {
+		if example.Text == text && example.Language == language {
+			return true
+		}
+	}
+	return false
+}
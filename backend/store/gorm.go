@@ -0,0 +1,1095 @@
+package store
+
+import (
+	"backend/graph/model"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// bulkBatchSize bounds the rows a single bulk statement reads or writes, keeping it within parameter limits.
+	bulkBatchSize = 500
+	// queryCanceledCode is the Postgres error code of a statement cancelled by statement_timeout.
+	queryCanceledCode = "57014"
+	// trigramThreshold is lower than the pg_trgm default of 0.3, so that typos in short words still match.
+	trigramThreshold = 0.2
+)
+
+// translationWalkQuery walks the translations graph from a single word, in both directions of every pair.
+// Words translated in several senses are connected once. A path is stored as comma separated word IDs,
+// e.g. ",1,5,9,", which keeps the query free of array types.
+// Words in the target language end a path, other words are used as pivots.
+const translationWalkQuery = `
+WITH RECURSIVE edges(source_id, target_id) AS (
+	SELECT word_id, translation_id FROM translations
+	UNION
+	SELECT translation_id, word_id FROM translations
+), walk(word_id, path, hops) AS (
+	SELECT CAST(? AS BIGINT), ',' || CAST(? AS TEXT) || ',', 0
+	UNION ALL
+	SELECT edges.target_id, walk.path || CAST(edges.target_id AS TEXT) || ',', walk.hops + 1
+	FROM walk
+	JOIN edges ON edges.source_id = walk.word_id
+	JOIN words AS pivot ON pivot.id = walk.word_id
+	WHERE walk.hops < ?
+		AND (walk.hops = 0 OR pivot.language <> ?)
+		AND walk.path NOT LIKE '%,' || CAST(edges.target_id AS TEXT) || ',%'
+)
+SELECT walk.word_id, walk.path, walk.hops
+FROM walk
+JOIN words ON words.id = walk.word_id
+WHERE walk.hops > 0 AND words.language = ?
+ORDER BY walk.hops, walk.path`
+
+// GormStore is the SQL implementation of DictionaryStore, for Postgres and SQLite databases.
+type GormStore struct {
+	db               *gorm.DB
+	statementTimeout time.Duration
+	// sqlite is set for SQLite databases, which lack the trigram index used for suggestions.
+	sqlite bool
+	// inTransaction is set for stores passed to Transaction callbacks, whose db is bound to the transaction context.
+	inTransaction bool
+}
+
+// NewGorm returns a store backed by db. Transactions are cancelled after statementTimeout, zero means no limit.
+func NewGorm(db *gorm.DB, statementTimeout time.Duration) *GormStore {
+	return &GormStore{db: db, statementTimeout: statementTimeout, sqlite: db.Dialector.Name() == "sqlite"}
+}
+
+// conn returns the connection to run a query on. Outside of a transaction it is bound to ctx,
+// inside one it stays bound to the context of the transaction.
+func (s *GormStore) conn(ctx context.Context) *gorm.DB {
+	if s.inTransaction {
+		return s.db
+	}
+	return s.db.WithContext(ctx)
+}
+
+func (s *GormStore) Transaction(ctx context.Context, fn func(tx DictionaryStore) error) error {
+	if s.inTransaction {
+		return fn(s)
+	}
+	if s.statementTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.statementTimeout)
+		defer cancel()
+	}
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&GormStore{db: tx, statementTimeout: s.statementTimeout, sqlite: s.sqlite, inTransaction: true})
+	})
+	if err != nil && (isTimeout(err) || errors.Is(ctx.Err(), context.DeadlineExceeded)) {
+		return fmt.Errorf("%w: %w", ErrTimeout, err)
+	}
+	return err
+}
+
+// isTimeout reports whether err was caused by a deadline, either of the request or of the database statement.
+func isTimeout(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == queryCanceledCode
+	}
+	return errors.Is(err, context.DeadlineExceeded) || pgconn.Timeout(err)
+}
+
+// wrap translates gorm errors to store errors, describing other errors with action.
+func wrap(err error, action string) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return fmt.Errorf("database error while %s: %w", action, ErrConflict)
+	}
+	return fmt.Errorf("database error while %s: %w", action, err)
+}
+
+func (s *GormStore) FindLanguage(ctx context.Context, code string) (*model.Language, error) {
+	var language model.Language
+	err := s.conn(ctx).First(&language, "code = ?", code).Error
+	if err != nil {
+		return nil, wrap(err, "searching language")
+	}
+	return &language, nil
+}
+
+// FindLanguageByName compares names in Go, SQLite only lowercases ASCII letters and languages are few.
+func (s *GormStore) FindLanguageByName(ctx context.Context, name string) (*model.Language, error) {
+	languages, err := s.ListLanguages(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, language := range languages {
+		if strings.EqualFold(language.Name, name) || strings.EqualFold(language.NativeName, name) {
+			return language, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (s *GormStore) ListLanguages(ctx context.Context) ([]*model.Language, error) {
+	var languages []*model.Language
+	err := s.conn(ctx).Order("code").Find(&languages).Error
+	if err != nil {
+		return nil, wrap(err, "listing languages")
+	}
+	return languages, nil
+}
+
+func (s *GormStore) AddLanguage(ctx context.Context, language *model.Language) (bool, error) {
+	result := s.conn(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(language)
+	if result.Error != nil {
+		return false, wrap(result.Error, "inserting language")
+	}
+	if result.RowsAffected > 0 {
+		return true, nil
+	}
+	err := s.conn(ctx).First(language, "code = ?", language.Code).Error
+	return false, wrap(err, "selecting language")
+}
+
+func (s *GormStore) FindWord(ctx context.Context, text string, language string) (*model.Word, error) {
+	var word model.Word
+	err := s.conn(ctx).Where("text = ? and language = ?", text, language).First(&word).Error
+	if err != nil {
+		return nil, wrap(err, "searching word")
+	}
+	return &word, nil
+}
+
+func (s *GormStore) FindWordsByIDs(ctx context.Context, ids []int) ([]*model.Word, error) {
+	var words []*model.Word
+	if len(ids) == 0 {
+		return words, nil
+	}
+	err := s.conn(ctx).Where("id in (?)", ids).Order("id").Find(&words).Error
+	if err != nil {
+		return nil, wrap(err, "searching words")
+	}
+	return words, nil
+}
+
+func (s *GormStore) FindOrCreateWord(ctx context.Context, word *model.Word) (bool, error) {
+	result := s.conn(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(word)
+	if result.Error != nil {
+		return false, wrap(result.Error, "inserting word")
+	}
+	if result.RowsAffected > 0 {
+		err := s.conn(ctx).Create(&model.Sense{WordID: word.ID}).Error
+		return true, wrap(err, "inserting default sense")
+	}
+	err := s.conn(ctx).First(word, "text = ? AND language = ?", word.Text, word.Language).Error
+	return false, wrap(err, "selecting word")
+}
+
+// FindOrCreateWords looks up the words with one query per batch and inserts the missing ones.
+// Inserted IDs are read back by text and language, as ON CONFLICT DO NOTHING returns no row for skipped words.
+func (s *GormStore) FindOrCreateWords(ctx context.Context, words []*model.Word) ([]bool, error) {
+	created := make([]bool, len(words))
+	found, err := s.findWordsByKeys(ctx, words)
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []*model.Word
+	missingKeys := make(map[wordKey]bool)
+	for i, word := range words {
+		key := wordKey{word.Text, word.Language}
+		if found[key] == nil && !missingKeys[key] {
+			missingKeys[key] = true
+			created[i] = true
+			insert := *word
+			insert.ID = 0
+			missing = append(missing, &insert)
+		}
+	}
+	if len(missing) > 0 {
+		err = s.conn(ctx).Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(missing, bulkBatchSize).Error
+		if err != nil {
+			return nil, wrap(err, "inserting words")
+		}
+		inserted, err := s.findWordsByKeys(ctx, missing)
+		if err != nil {
+			return nil, err
+		}
+		senses := make([]*model.Sense, 0, len(inserted))
+		for key, word := range inserted {
+			found[key] = word
+			senses = append(senses, &model.Sense{WordID: word.ID})
+		}
+		if len(senses) > 0 {
+			err = s.conn(ctx).Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(senses, bulkBatchSize).Error
+			if err != nil {
+				return nil, wrap(err, "inserting default senses")
+			}
+		}
+	}
+
+	for _, word := range words {
+		stored := found[wordKey{word.Text, word.Language}]
+		if stored == nil {
+			return nil, fmt.Errorf("database error while inserting words: %q in %q was not stored", word.Text, word.Language)
+		}
+		*word = *stored
+	}
+	return created, nil
+}
+
+func (s *GormStore) findWordsByKeys(ctx context.Context, words []*model.Word) (map[wordKey]*model.Word, error) {
+	found := make(map[wordKey]*model.Word, len(words))
+	for start := 0; start < len(words); start += bulkBatchSize {
+		batch := words[start:min(start+bulkBatchSize, len(words))]
+		keys := make([][]interface{}, len(batch))
+		for i, word := range batch {
+			keys[i] = []interface{}{word.Text, word.Language}
+		}
+		var stored []*model.Word
+		err := s.conn(ctx).Where("(text, language) IN ?", keys).Find(&stored).Error
+		if err != nil {
+			return nil, wrap(err, "searching words")
+		}
+		for _, word := range stored {
+			found[wordKey{word.Text, word.Language}] = word
+		}
+	}
+	return found, nil
+}
+
+func (s *GormStore) UpdateWord(ctx context.Context, word *model.Word) error {
+	return wrap(s.conn(ctx).Save(word).Error, "updating word")
+}
+
+func (s *GormStore) DeleteWord(ctx context.Context, word *model.Word) error {
+	err := s.conn(ctx).Where("word_id = ? OR translation_id = ?", word.ID, word.ID).Delete(&model.Translation{}).Error
+	if err != nil {
+		return wrap(err, "removing translations of word")
+	}
+	err = s.conn(ctx).Model(&model.Example{}).
+		Where("translated_example_id IN (SELECT id FROM examples WHERE word_id = ?)", word.ID).
+		Update("translated_example_id", nil).Error
+	if err != nil {
+		return wrap(err, "unlinking examples of word")
+	}
+	err = s.conn(ctx).Where("word_id = ?", word.ID).Delete(&model.Example{}).Error
+	if err != nil {
+		return wrap(err, "removing examples of word")
+	}
+	err = s.conn(ctx).Where("word_id = ?", word.ID).Delete(&model.Pronunciation{}).Error
+	if err != nil {
+		return wrap(err, "removing pronunciations of word")
+	}
+	err = s.conn(ctx).Where("word_id = ?", word.ID).Delete(&model.Recording{}).Error
+	if err != nil {
+		return wrap(err, "removing recordings of word")
+	}
+	err = s.conn(ctx).Where("word_id = ?", word.ID).Delete(&model.WordTag{}).Error
+	if err != nil {
+		return wrap(err, "removing tags of word")
+	}
+	err = s.conn(ctx).Where("word_id = ?", word.ID).Delete(&model.CollectionWord{}).Error
+	if err != nil {
+		return wrap(err, "removing word from collections")
+	}
+	err = s.conn(ctx).Where("word_id = ?", word.ID).Delete(&model.Sense{}).Error
+	if err != nil {
+		return wrap(err, "removing senses of word")
+	}
+	return wrap(s.conn(ctx).Delete(word).Error, "removing word")
+}
+
+func (s *GormStore) ListWords(ctx context.Context, filter WordFilter, page Page) (*WordPage, error) {
+	words := func() *gorm.DB {
+		query := s.conn(ctx).Model(&model.Word{})
+		if filter.Language != "" {
+			query = query.Where("language = ?", filter.Language)
+		}
+		if filter.TextPrefix != "" {
+			query = query.Where(`text LIKE ? ESCAPE '\'`, escapeLike(filter.TextPrefix)+"%")
+		}
+		switch {
+		case filter.TranslationOf != 0 && filter.PartOfSpeech != "":
+			query = query.Where(`id IN (
+				SELECT translations.translation_id FROM translations JOIN senses ON senses.id = translations.sense_id
+				WHERE translations.word_id = ? AND senses.part_of_speech = ?
+				UNION
+				SELECT translations.word_id FROM translations JOIN senses ON senses.id = translations.translation_sense_id
+				WHERE translations.translation_id = ? AND senses.part_of_speech = ?)`,
+				filter.TranslationOf, filter.PartOfSpeech, filter.TranslationOf, filter.PartOfSpeech)
+		case filter.TranslationOf != 0:
+			query = query.Where("id IN (SELECT translation_id FROM translations WHERE word_id = ? UNION SELECT word_id FROM translations WHERE translation_id = ?)", filter.TranslationOf, filter.TranslationOf)
+		case filter.PartOfSpeech != "":
+			query = query.Where("id IN (SELECT word_id FROM senses WHERE part_of_speech = ?)", filter.PartOfSpeech)
+		}
+		if len(filter.Tags) > 0 {
+			names := uniqueStrings(filter.Tags)
+			query = query.Where(`id IN (
+				SELECT word_tags.word_id FROM word_tags JOIN tags ON tags.id = word_tags.tag_id
+				WHERE tags.name IN (?) GROUP BY word_tags.word_id HAVING COUNT(*) = ?)`, names, len(names))
+		}
+		if filter.CollectionID != 0 {
+			query = query.Where("id IN (SELECT word_id FROM collection_words WHERE collection_id = ?)", filter.CollectionID)
+		}
+		return query
+	}
+
+	var total int64
+	err := words().Count(&total).Error
+	if err != nil {
+		return nil, wrap(err, "counting words")
+	}
+
+	query := words()
+	if page.AfterID != 0 {
+		query = query.Where("id > ?", page.AfterID)
+	}
+	if page.BeforeID != 0 {
+		query = query.Where("id < ?", page.BeforeID)
+	}
+	order := "id"
+	if page.Backward {
+		order = "id desc"
+	}
+	result := &WordPage{Total: int(total)}
+	err = query.Order(order).Limit(page.Limit + 1).Find(&result.Words).Error
+	if err != nil {
+		return nil, wrap(err, "listing words")
+	}
+	hasMore := len(result.Words) > page.Limit
+	if hasMore {
+		result.Words = result.Words[:page.Limit]
+	}
+
+	if page.Backward {
+		for i, j := 0, len(result.Words)-1; i < j; i, j = i+1, j-1 {
+			result.Words[i], result.Words[j] = result.Words[j], result.Words[i]
+		}
+		result.HasPreviousPage = hasMore
+		if page.BeforeID != 0 {
+			result.HasNextPage, err = exists(words().Where("id >= ?", page.BeforeID))
+		}
+	} else {
+		result.HasNextPage = hasMore
+		if page.AfterID != 0 {
+			result.HasPreviousPage, err = exists(words().Where("id <= ?", page.AfterID))
+		}
+	}
+	if err != nil {
+		return nil, wrap(err, "checking page bounds")
+	}
+	return result, nil
+}
+
+func exists(query *gorm.DB) (bool, error) {
+	var ids []int
+	err := query.Limit(1).Pluck("id", &ids).Error
+	return len(ids) > 0, err
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// SimilarWords preselects candidates with the trigram index on words.text.
+// SQLite has no such index, so there all words of similar length are ranked by edit distance.
+func (s *GormStore) SimilarWords(ctx context.Context, text string, language string, maxDistance int, limit int) ([]*model.Word, error) {
+	length := len([]rune(text))
+	if s.sqlite {
+		var words []*model.Word
+		err := s.conn(ctx).Where("language = ?", language).
+			Where("length(text) BETWEEN ? AND ?", length-maxDistance, length+maxDistance).
+			Order("id").
+			Find(&words).Error
+		if err != nil {
+			return nil, wrap(err, "searching similar words")
+		}
+		return closestWords(text, words, maxDistance, limit), nil
+	}
+
+	err := s.conn(ctx).Exec(fmt.Sprintf("SET LOCAL pg_trgm.similarity_threshold = %v", trigramThreshold)).Error
+	if err != nil {
+		return nil, wrap(err, "preparing suggestions")
+	}
+
+	var candidates []*model.Word
+	err = s.conn(ctx).Where("language = ? AND text % ?", language, text).
+		Where("char_length(text) BETWEEN ? AND ?", length-maxDistance, length+maxDistance).
+		Order(gorm.Expr("similarity(text, ?) DESC", text)).
+		Limit(limit).
+		Find(&candidates).Error
+	if err != nil {
+		return nil, wrap(err, "searching similar words")
+	}
+	return candidates, nil
+}
+
+func (s *GormStore) FindSensesByIDs(ctx context.Context, ids []int) ([]*model.Sense, error) {
+	var senses []*model.Sense
+	if len(ids) == 0 {
+		return senses, nil
+	}
+	err := s.conn(ctx).Where("id IN (?)", ids).Order("id").Find(&senses).Error
+	if err != nil {
+		return nil, wrap(err, "searching senses")
+	}
+	return senses, nil
+}
+
+func (s *GormStore) SensesOf(ctx context.Context, wordIDs []int) (map[int][]*model.Sense, error) {
+	senses := make(map[int][]*model.Sense, len(wordIDs))
+	for _, id := range wordIDs {
+		senses[id] = []*model.Sense{}
+	}
+	for start := 0; start < len(wordIDs); start += bulkBatchSize {
+		var found []*model.Sense
+		err := s.conn(ctx).Where("word_id IN (?)", wordIDs[start:min(start+bulkBatchSize, len(wordIDs))]).Order("id").Find(&found).Error
+		if err != nil {
+			return nil, wrap(err, "searching senses of words")
+		}
+		for _, sense := range found {
+			senses[sense.WordID] = append(senses[sense.WordID], sense)
+		}
+	}
+	return senses, nil
+}
+
+func (s *GormStore) FindOrCreateSense(ctx context.Context, sense *model.Sense) (bool, error) {
+	result := s.conn(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(sense)
+	if result.Error != nil {
+		return false, wrap(result.Error, "inserting sense")
+	}
+	if result.RowsAffected > 0 {
+		return true, nil
+	}
+	err := s.conn(ctx).First(sense, "word_id = ? AND part_of_speech = ? AND gloss = ?", sense.WordID, sense.PartOfSpeech, sense.Gloss).Error
+	return false, wrap(err, "selecting sense")
+}
+
+func (s *GormStore) UpdateSense(ctx context.Context, sense *model.Sense) error {
+	return wrap(s.conn(ctx).Save(sense).Error, "updating sense")
+}
+
+func (s *GormStore) DeleteSense(ctx context.Context, sense *model.Sense) error {
+	err := s.conn(ctx).Where("sense_id = ? OR translation_sense_id = ?", sense.ID, sense.ID).Delete(&model.Translation{}).Error
+	if err != nil {
+		return wrap(err, "removing translations of sense")
+	}
+	err = s.conn(ctx).Model(&model.Example{}).Where("sense_id = ?", sense.ID).Update("sense_id", nil).Error
+	if err != nil {
+		return wrap(err, "detaching examples of sense")
+	}
+	return wrap(s.conn(ctx).Delete(sense).Error, "removing sense")
+}
+
+func (s *GormStore) FindExamplesByIDs(ctx context.Context, ids []int) ([]*model.Example, error) {
+	var examples []*model.Example
+	if len(ids) == 0 {
+		return examples, nil
+	}
+	err := s.conn(ctx).Where("id IN (?)", ids).Order("id").Find(&examples).Error
+	if err != nil {
+		return nil, wrap(err, "searching examples")
+	}
+	return examples, nil
+}
+
+func (s *GormStore) ExamplesOf(ctx context.Context, wordIDs []int) (map[int][]*model.Example, error) {
+	examples := make(map[int][]*model.Example, len(wordIDs))
+	for _, id := range wordIDs {
+		examples[id] = []*model.Example{}
+	}
+	for start := 0; start < len(wordIDs); start += bulkBatchSize {
+		var found []*model.Example
+		err := s.conn(ctx).Where("word_id IN (?)", wordIDs[start:min(start+bulkBatchSize, len(wordIDs))]).Order("id").Find(&found).Error
+		if err != nil {
+			return nil, wrap(err, "searching examples of words")
+		}
+		for _, example := range found {
+			examples[example.WordID] = append(examples[example.WordID], example)
+		}
+	}
+	return examples, nil
+}
+
+func (s *GormStore) AddExample(ctx context.Context, example *model.Example) error {
+	example.ID = 0
+	if err := s.conn(ctx).Create(example).Error; err != nil {
+		return wrap(err, "inserting example")
+	}
+	return s.linkExample(ctx, example)
+}
+
+func (s *GormStore) UpdateExample(ctx context.Context, example *model.Example) error {
+	if err := s.conn(ctx).Save(example).Error; err != nil {
+		return wrap(err, "updating example")
+	}
+	return s.linkExample(ctx, example)
+}
+
+// linkExample links the translated example of the stored example back to it, unlinking examples
+// previously linked to either of the two.
+func (s *GormStore) linkExample(ctx context.Context, example *model.Example) error {
+	linked := []int{example.ID}
+	if example.TranslatedExampleID != nil {
+		linked = append(linked, *example.TranslatedExampleID)
+	}
+	err := s.conn(ctx).Model(&model.Example{}).
+		Where("translated_example_id IN (?) AND id NOT IN (?)", linked, linked).
+		Update("translated_example_id", nil).Error
+	if err != nil {
+		return wrap(err, "unlinking examples")
+	}
+	if example.TranslatedExampleID == nil {
+		return nil
+	}
+	err = s.conn(ctx).Model(&model.Example{}).Where("id = ?", *example.TranslatedExampleID).
+		Update("translated_example_id", example.ID).Error
+	return wrap(err, "linking examples")
+}
+
+func (s *GormStore) DeleteExample(ctx context.Context, example *model.Example) error {
+	err := s.conn(ctx).Model(&model.Example{}).Where("translated_example_id = ?", example.ID).
+		Update("translated_example_id", nil).Error
+	if err != nil {
+		return wrap(err, "unlinking example")
+	}
+	return wrap(s.conn(ctx).Delete(example).Error, "removing example")
+}
+
+func (s *GormStore) FindPronunciationsByIDs(ctx context.Context, ids []int) ([]*model.Pronunciation, error) {
+	var pronunciations []*model.Pronunciation
+	if len(ids) == 0 {
+		return pronunciations, nil
+	}
+	err := s.conn(ctx).Where("id IN (?)", ids).Order("id").Find(&pronunciations).Error
+	if err != nil {
+		return nil, wrap(err, "searching pronunciations")
+	}
+	return pronunciations, nil
+}
+
+func (s *GormStore) PronunciationsOf(ctx context.Context, wordIDs []int) (map[int][]*model.Pronunciation, error) {
+	pronunciations := make(map[int][]*model.Pronunciation, len(wordIDs))
+	for _, id := range wordIDs {
+		pronunciations[id] = []*model.Pronunciation{}
+	}
+	for start := 0; start < len(wordIDs); start += bulkBatchSize {
+		var found []*model.Pronunciation
+		err := s.conn(ctx).Where("word_id IN (?)", wordIDs[start:min(start+bulkBatchSize, len(wordIDs))]).Order("id").Find(&found).Error
+		if err != nil {
+			return nil, wrap(err, "searching pronunciations of words")
+		}
+		for _, pronunciation := range found {
+			pronunciations[pronunciation.WordID] = append(pronunciations[pronunciation.WordID], pronunciation)
+		}
+	}
+	return pronunciations, nil
+}
+
+func (s *GormStore) FindOrCreatePronunciation(ctx context.Context, pronunciation *model.Pronunciation) (bool, error) {
+	result := s.conn(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(pronunciation)
+	if result.Error != nil {
+		return false, wrap(result.Error, "inserting pronunciation")
+	}
+	if result.RowsAffected > 0 {
+		return true, nil
+	}
+	err := s.conn(ctx).First(pronunciation, "word_id = ? AND ipa = ? AND accent = ?", pronunciation.WordID, pronunciation.IPA, pronunciation.Accent).Error
+	return false, wrap(err, "selecting pronunciation")
+}
+
+func (s *GormStore) UpdatePronunciation(ctx context.Context, pronunciation *model.Pronunciation) error {
+	return wrap(s.conn(ctx).Save(pronunciation).Error, "updating pronunciation")
+}
+
+func (s *GormStore) DeletePronunciation(ctx context.Context, pronunciation *model.Pronunciation) error {
+	return wrap(s.conn(ctx).Delete(pronunciation).Error, "removing pronunciation")
+}
+
+func (s *GormStore) FindRecordingsByIDs(ctx context.Context, ids []int) ([]*model.Recording, error) {
+	var recordings []*model.Recording
+	if len(ids) == 0 {
+		return recordings, nil
+	}
+	err := s.conn(ctx).Where("id IN (?)", ids).Order("id").Find(&recordings).Error
+	if err != nil {
+		return nil, wrap(err, "searching recordings")
+	}
+	return recordings, nil
+}
+
+func (s *GormStore) RecordingsOf(ctx context.Context, wordIDs []int) (map[int][]*model.Recording, error) {
+	recordings := make(map[int][]*model.Recording, len(wordIDs))
+	for _, id := range wordIDs {
+		recordings[id] = []*model.Recording{}
+	}
+	for start := 0; start < len(wordIDs); start += bulkBatchSize {
+		var found []*model.Recording
+		err := s.conn(ctx).Where("word_id IN (?)", wordIDs[start:min(start+bulkBatchSize, len(wordIDs))]).Order("id").Find(&found).Error
+		if err != nil {
+			return nil, wrap(err, "searching recordings of words")
+		}
+		for _, recording := range found {
+			recordings[recording.WordID] = append(recordings[recording.WordID], recording)
+		}
+	}
+	return recordings, nil
+}
+
+func (s *GormStore) AddRecording(ctx context.Context, recording *model.Recording) error {
+	recording.ID = 0
+	return wrap(s.conn(ctx).Create(recording).Error, "inserting recording")
+}
+
+func (s *GormStore) DeleteRecording(ctx context.Context, recording *model.Recording) error {
+	return wrap(s.conn(ctx).Delete(recording).Error, "removing recording")
+}
+
+func (s *GormStore) ListTags(ctx context.Context) ([]*model.Tag, error) {
+	var tags []*model.Tag
+	err := s.conn(ctx).Order("name").Find(&tags).Error
+	if err != nil {
+		return nil, wrap(err, "listing tags")
+	}
+	return tags, nil
+}
+
+func (s *GormStore) FindTagsByIDs(ctx context.Context, ids []int) ([]*model.Tag, error) {
+	var tags []*model.Tag
+	if len(ids) == 0 {
+		return tags, nil
+	}
+	err := s.conn(ctx).Where("id IN (?)", ids).Order("name").Find(&tags).Error
+	if err != nil {
+		return nil, wrap(err, "searching tags")
+	}
+	return tags, nil
+}
+
+func (s *GormStore) TagsOf(ctx context.Context, wordIDs []int) (map[int][]*model.Tag, error) {
+	tags := make(map[int][]*model.Tag, len(wordIDs))
+	for _, id := range wordIDs {
+		tags[id] = []*model.Tag{}
+	}
+	for start := 0; start < len(wordIDs); start += bulkBatchSize {
+		var found []struct {
+			model.Tag
+			WordID int
+		}
+		err := s.conn(ctx).Table("tags").Select("tags.id, tags.name, word_tags.word_id").
+			Joins("JOIN word_tags ON word_tags.tag_id = tags.id").
+			Where("word_tags.word_id IN (?)", wordIDs[start:min(start+bulkBatchSize, len(wordIDs))]).
+			Order("tags.name").Scan(&found).Error
+		if err != nil {
+			return nil, wrap(err, "searching tags of words")
+		}
+		for _, tag := range found {
+			tags[tag.WordID] = append(tags[tag.WordID], &model.Tag{ID: tag.ID, Name: tag.Name})
+		}
+	}
+	return tags, nil
+}
+
+func (s *GormStore) TagWords(ctx context.Context, wordIDs []int, names []string) error {
+	if len(wordIDs) == 0 || len(names) == 0 {
+		return nil
+	}
+	names = uniqueStrings(names)
+	tags := make([]model.Tag, len(names))
+	for i, name := range names {
+		tags[i] = model.Tag{Name: name}
+	}
+	err := s.conn(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&tags).Error
+	if err != nil {
+		return wrap(err, "inserting tags")
+	}
+	var tagIDs []int
+	err = s.conn(ctx).Model(&model.Tag{}).Where("name IN (?)", names).Pluck("id", &tagIDs).Error
+	if err != nil {
+		return wrap(err, "searching tags")
+	}
+
+	links := make([]model.WordTag, 0, len(wordIDs)*len(tagIDs))
+	for _, wordID := range wordIDs {
+		for _, tagID := range tagIDs {
+			links = append(links, model.WordTag{WordID: wordID, TagID: tagID})
+		}
+	}
+	err = s.conn(ctx).Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(links, bulkBatchSize).Error
+	return wrap(err, "tagging words")
+}
+
+func (s *GormStore) UntagWords(ctx context.Context, wordIDs []int, names []string) error {
+	if len(wordIDs) == 0 || len(names) == 0 {
+		return nil
+	}
+	for start := 0; start < len(wordIDs); start += bulkBatchSize {
+		err := s.conn(ctx).Where("word_id IN (?) AND tag_id IN (SELECT id FROM tags WHERE name IN (?))",
+			wordIDs[start:min(start+bulkBatchSize, len(wordIDs))], names).Delete(&model.WordTag{}).Error
+		if err != nil {
+			return wrap(err, "untagging words")
+		}
+	}
+	return nil
+}
+
+func (s *GormStore) DeleteTag(ctx context.Context, tag *model.Tag) error {
+	err := s.conn(ctx).Where("tag_id = ?", tag.ID).Delete(&model.WordTag{}).Error
+	if err != nil {
+		return wrap(err, "removing tag from words")
+	}
+	return wrap(s.conn(ctx).Delete(tag).Error, "removing tag")
+}
+
+func (s *GormStore) FindCollection(ctx context.Context, name string) (*model.Collection, error) {
+	var collection model.Collection
+	err := s.conn(ctx).First(&collection, "name = ?", name).Error
+	if err != nil {
+		return nil, wrap(err, "searching collection")
+	}
+	return &collection, nil
+}
+
+func (s *GormStore) FindCollectionsByIDs(ctx context.Context, ids []int) ([]*model.Collection, error) {
+	var collections []*model.Collection
+	if len(ids) == 0 {
+		return collections, nil
+	}
+	err := s.conn(ctx).Where("id IN (?)", ids).Order("name").Find(&collections).Error
+	if err != nil {
+		return nil, wrap(err, "searching collections")
+	}
+	return collections, nil
+}
+
+func (s *GormStore) ListCollections(ctx context.Context) ([]*model.Collection, error) {
+	var collections []*model.Collection
+	err := s.conn(ctx).Order("name").Find(&collections).Error
+	if err != nil {
+		return nil, wrap(err, "listing collections")
+	}
+	return collections, nil
+}
+
+func (s *GormStore) CollectionsOf(ctx context.Context, wordIDs []int) (map[int][]*model.Collection, error) {
+	collections := make(map[int][]*model.Collection, len(wordIDs))
+	for _, id := range wordIDs {
+		collections[id] = []*model.Collection{}
+	}
+	for start := 0; start < len(wordIDs); start += bulkBatchSize {
+		var found []struct {
+			model.Collection
+			WordID int
+		}
+		err := s.conn(ctx).Table("collections").Select("collections.id, collections.name, collections.description, collection_words.word_id").
+			Joins("JOIN collection_words ON collection_words.collection_id = collections.id").
+			Where("collection_words.word_id IN (?)", wordIDs[start:min(start+bulkBatchSize, len(wordIDs))]).
+			Order("collections.name").Scan(&found).Error
+		if err != nil {
+			return nil, wrap(err, "searching collections of words")
+		}
+		for _, collection := range found {
+			c := collection.Collection
+			collections[collection.WordID] = append(collections[collection.WordID], &c)
+		}
+	}
+	return collections, nil
+}
+
+func (s *GormStore) AddCollection(ctx context.Context, collection *model.Collection) error {
+	collection.ID = 0
+	return wrap(s.conn(ctx).Create(collection).Error, "inserting collection")
+}
+
+func (s *GormStore) UpdateCollection(ctx context.Context, collection *model.Collection) error {
+	return wrap(s.conn(ctx).Save(collection).Error, "updating collection")
+}
+
+func (s *GormStore) DeleteCollection(ctx context.Context, collection *model.Collection) error {
+	err := s.conn(ctx).Where("collection_id = ?", collection.ID).Delete(&model.CollectionWord{}).Error
+	if err != nil {
+		return wrap(err, "removing words from collection")
+	}
+	return wrap(s.conn(ctx).Delete(collection).Error, "removing collection")
+}
+
+func (s *GormStore) AddToCollection(ctx context.Context, collectionID int, wordIDs []int) error {
+	if len(wordIDs) == 0 {
+		return nil
+	}
+	members := make([]model.CollectionWord, len(wordIDs))
+	for i, wordID := range wordIDs {
+		members[i] = model.CollectionWord{CollectionID: collectionID, WordID: wordID}
+	}
+	err := s.conn(ctx).Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(members, bulkBatchSize).Error
+	return wrap(err, "adding words to collection")
+}
+
+func (s *GormStore) RemoveFromCollection(ctx context.Context, collectionID int, wordIDs []int) error {
+	for start := 0; start < len(wordIDs); start += bulkBatchSize {
+		err := s.conn(ctx).Where("collection_id = ? AND word_id IN (?)", collectionID, wordIDs[start:min(start+bulkBatchSize, len(wordIDs))]).
+			Delete(&model.CollectionWord{}).Error
+		if err != nil {
+			return wrap(err, "removing words from collection")
+		}
+	}
+	return nil
+}
+
+// defaultSenses returns the IDs of the default senses of the words by word ID, creating the missing ones.
+func (s *GormStore) defaultSenses(ctx context.Context, wordIDs []int) (map[int]int, error) {
+	ids := make(map[int]int, len(wordIDs))
+	find := func(wordIDs []int) error {
+		for start := 0; start < len(wordIDs); start += bulkBatchSize {
+			var senses []model.Sense
+			err := s.conn(ctx).Where("word_id IN (?) AND part_of_speech = '' AND gloss = ''", wordIDs[start:min(start+bulkBatchSize, len(wordIDs))]).Find(&senses).Error
+			if err != nil {
+				return wrap(err, "searching default senses")
+			}
+			for _, sense := range senses {
+				ids[sense.WordID] = sense.ID
+			}
+		}
+		return nil
+	}
+	if err := find(wordIDs); err != nil {
+		return nil, err
+	}
+
+	var missing []*model.Sense
+	var missingIDs []int
+	for _, id := range wordIDs {
+		if _, ok := ids[id]; !ok {
+			ids[id] = 0
+			missing = append(missing, &model.Sense{WordID: id})
+			missingIDs = append(missingIDs, id)
+		}
+	}
+	if len(missing) > 0 {
+		err := s.conn(ctx).Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(missing, bulkBatchSize).Error
+		if err != nil {
+			return nil, wrap(err, "inserting default senses")
+		}
+		if err := find(missingIDs); err != nil {
+			return nil, err
+		}
+	}
+	return ids, nil
+}
+
+// AddTranslation stores the translation with AddTranslations, which links the default senses of its words.
+func (s *GormStore) AddTranslation(ctx context.Context, translation model.Translation) error {
+	_, err := s.AddTranslations(ctx, []model.Translation{translation})
+	return err
+}
+
+func (s *GormStore) AddTranslations(ctx context.Context, translations []model.Translation) ([]bool, error) {
+	var wordIDs []int
+	for _, translation := range translations {
+		if translation.SenseID == 0 || translation.TranslationSenseID == 0 {
+			wordIDs = append(wordIDs, translation.WordID, translation.TranslationID)
+		}
+	}
+	if len(wordIDs) > 0 {
+		defaults, err := s.defaultSenses(ctx, wordIDs)
+		if err != nil {
+			return nil, err
+		}
+		for i := range translations {
+			if translations[i].SenseID == 0 {
+				translations[i].SenseID = defaults[translations[i].WordID]
+			}
+			if translations[i].TranslationSenseID == 0 {
+				translations[i].TranslationSenseID = defaults[translations[i].TranslationID]
+			}
+		}
+	}
+
+	created := make([]bool, len(translations))
+	existing := make(map[model.Translation]bool, len(translations))
+	for start := 0; start < len(translations); start += bulkBatchSize {
+		batch := translations[start:min(start+bulkBatchSize, len(translations))]
+		pairs := make([][]interface{}, len(batch))
+		for i, translation := range batch {
+			translation.SortTranslation()
+			pairs[i] = []interface{}{translation.SenseID, translation.TranslationSenseID}
+		}
+		var stored []model.Translation
+		err := s.conn(ctx).Where("(sense_id, translation_sense_id) IN ?", pairs).Find(&stored).Error
+		if err != nil {
+			return nil, wrap(err, "searching translations")
+		}
+		for _, translation := range stored {
+			existing[translation] = true
+		}
+	}
+
+	var missing []model.Translation
+	for i, translation := range translations {
+		translation.SortTranslation()
+		if !existing[translation] {
+			existing[translation] = true
+			created[i] = true
+			missing = append(missing, translation)
+		}
+	}
+	if len(missing) > 0 {
+		err := s.conn(ctx).Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(missing, bulkBatchSize).Error
+		if err != nil {
+			return nil, wrap(err, "inserting translations")
+		}
+	}
+	return created, nil
+}
+
+// DeleteTranslation matches any sense of a word whose sense ID is zero.
+func (s *GormStore) DeleteTranslation(ctx context.Context, translation model.Translation) (bool, error) {
+	translation.SortTranslation()
+	query := s.conn(ctx).Where("word_id = ? AND translation_id = ?", translation.WordID, translation.TranslationID)
+	if translation.SenseID != 0 {
+		query = query.Where("sense_id = ?", translation.SenseID)
+	}
+	if translation.TranslationSenseID != 0 {
+		query = query.Where("translation_sense_id = ?", translation.TranslationSenseID)
+	}
+	result := query.Delete(&model.Translation{})
+	if result.Error != nil {
+		return false, wrap(result.Error, "deleting translation")
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (s *GormStore) ListTranslations(ctx context.Context) ([]model.Translation, error) {
+	var translations []model.Translation
+	err := s.conn(ctx).Order("word_id, translation_id, sense_id, translation_sense_id").Find(&translations).Error
+	if err != nil {
+		return nil, wrap(err, "listing translations")
+	}
+	return translations, nil
+}
+
+func (s *GormStore) TranslationsOfSenses(ctx context.Context, senseIDs []int) ([]model.Translation, error) {
+	var translations []model.Translation
+	if len(senseIDs) == 0 {
+		return translations, nil
+	}
+	err := s.conn(ctx).Where("sense_id IN (?) OR translation_sense_id IN (?)", senseIDs, senseIDs).
+		Order("word_id, translation_id, sense_id, translation_sense_id").
+		Find(&translations).Error
+	if err != nil {
+		return nil, wrap(err, "searching translations of senses")
+	}
+	return translations, nil
+}
+
+func (s *GormStore) TranslationsOf(ctx context.Context, wordID int) ([]*model.Word, error) {
+	var words []*model.Word
+	err := s.conn(ctx).
+		Where("id IN (SELECT translation_id FROM translations WHERE word_id = ? UNION SELECT word_id FROM translations WHERE translation_id = ?)", wordID, wordID).
+		Order("id").
+		Find(&words).Error
+	if err != nil {
+		return nil, wrap(err, "searching translations")
+	}
+	return words, nil
+}
+
+func (s *GormStore) TranslationsOfWords(ctx context.Context, wordIDs []int) (map[int][]*model.Word, error) {
+	translated := make(map[int][]*model.Word, len(wordIDs))
+	for _, id := range wordIDs {
+		translated[id] = []*model.Word{}
+	}
+	if len(wordIDs) == 0 {
+		return translated, nil
+	}
+
+	var translations []model.Translation
+	err := s.conn(ctx).Where("word_id IN (?) OR translation_id IN (?)", wordIDs, wordIDs).Find(&translations).Error
+	if err != nil {
+		return nil, wrap(err, "searching translations")
+	}
+	// sources maps every translated word to the words of wordIDs it is a translation of,
+	// once however many of their senses are linked.
+	sources := make(map[int][]int)
+	linked := make(map[[2]int]bool)
+	link := func(source, target int) {
+		if _, ok := translated[source]; ok && !linked[[2]int{source, target}] {
+			linked[[2]int{source, target}] = true
+			sources[target] = append(sources[target], source)
+		}
+	}
+	for _, translation := range translations {
+		link(translation.WordID, translation.TranslationID)
+		link(translation.TranslationID, translation.WordID)
+	}
+	ids := make([]int, 0, len(sources))
+	for id := range sources {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	for start := 0; start < len(ids); start += bulkBatchSize {
+		words, err := s.FindWordsByIDs(ctx, ids[start:min(start+bulkBatchSize, len(ids))])
+		if err != nil {
+			return nil, err
+		}
+		for _, word := range words {
+			for _, source := range sources[word.ID] {
+				translated[source] = append(translated[source], word)
+			}
+		}
+	}
+	return translated, nil
+}
+
+type walkRow struct {
+	WordID int
+	Path   string
+	Hops   int
+}
+
+func (s *GormStore) TranslationPaths(ctx context.Context, sourceID int, targetLanguage string, maxHops int) ([][]int, error) {
+	var rows []walkRow
+	err := s.conn(ctx).Raw(translationWalkQuery, sourceID, sourceID, maxHops, targetLanguage, targetLanguage).Scan(&rows).Error
+	if err != nil {
+		return nil, wrap(err, "walking translations")
+	}
+
+	seen := make(map[int]bool)
+	var paths [][]int
+	for _, row := range rows {
+		if seen[row.WordID] {
+			continue
+		}
+		seen[row.WordID] = true
+		path, err := parseWalkPath(row.Path)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+func parseWalkPath(path string) ([]int, error) {
+	parts := strings.Split(strings.Trim(path, ","), ",")
+	ids := make([]int, len(parts))
+	for i, part := range parts {
+		id, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, fmt.Errorf("malformed translation path %q", path)
+		}
+		ids[i] = id
+	}
+	return ids, nil
+}
@@ -0,0 +1,43 @@
+package store
+
+import (
+	"backend/graph/model"
+	"backend/normalize"
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ResolveLanguage returns the registered language identified by code.
+// Besides any BCP 47 or ISO 639 spelling of the code, the English or native name of the language is accepted.
+func ResolveLanguage(ctx context.Context, s DictionaryStore, code string) (*model.Language, error) {
+	var language *model.Language
+	var err error
+	if canonical, ok := model.CanonicalLanguageCode(code); ok {
+		language, err = s.FindLanguage(ctx, canonical)
+	} else {
+		language, err = s.FindLanguageByName(ctx, code)
+	}
+	if errors.Is(err, ErrNotFound) {
+		return nil, fmt.Errorf("%w %q, register it with addLanguage", ErrUnsupportedLanguage, code)
+	}
+	return language, err
+}
+
+// NormalizeWord returns a word with canonical language and text in the form it is stored and looked up under.
+func NormalizeWord(ctx context.Context, s DictionaryStore, text string, code string) (model.Word, error) {
+	language, err := ResolveLanguage(ctx, s, code)
+	if err != nil {
+		return model.Word{}, err
+	}
+	return NormalizeWordIn(text, language), nil
+}
+
+// NormalizeWordIn is NormalizeWord for an already resolved language.
+func NormalizeWordIn(text string, language *model.Language) model.Word {
+	return model.Word{
+		Text:        normalize.Key(text, language.Code, language.CaseSensitive),
+		DisplayText: normalize.Display(text),
+		Language:    language.Code,
+	}
+}
@@ -0,0 +1,1323 @@
+package store
+
+import (
+	"backend/graph/model"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/agnivade/levenshtein"
+)
+
+// memoryData is a snapshot of the dictionary. Stored words and languages are never modified in place,
+// so snapshots can share them and a transaction only copies the maps when it first writes.
+// wordOrder holds the IDs of the words in ascending order, so pages of words are cut out without sorting them.
+type memoryData struct {
+	languages           map[string]*model.Language
+	words               map[int]*model.Word
+	wordIDs             map[wordKey]int
+	wordOrder           []int
+	senses              map[int]*model.Sense
+	senseIDs            map[senseKey]int
+	examples            map[int]*model.Example
+	pronunciations      map[int]*model.Pronunciation
+	recordings          map[int]*model.Recording
+	translations        map[model.Translation]bool
+	tags                map[int]*model.Tag
+	wordTags            map[model.WordTag]bool
+	collections         map[int]*model.Collection
+	collectionWords     map[model.CollectionWord]bool
+	lastWordID          int
+	lastSenseID         int
+	lastExampleID       int
+	lastPronunciationID int
+	lastRecordingID     int
+	lastTagID           int
+	lastCollectionID    int
+}
+
+// senseKey identifies a sense, parts of speech and glosses are unique within a word.
+type senseKey struct {
+	wordID       int
+	partOfSpeech model.PartOfSpeech
+	gloss        string
+}
+
+func newMemoryData() *memoryData {
+	return &memoryData{
+		languages:       make(map[string]*model.Language),
+		words:           make(map[int]*model.Word),
+		wordIDs:         make(map[wordKey]int),
+		senses:          make(map[int]*model.Sense),
+		senseIDs:        make(map[senseKey]int),
+		examples:        make(map[int]*model.Example),
+		pronunciations:  make(map[int]*model.Pronunciation),
+		recordings:      make(map[int]*model.Recording),
+		translations:    make(map[model.Translation]bool),
+		tags:            make(map[int]*model.Tag),
+		wordTags:        make(map[model.WordTag]bool),
+		collections:     make(map[int]*model.Collection),
+		collectionWords: make(map[model.CollectionWord]bool),
+	}
+}
+
+func (d *memoryData) clone() *memoryData {
+	c := &memoryData{
+		languages:           make(map[string]*model.Language, len(d.languages)),
+		words:               make(map[int]*model.Word, len(d.words)),
+		wordIDs:             make(map[wordKey]int, len(d.wordIDs)),
+		wordOrder:           slices.Clone(d.wordOrder),
+		senses:              make(map[int]*model.Sense, len(d.senses)),
+		senseIDs:            make(map[senseKey]int, len(d.senseIDs)),
+		examples:            make(map[int]*model.Example, len(d.examples)),
+		pronunciations:      make(map[int]*model.Pronunciation, len(d.pronunciations)),
+		recordings:          make(map[int]*model.Recording, len(d.recordings)),
+		translations:        make(map[model.Translation]bool, len(d.translations)),
+		tags:                make(map[int]*model.Tag, len(d.tags)),
+		wordTags:            make(map[model.WordTag]bool, len(d.wordTags)),
+		collections:         make(map[int]*model.Collection, len(d.collections)),
+		collectionWords:     make(map[model.CollectionWord]bool, len(d.collectionWords)),
+		lastWordID:          d.lastWordID,
+		lastSenseID:         d.lastSenseID,
+		lastExampleID:       d.lastExampleID,
+		lastPronunciationID: d.lastPronunciationID,
+		lastRecordingID:     d.lastRecordingID,
+		lastTagID:           d.lastTagID,
+		lastCollectionID:    d.lastCollectionID,
+	}
+	for code, language := range d.languages {
+		c.languages[code] = language
+	}
+	for id, word := range d.words {
+		c.words[id] = word
+	}
+	for key, id := range d.wordIDs {
+		c.wordIDs[key] = id
+	}
+	for id, sense := range d.senses {
+		c.senses[id] = sense
+	}
+	for key, id := range d.senseIDs {
+		c.senseIDs[key] = id
+	}
+	for id, example := range d.examples {
+		c.examples[id] = example
+	}
+	for id, pronunciation := range d.pronunciations {
+		c.pronunciations[id] = pronunciation
+	}
+	for id, recording := range d.recordings {
+		c.recordings[id] = recording
+	}
+	for translation := range d.translations {
+		c.translations[translation] = true
+	}
+	for id, tag := range d.tags {
+		c.tags[id] = tag
+	}
+	for link := range d.wordTags {
+		c.wordTags[link] = true
+	}
+	for id, collection := range d.collections {
+		c.collections[id] = collection
+	}
+	for member := range d.collectionWords {
+		c.collectionWords[member] = true
+	}
+	return c
+}
+
+// sortedWords returns copies of words matching keep, ordered by ID.
+func (d *memoryData) sortedWords(keep func(word *model.Word) bool) []*model.Word {
+	words := make([]*model.Word, 0)
+	for _, id := range d.wordOrder {
+		if word := d.words[id]; keep(word) {
+			words = append(words, copyWord(word))
+		}
+	}
+	return words
+}
+
+// translationIDs returns IDs of the words the word with given ID is translated to,
+// in its senses of partOfSpeech unless it is empty.
+func (d *memoryData) translationIDs(wordID int, partOfSpeech model.PartOfSpeech) map[int]bool {
+	ids := make(map[int]bool)
+	for translation := range d.translations {
+		if translation.WordID == wordID && d.senseIs(translation.SenseID, partOfSpeech) {
+			ids[translation.TranslationID] = true
+		} else if translation.TranslationID == wordID && d.senseIs(translation.TranslationSenseID, partOfSpeech) {
+			ids[translation.WordID] = true
+		}
+	}
+	return ids
+}
+
+// senseIs reports whether the sense with given ID is of partOfSpeech, any sense is when it is empty.
+func (d *memoryData) senseIs(senseID int, partOfSpeech model.PartOfSpeech) bool {
+	return partOfSpeech == "" || d.senses[senseID] != nil && d.senses[senseID].PartOfSpeech == partOfSpeech
+}
+
+// sortedSenses returns copies of senses matching keep, ordered by ID.
+func (d *memoryData) sortedSenses(keep func(sense *model.Sense) bool) []*model.Sense {
+	senses := make([]*model.Sense, 0)
+	for _, sense := range d.senses {
+		if keep(sense) {
+			c := *sense
+			senses = append(senses, &c)
+		}
+	}
+	sort.Slice(senses, func(i, j int) bool { return senses[i].ID < senses[j].ID })
+	return senses
+}
+
+// addSense stores a copy of the sense under a new ID, which is set in sense.
+func (d *memoryData) addSense(sense *model.Sense) {
+	d.lastSenseID++
+	sense.ID = d.lastSenseID
+	c := *sense
+	d.senses[sense.ID] = &c
+	d.senseIDs[senseKey{sense.WordID, sense.PartOfSpeech, sense.Gloss}] = sense.ID
+}
+
+// defaultSense returns the ID of the default sense of the word, creating it when missing.
+func (d *memoryData) defaultSense(wordID int) int {
+	if id, ok := d.senseIDs[senseKey{wordID: wordID}]; ok {
+		return id
+	}
+	sense := &model.Sense{WordID: wordID}
+	d.addSense(sense)
+	return sense.ID
+}
+
+// sortedExamples returns copies of examples matching keep, ordered by ID.
+func (d *memoryData) sortedExamples(keep func(example *model.Example) bool) []*model.Example {
+	examples := make([]*model.Example, 0)
+	for _, example := range d.examples {
+		if keep(example) {
+			examples = append(examples, copyExample(example))
+		}
+	}
+	sort.Slice(examples, func(i, j int) bool { return examples[i].ID < examples[j].ID })
+	return examples
+}
+
+// setExample stores a copy of the example, replacing the stored example with the same ID.
+func (d *memoryData) setExample(example *model.Example) {
+	d.examples[example.ID] = copyExample(example)
+}
+
+// unlinkExamples removes the links of stored examples to the examples with given IDs, other than
+// those of the examples themselves.
+func (d *memoryData) unlinkExamples(ids ...int) {
+	for _, example := range d.examples {
+		if example.TranslatedExampleID == nil || slices.Contains(ids, example.ID) || !slices.Contains(ids, *example.TranslatedExampleID) {
+			continue
+		}
+		unlinked := copyExample(example)
+		unlinked.TranslatedExampleID = nil
+		d.examples[example.ID] = unlinked
+	}
+}
+
+// linkExample stores the example, linking its translated example back to it like GormStore.linkExample.
+func (d *memoryData) linkExample(example *model.Example) error {
+	if id := example.TranslatedExampleID; id != nil && *id != example.ID && d.examples[*id] == nil {
+		return fmt.Errorf("database error while linking examples: example %d must exist", *id)
+	}
+	d.setExample(example)
+	if example.TranslatedExampleID == nil {
+		d.unlinkExamples(example.ID)
+		return nil
+	}
+	d.unlinkExamples(example.ID, *example.TranslatedExampleID)
+	translated := copyExample(d.examples[*example.TranslatedExampleID])
+	translated.TranslatedExampleID = &example.ID
+	d.setExample(translated)
+	return nil
+}
+
+// sortedCopies returns copies of the records matching keep, ordered by ID. It suits records without pointers,
+// which a shallow copy detaches from the stored record.
+func sortedCopies[T any](records map[int]*T, keep func(record *T) bool) []*T {
+	ids := make([]int, 0)
+	for id, record := range records {
+		if keep(record) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	copies := make([]*T, len(ids))
+	for i, id := range ids {
+		c := *records[id]
+		copies[i] = &c
+	}
+	return copies
+}
+
+// sortTranslations orders translations like ListTranslations.
+func sortTranslations(translations []model.Translation) {
+	sort.Slice(translations, func(i, j int) bool {
+		a, b := translations[i], translations[j]
+		if a.WordID != b.WordID {
+			return a.WordID < b.WordID
+		}
+		if a.TranslationID != b.TranslationID {
+			return a.TranslationID < b.TranslationID
+		}
+		if a.SenseID != b.SenseID {
+			return a.SenseID < b.SenseID
+		}
+		return a.TranslationSenseID < b.TranslationSenseID
+	})
+}
+
+func copyWord(word *model.Word) *model.Word {
+	c := *word
+	c.Translations = nil
+	return &c
+}
+
+// copyExample copies the optional IDs along with the example, so neither copy modifies the other.
+func copyExample(example *model.Example) *model.Example {
+	c := *example
+	if example.SenseID != nil {
+		senseID := *example.SenseID
+		c.SenseID = &senseID
+	}
+	if example.TranslatedExampleID != nil {
+		translatedExampleID := *example.TranslatedExampleID
+		c.TranslatedExampleID = &translatedExampleID
+	}
+	return &c
+}
+
+func copyLanguage(language *model.Language) *model.Language {
+	c := *language
+	return &c
+}
+
+// memoryState is the committed data shared by a MemoryStore and its transactions.
+type memoryState struct {
+	mu   sync.RWMutex
+	data *memoryData
+}
+
+// MemoryStore is a DictionaryStore keeping the dictionary in memory, meant for tests and local demos.
+// Transactions are serialized, each one works on its own snapshot which replaces the data on commit.
+type MemoryStore struct {
+	state            *memoryState
+	statementTimeout time.Duration
+	// data and owned are only set for stores passed to Transaction callbacks,
+	// owned is set once data was copied from the committed snapshot.
+	data          *memoryData
+	owned         bool
+	inTransaction bool
+}
+
+// NewMemory returns an empty store. Transactions are cancelled after statementTimeout, zero means no limit.
+func NewMemory(statementTimeout time.Duration) *MemoryStore {
+	return &MemoryStore{state: &memoryState{data: newMemoryData()}, statementTimeout: statementTimeout}
+}
+
+// read returns the data to query and the function releasing it.
+func (s *MemoryStore) read() (*memoryData, func()) {
+	if s.inTransaction {
+		return s.data, func() {}
+	}
+	s.state.mu.RLock()
+	return s.state.data, s.state.mu.RUnlock
+}
+
+// write returns the data to modify and the function releasing it.
+// In a transaction the committed snapshot is copied on the first write.
+func (s *MemoryStore) write() (*memoryData, func()) {
+	if s.inTransaction {
+		if !s.owned {
+			s.data = s.data.clone()
+			s.owned = true
+		}
+		return s.data, func() {}
+	}
+	s.state.mu.Lock()
+	return s.state.data, s.state.mu.Unlock
+}
+
+func (s *MemoryStore) Transaction(ctx context.Context, fn func(tx DictionaryStore) error) error {
+	if s.inTransaction {
+		return fn(s)
+	}
+	if s.statementTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.statementTimeout)
+		defer cancel()
+	}
+
+	s.state.mu.Lock()
+	defer s.state.mu.Unlock()
+	if err := contextError(ctx); err != nil {
+		return err
+	}
+	tx := &MemoryStore{state: s.state, statementTimeout: s.statementTimeout, data: s.state.data, inTransaction: true}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	// Like a database, the transaction is not committed once its deadline passed.
+	if err := contextError(ctx); err != nil {
+		return err
+	}
+	if tx.owned {
+		s.state.data = tx.data
+	}
+	return nil
+}
+
+func contextError(ctx context.Context) error {
+	err := ctx.Err()
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %w", ErrTimeout, err)
+	}
+	return err
+}
+
+func (s *MemoryStore) FindLanguage(ctx context.Context, code string) (*model.Language, error) {
+	d, release := s.read()
+	defer release()
+	language, ok := d.languages[code]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return copyLanguage(language), nil
+}
+
+func (s *MemoryStore) FindLanguageByName(ctx context.Context, name string) (*model.Language, error) {
+	languages, err := s.ListLanguages(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, language := range languages {
+		if strings.EqualFold(language.Name, name) || strings.EqualFold(language.NativeName, name) {
+			return language, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (s *MemoryStore) ListLanguages(ctx context.Context) ([]*model.Language, error) {
+	d, release := s.read()
+	defer release()
+	languages := make([]*model.Language, 0, len(d.languages))
+	for _, language := range d.languages {
+		languages = append(languages, copyLanguage(language))
+	}
+	sort.Slice(languages, func(i, j int) bool { return languages[i].Code < languages[j].Code })
+	return languages, nil
+}
+
+func (s *MemoryStore) AddLanguage(ctx context.Context, language *model.Language) (bool, error) {
+	d, release := s.write()
+	defer release()
+	if existing, ok := d.languages[language.Code]; ok {
+		*language = *existing
+		return false, nil
+	}
+	d.languages[language.Code] = copyLanguage(language)
+	return true, nil
+}
+
+func (s *MemoryStore) FindWord(ctx context.Context, text string, language string) (*model.Word, error) {
+	d, release := s.read()
+	defer release()
+	id, ok := d.wordIDs[wordKey{text, language}]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return copyWord(d.words[id]), nil
+}
+
+func (s *MemoryStore) FindWordsByIDs(ctx context.Context, ids []int) ([]*model.Word, error) {
+	d, release := s.read()
+	defer release()
+	wanted := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+	return d.sortedWords(func(word *model.Word) bool { return wanted[word.ID] }), nil
+}
+
+func (s *MemoryStore) FindOrCreateWord(ctx context.Context, word *model.Word) (bool, error) {
+	d, release := s.write()
+	defer release()
+	key := wordKey{word.Text, word.Language}
+	if id, ok := d.wordIDs[key]; ok {
+		*word = *copyWord(d.words[id])
+		return false, nil
+	}
+	d.lastWordID++
+	word.ID = d.lastWordID
+	d.words[word.ID] = copyWord(word)
+	d.wordIDs[key] = word.ID
+	// IDs only grow, the new word comes last.
+	d.wordOrder = append(d.wordOrder, word.ID)
+	d.addSense(&model.Sense{WordID: word.ID})
+	return true, nil
+}
+
+func (s *MemoryStore) FindOrCreateWords(ctx context.Context, words []*model.Word) ([]bool, error) {
+	created := make([]bool, len(words))
+	for i, word := range words {
+		var err error
+		created[i], err = s.FindOrCreateWord(ctx, word)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return created, nil
+}
+
+func (s *MemoryStore) UpdateWord(ctx context.Context, word *model.Word) error {
+	d, release := s.write()
+	defer release()
+	current, ok := d.words[word.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	key := wordKey{word.Text, word.Language}
+	if id, ok := d.wordIDs[key]; ok && id != word.ID {
+		return fmt.Errorf("database error while updating word: %w", ErrConflict)
+	}
+	delete(d.wordIDs, wordKey{current.Text, current.Language})
+	d.words[word.ID] = copyWord(word)
+	d.wordIDs[key] = word.ID
+	return nil
+}
+
+func (s *MemoryStore) DeleteWord(ctx context.Context, word *model.Word) error {
+	d, release := s.write()
+	defer release()
+	current, ok := d.words[word.ID]
+	if !ok {
+		return nil
+	}
+	for translation := range d.translations {
+		if translation.WordID == word.ID || translation.TranslationID == word.ID {
+			delete(d.translations, translation)
+		}
+	}
+	for id, example := range d.examples {
+		if example.WordID == word.ID {
+			d.unlinkExamples(id)
+			delete(d.examples, id)
+		}
+	}
+	for id, pronunciation := range d.pronunciations {
+		if pronunciation.WordID == word.ID {
+			delete(d.pronunciations, id)
+		}
+	}
+	for id, recording := range d.recordings {
+		if recording.WordID == word.ID {
+			delete(d.recordings, id)
+		}
+	}
+	for link := range d.wordTags {
+		if link.WordID == word.ID {
+			delete(d.wordTags, link)
+		}
+	}
+	for member := range d.collectionWords {
+		if member.WordID == word.ID {
+			delete(d.collectionWords, member)
+		}
+	}
+	for key, id := range d.senseIDs {
+		if key.wordID == word.ID {
+			delete(d.senseIDs, key)
+			delete(d.senses, id)
+		}
+	}
+	delete(d.wordIDs, wordKey{current.Text, current.Language})
+	delete(d.words, word.ID)
+	if i, ok := slices.BinarySearch(d.wordOrder, word.ID); ok {
+		d.wordOrder = slices.Delete(d.wordOrder, i, i+1)
+	}
+	return nil
+}
+
+func (s *MemoryStore) ListWords(ctx context.Context, filter WordFilter, page Page) (*WordPage, error) {
+	d, release := s.read()
+	defer release()
+	var translations map[int]bool
+	if filter.TranslationOf != 0 {
+		translations = d.translationIDs(filter.TranslationOf, filter.PartOfSpeech)
+	}
+	withPartOfSpeech := make(map[int]bool)
+	if filter.TranslationOf == 0 && filter.PartOfSpeech != "" {
+		for _, sense := range d.senses {
+			if sense.PartOfSpeech == filter.PartOfSpeech {
+				withPartOfSpeech[sense.WordID] = true
+			}
+		}
+	}
+	// withTags counts the tags of the filter each word has.
+	withTags := make(map[int]int)
+	tagNames := uniqueStrings(filter.Tags)
+	if len(tagNames) > 0 {
+		for link := range d.wordTags {
+			if slices.Contains(tagNames, d.tags[link.TagID].Name) {
+				withTags[link.WordID]++
+			}
+		}
+	}
+	unfiltered := filter.Language == "" && filter.TextPrefix == "" && filter.TranslationOf == 0 &&
+		filter.PartOfSpeech == "" && len(tagNames) == 0 && filter.CollectionID == 0
+	matches := func(i int) bool {
+		word := d.words[d.wordOrder[i]]
+		return unfiltered || (filter.Language == "" || word.Language == filter.Language) &&
+			strings.HasPrefix(word.Text, filter.TextPrefix) &&
+			(filter.TranslationOf == 0 || translations[word.ID]) &&
+			(filter.TranslationOf != 0 || filter.PartOfSpeech == "" || withPartOfSpeech[word.ID]) &&
+			withTags[word.ID] == len(tagNames) &&
+			(filter.CollectionID == 0 || d.collectionWords[model.CollectionWord{CollectionID: filter.CollectionID, WordID: word.ID}])
+	}
+	// anyMatches reports whether a word between the from and to positions of wordOrder matches the filter.
+	anyMatches := func(from, to int) bool {
+		for i := from; i < to; i++ {
+			if matches(i) {
+				return true
+			}
+		}
+		return false
+	}
+
+	// start and end bound the page range in wordOrder, the words before and after it are in other pages.
+	// Only the words of the page are visited, and the others only to count them when the filter drops some.
+	start, _ := slices.BinarySearch(d.wordOrder, page.AfterID+1)
+	end := len(d.wordOrder)
+	if page.BeforeID != 0 {
+		end, _ = slices.BinarySearch(d.wordOrder, page.BeforeID)
+	}
+	if end < start {
+		end = start
+	}
+
+	result := &WordPage{Total: len(d.wordOrder), Words: make([]*model.Word, 0)}
+	if !unfiltered {
+		result.Total = 0
+		for i := range d.wordOrder {
+			if matches(i) {
+				result.Total++
+			}
+		}
+	}
+	if page.Backward {
+		first := end
+		for first > start && len(result.Words) < page.Limit {
+			first--
+			if matches(first) {
+				result.Words = append(result.Words, copyWord(d.words[d.wordOrder[first]]))
+			}
+		}
+		slices.Reverse(result.Words)
+		result.HasPreviousPage = anyMatches(start, first)
+		result.HasNextPage = page.BeforeID != 0 && anyMatches(end, len(d.wordOrder))
+	} else {
+		last := start
+		for last < end && len(result.Words) < page.Limit {
+			if matches(last) {
+				result.Words = append(result.Words, copyWord(d.words[d.wordOrder[last]]))
+			}
+			last++
+		}
+		result.HasNextPage = anyMatches(last, end)
+		result.HasPreviousPage = page.AfterID != 0 && anyMatches(0, start)
+	}
+	return result, nil
+}
+
+// SimilarWords ranks words of the language by edit distance, there is no index to preselect candidates with.
+func (s *MemoryStore) SimilarWords(ctx context.Context, text string, language string, maxDistance int, limit int) ([]*model.Word, error) {
+	d, release := s.read()
+	defer release()
+	length := len([]rune(text))
+	words := d.sortedWords(func(word *model.Word) bool {
+		wordLength := len([]rune(word.Text))
+		return word.Language == language && wordLength >= length-maxDistance && wordLength <= length+maxDistance
+	})
+	return closestWords(text, words, maxDistance, limit), nil
+}
+
+// closestWords returns up to limit words within maxDistance edits of text, closest first.
+// Words equally distant keep their order.
+func closestWords(text string, words []*model.Word, maxDistance int, limit int) []*model.Word {
+	distances := make(map[int]int, len(words))
+	closest := make([]*model.Word, 0, len(words))
+	for _, word := range words {
+		distances[word.ID] = levenshtein.ComputeDistance(text, word.Text)
+		if distances[word.ID] <= maxDistance {
+			closest = append(closest, word)
+		}
+	}
+	sort.SliceStable(closest, func(i, j int) bool {
+		return distances[closest[i].ID] < distances[closest[j].ID]
+	})
+	if len(closest) > limit {
+		closest = closest[:limit]
+	}
+	return closest
+}
+
+func (s *MemoryStore) FindSensesByIDs(ctx context.Context, ids []int) ([]*model.Sense, error) {
+	d, release := s.read()
+	defer release()
+	wanted := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+	return d.sortedSenses(func(sense *model.Sense) bool { return wanted[sense.ID] }), nil
+}
+
+func (s *MemoryStore) SensesOf(ctx context.Context, wordIDs []int) (map[int][]*model.Sense, error) {
+	d, release := s.read()
+	defer release()
+	senses := make(map[int][]*model.Sense, len(wordIDs))
+	for _, id := range wordIDs {
+		senses[id] = []*model.Sense{}
+	}
+	for _, sense := range d.sortedSenses(func(sense *model.Sense) bool { return senses[sense.WordID] != nil }) {
+		senses[sense.WordID] = append(senses[sense.WordID], sense)
+	}
+	return senses, nil
+}
+
+func (s *MemoryStore) FindOrCreateSense(ctx context.Context, sense *model.Sense) (bool, error) {
+	d, release := s.write()
+	defer release()
+	if d.words[sense.WordID] == nil {
+		return false, fmt.Errorf("database error while inserting sense: word %d must exist", sense.WordID)
+	}
+	if id, ok := d.senseIDs[senseKey{sense.WordID, sense.PartOfSpeech, sense.Gloss}]; ok {
+		*sense = *d.senses[id]
+		return false, nil
+	}
+	d.addSense(sense)
+	return true, nil
+}
+
+func (s *MemoryStore) UpdateSense(ctx context.Context, sense *model.Sense) error {
+	d, release := s.write()
+	defer release()
+	current, ok := d.senses[sense.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	if d.words[sense.WordID] == nil {
+		return fmt.Errorf("database error while updating sense: word %d must exist", sense.WordID)
+	}
+	key := senseKey{sense.WordID, sense.PartOfSpeech, sense.Gloss}
+	if id, ok := d.senseIDs[key]; ok && id != sense.ID {
+		return fmt.Errorf("database error while updating sense: %w", ErrConflict)
+	}
+	delete(d.senseIDs, senseKey{current.WordID, current.PartOfSpeech, current.Gloss})
+	c := *sense
+	d.senses[sense.ID] = &c
+	d.senseIDs[key] = sense.ID
+	return nil
+}
+
+func (s *MemoryStore) DeleteSense(ctx context.Context, sense *model.Sense) error {
+	d, release := s.write()
+	defer release()
+	current, ok := d.senses[sense.ID]
+	if !ok {
+		return nil
+	}
+	for translation := range d.translations {
+		if translation.SenseID == sense.ID || translation.TranslationSenseID == sense.ID {
+			delete(d.translations, translation)
+		}
+	}
+	for _, example := range d.examples {
+		if example.SenseID != nil && *example.SenseID == sense.ID {
+			detached := copyExample(example)
+			detached.SenseID = nil
+			d.setExample(detached)
+		}
+	}
+	delete(d.senseIDs, senseKey{current.WordID, current.PartOfSpeech, current.Gloss})
+	delete(d.senses, sense.ID)
+	return nil
+}
+
+func (s *MemoryStore) FindExamplesByIDs(ctx context.Context, ids []int) ([]*model.Example, error) {
+	d, release := s.read()
+	defer release()
+	wanted := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+	return d.sortedExamples(func(example *model.Example) bool { return wanted[example.ID] }), nil
+}
+
+func (s *MemoryStore) ExamplesOf(ctx context.Context, wordIDs []int) (map[int][]*model.Example, error) {
+	d, release := s.read()
+	defer release()
+	examples := make(map[int][]*model.Example, len(wordIDs))
+	for _, id := range wordIDs {
+		examples[id] = []*model.Example{}
+	}
+	for _, example := range d.sortedExamples(func(example *model.Example) bool { return examples[example.WordID] != nil }) {
+		examples[example.WordID] = append(examples[example.WordID], example)
+	}
+	return examples, nil
+}
+
+func (s *MemoryStore) AddExample(ctx context.Context, example *model.Example) error {
+	d, release := s.write()
+	defer release()
+	if err := d.checkExample(example); err != nil {
+		return fmt.Errorf("database error while inserting example: %w", err)
+	}
+	d.lastExampleID++
+	example.ID = d.lastExampleID
+	return d.linkExample(example)
+}
+
+func (s *MemoryStore) UpdateExample(ctx context.Context, example *model.Example) error {
+	d, release := s.write()
+	defer release()
+	if d.examples[example.ID] == nil {
+		return ErrNotFound
+	}
+	if err := d.checkExample(example); err != nil {
+		return fmt.Errorf("database error while updating example: %w", err)
+	}
+	return d.linkExample(example)
+}
+
+// checkExample fails like the foreign keys of the examples table do for examples of missing words or senses.
+func (d *memoryData) checkExample(example *model.Example) error {
+	if d.words[example.WordID] == nil {
+		return fmt.Errorf("word %d must exist", example.WordID)
+	}
+	if example.SenseID != nil && d.senses[*example.SenseID] == nil {
+		return fmt.Errorf("sense %d must exist", *example.SenseID)
+	}
+	return nil
+}
+
+func (s *MemoryStore) DeleteExample(ctx context.Context, example *model.Example) error {
+	d, release := s.write()
+	defer release()
+	d.unlinkExamples(example.ID)
+	delete(d.examples, example.ID)
+	return nil
+}
+
+func (s *MemoryStore) FindPronunciationsByIDs(ctx context.Context, ids []int) ([]*model.Pronunciation, error) {
+	d, release := s.read()
+	defer release()
+	wanted := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+	return sortedCopies(d.pronunciations, func(pronunciation *model.Pronunciation) bool { return wanted[pronunciation.ID] }), nil
+}
+
+func (s *MemoryStore) PronunciationsOf(ctx context.Context, wordIDs []int) (map[int][]*model.Pronunciation, error) {
+	d, release := s.read()
+	defer release()
+	pronunciations := make(map[int][]*model.Pronunciation, len(wordIDs))
+	for _, id := range wordIDs {
+		pronunciations[id] = []*model.Pronunciation{}
+	}
+	for _, pronunciation := range sortedCopies(d.pronunciations, func(pronunciation *model.Pronunciation) bool { return pronunciations[pronunciation.WordID] != nil }) {
+		pronunciations[pronunciation.WordID] = append(pronunciations[pronunciation.WordID], pronunciation)
+	}
+	return pronunciations, nil
+}
+
+func (s *MemoryStore) FindOrCreatePronunciation(ctx context.Context, pronunciation *model.Pronunciation) (bool, error) {
+	d, release := s.write()
+	defer release()
+	if d.words[pronunciation.WordID] == nil {
+		return false, fmt.Errorf("database error while inserting pronunciation: word %d must exist", pronunciation.WordID)
+	}
+	if existing := d.samePronunciation(pronunciation); existing != nil {
+		*pronunciation = *existing
+		return false, nil
+	}
+	d.lastPronunciationID++
+	pronunciation.ID = d.lastPronunciationID
+	c := *pronunciation
+	d.pronunciations[c.ID] = &c
+	return true, nil
+}
+
+func (s *MemoryStore) UpdatePronunciation(ctx context.Context, pronunciation *model.Pronunciation) error {
+	d, release := s.write()
+	defer release()
+	if d.pronunciations[pronunciation.ID] == nil {
+		return ErrNotFound
+	}
+	if d.words[pronunciation.WordID] == nil {
+		return fmt.Errorf("database error while updating pronunciation: word %d must exist", pronunciation.WordID)
+	}
+	if existing := d.samePronunciation(pronunciation); existing != nil && existing.ID != pronunciation.ID {
+		return fmt.Errorf("database error while updating pronunciation: %w", ErrConflict)
+	}
+	c := *pronunciation
+	d.pronunciations[c.ID] = &c
+	return nil
+}
+
+// samePronunciation returns the stored pronunciation of the word with the same transcription and accent,
+// nil when there is none.
+func (d *memoryData) samePronunciation(pronunciation *model.Pronunciation) *model.Pronunciation {
+	for _, stored := range d.pronunciations {
+		if stored.WordID == pronunciation.WordID && stored.IPA == pronunciation.IPA && stored.Accent == pronunciation.Accent {
+			return stored
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) DeletePronunciation(ctx context.Context, pronunciation *model.Pronunciation) error {
+	d, release := s.write()
+	defer release()
+	delete(d.pronunciations, pronunciation.ID)
+	return nil
+}
+
+func (s *MemoryStore) FindRecordingsByIDs(ctx context.Context, ids []int) ([]*model.Recording, error) {
+	d, release := s.read()
+	defer release()
+	wanted := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+	return sortedCopies(d.recordings, func(recording *model.Recording) bool { return wanted[recording.ID] }), nil
+}
+
+func (s *MemoryStore) RecordingsOf(ctx context.Context, wordIDs []int) (map[int][]*model.Recording, error) {
+	d, release := s.read()
+	defer release()
+	recordings := make(map[int][]*model.Recording, len(wordIDs))
+	for _, id := range wordIDs {
+		recordings[id] = []*model.Recording{}
+	}
+	for _, recording := range sortedCopies(d.recordings, func(recording *model.Recording) bool { return recordings[recording.WordID] != nil }) {
+		recordings[recording.WordID] = append(recordings[recording.WordID], recording)
+	}
+	return recordings, nil
+}
+
+func (s *MemoryStore) AddRecording(ctx context.Context, recording *model.Recording) error {
+	d, release := s.write()
+	defer release()
+	if d.words[recording.WordID] == nil {
+		return fmt.Errorf("database error while inserting recording: word %d must exist", recording.WordID)
+	}
+	for _, stored := range d.recordings {
+		if stored.BlobKey == recording.BlobKey {
+			return fmt.Errorf("database error while inserting recording: %w", ErrConflict)
+		}
+	}
+	d.lastRecordingID++
+	recording.ID = d.lastRecordingID
+	c := *recording
+	d.recordings[c.ID] = &c
+	return nil
+}
+
+func (s *MemoryStore) DeleteRecording(ctx context.Context, recording *model.Recording) error {
+	d, release := s.write()
+	defer release()
+	delete(d.recordings, recording.ID)
+	return nil
+}
+
+// sortByName orders tags or collections by name.
+func sortByName[T any](records []*T, name func(record *T) string) []*T {
+	sort.Slice(records, func(i, j int) bool { return name(records[i]) < name(records[j]) })
+	return records
+}
+
+func tagName(tag *model.Tag) string { return tag.Name }
+
+func collectionName(collection *model.Collection) string { return collection.Name }
+
+func (s *MemoryStore) ListTags(ctx context.Context) ([]*model.Tag, error) {
+	d, release := s.read()
+	defer release()
+	return sortByName(sortedCopies(d.tags, func(tag *model.Tag) bool { return true }), tagName), nil
+}
+
+func (s *MemoryStore) FindTagsByIDs(ctx context.Context, ids []int) ([]*model.Tag, error) {
+	d, release := s.read()
+	defer release()
+	wanted := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+	return sortByName(sortedCopies(d.tags, func(tag *model.Tag) bool { return wanted[tag.ID] }), tagName), nil
+}
+
+func (s *MemoryStore) TagsOf(ctx context.Context, wordIDs []int) (map[int][]*model.Tag, error) {
+	d, release := s.read()
+	defer release()
+	tags := make(map[int][]*model.Tag, len(wordIDs))
+	for _, id := range wordIDs {
+		tags[id] = []*model.Tag{}
+	}
+	for link := range d.wordTags {
+		if tags[link.WordID] != nil {
+			c := *d.tags[link.TagID]
+			tags[link.WordID] = append(tags[link.WordID], &c)
+		}
+	}
+	for id := range tags {
+		sortByName(tags[id], tagName)
+	}
+	return tags, nil
+}
+
+// tagID returns the ID of the tag with given name, zero when there is none.
+func (d *memoryData) tagID(name string) int {
+	for id, tag := range d.tags {
+		if tag.Name == name {
+			return id
+		}
+	}
+	return 0
+}
+
+func (s *MemoryStore) TagWords(ctx context.Context, wordIDs []int, names []string) error {
+	d, release := s.write()
+	defer release()
+	for _, wordID := range wordIDs {
+		if d.words[wordID] == nil {
+			return fmt.Errorf("database error while tagging words: word %d must exist", wordID)
+		}
+	}
+	for _, name := range uniqueStrings(names) {
+		id := d.tagID(name)
+		if id == 0 {
+			d.lastTagID++
+			id = d.lastTagID
+			d.tags[id] = &model.Tag{ID: id, Name: name}
+		}
+		for _, wordID := range wordIDs {
+			d.wordTags[model.WordTag{WordID: wordID, TagID: id}] = true
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) UntagWords(ctx context.Context, wordIDs []int, names []string) error {
+	d, release := s.write()
+	defer release()
+	for _, name := range names {
+		id := d.tagID(name)
+		for _, wordID := range wordIDs {
+			delete(d.wordTags, model.WordTag{WordID: wordID, TagID: id})
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) DeleteTag(ctx context.Context, tag *model.Tag) error {
+	d, release := s.write()
+	defer release()
+	for link := range d.wordTags {
+		if link.TagID == tag.ID {
+			delete(d.wordTags, link)
+		}
+	}
+	delete(d.tags, tag.ID)
+	return nil
+}
+
+func (s *MemoryStore) FindCollection(ctx context.Context, name string) (*model.Collection, error) {
+	d, release := s.read()
+	defer release()
+	for _, collection := range d.collections {
+		if collection.Name == name {
+			c := *collection
+			return &c, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (s *MemoryStore) FindCollectionsByIDs(ctx context.Context, ids []int) ([]*model.Collection, error) {
+	d, release := s.read()
+	defer release()
+	wanted := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+	return sortByName(sortedCopies(d.collections, func(collection *model.Collection) bool { return wanted[collection.ID] }), collectionName), nil
+}
+
+func (s *MemoryStore) ListCollections(ctx context.Context) ([]*model.Collection, error) {
+	d, release := s.read()
+	defer release()
+	return sortByName(sortedCopies(d.collections, func(collection *model.Collection) bool { return true }), collectionName), nil
+}
+
+func (s *MemoryStore) CollectionsOf(ctx context.Context, wordIDs []int) (map[int][]*model.Collection, error) {
+	d, release := s.read()
+	defer release()
+	collections := make(map[int][]*model.Collection, len(wordIDs))
+	for _, id := range wordIDs {
+		collections[id] = []*model.Collection{}
+	}
+	for member := range d.collectionWords {
+		if collections[member.WordID] != nil {
+			c := *d.collections[member.CollectionID]
+			collections[member.WordID] = append(collections[member.WordID], &c)
+		}
+	}
+	for id := range collections {
+		sortByName(collections[id], collectionName)
+	}
+	return collections, nil
+}
+
+// nameTaken reports whether a collection other than the one with given ID has the name.
+func (d *memoryData) nameTaken(name string, id int) bool {
+	for _, collection := range d.collections {
+		if collection.Name == name && collection.ID != id {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *MemoryStore) AddCollection(ctx context.Context, collection *model.Collection) error {
+	d, release := s.write()
+	defer release()
+	if d.nameTaken(collection.Name, 0) {
+		return fmt.Errorf("database error while inserting collection: %w", ErrConflict)
+	}
+	d.lastCollectionID++
+	collection.ID = d.lastCollectionID
+	c := *collection
+	d.collections[c.ID] = &c
+	return nil
+}
+
+func (s *MemoryStore) UpdateCollection(ctx context.Context, collection *model.Collection) error {
+	d, release := s.write()
+	defer release()
+	if d.collections[collection.ID] == nil {
+		return ErrNotFound
+	}
+	if d.nameTaken(collection.Name, collection.ID) {
+		return fmt.Errorf("database error while updating collection: %w", ErrConflict)
+	}
+	c := *collection
+	d.collections[c.ID] = &c
+	return nil
+}
+
+func (s *MemoryStore) DeleteCollection(ctx context.Context, collection *model.Collection) error {
+	d, release := s.write()
+	defer release()
+	for member := range d.collectionWords {
+		if member.CollectionID == collection.ID {
+			delete(d.collectionWords, member)
+		}
+	}
+	delete(d.collections, collection.ID)
+	return nil
+}
+
+func (s *MemoryStore) AddToCollection(ctx context.Context, collectionID int, wordIDs []int) error {
+	d, release := s.write()
+	defer release()
+	if d.collections[collectionID] == nil {
+		return fmt.Errorf("database error while adding words to collection: collection %d must exist", collectionID)
+	}
+	for _, wordID := range wordIDs {
+		if d.words[wordID] == nil {
+			return fmt.Errorf("database error while adding words to collection: word %d must exist", wordID)
+		}
+	}
+	for _, wordID := range wordIDs {
+		d.collectionWords[model.CollectionWord{CollectionID: collectionID, WordID: wordID}] = true
+	}
+	return nil
+}
+
+func (s *MemoryStore) RemoveFromCollection(ctx context.Context, collectionID int, wordIDs []int) error {
+	d, release := s.write()
+	defer release()
+	for _, wordID := range wordIDs {
+		delete(d.collectionWords, model.CollectionWord{CollectionID: collectionID, WordID: wordID})
+	}
+	return nil
+}
+
+// AddTranslation stores the translation with AddTranslations, which links the default senses of its words.
+func (s *MemoryStore) AddTranslation(ctx context.Context, translation model.Translation) error {
+	_, err := s.AddTranslations(ctx, []model.Translation{translation})
+	return err
+}
+
+func (s *MemoryStore) AddTranslations(ctx context.Context, translations []model.Translation) ([]bool, error) {
+	d, release := s.write()
+	defer release()
+	for _, translation := range translations {
+		if d.words[translation.WordID] == nil || d.words[translation.TranslationID] == nil {
+			return nil, fmt.Errorf("database error while inserting translations: words %d and %d must exist", translation.WordID, translation.TranslationID)
+		}
+		for _, id := range []int{translation.SenseID, translation.TranslationSenseID} {
+			if id != 0 && d.senses[id] == nil {
+				return nil, fmt.Errorf("database error while inserting translations: sense %d must exist", id)
+			}
+		}
+	}
+	created := make([]bool, len(translations))
+	for i := range translations {
+		if translations[i].SenseID == 0 {
+			translations[i].SenseID = d.defaultSense(translations[i].WordID)
+		}
+		if translations[i].TranslationSenseID == 0 {
+			translations[i].TranslationSenseID = d.defaultSense(translations[i].TranslationID)
+		}
+		translation := translations[i]
+		translation.SortTranslation()
+		created[i] = !d.translations[translation]
+		d.translations[translation] = true
+	}
+	return created, nil
+}
+
+// DeleteTranslation matches any sense of a word whose sense ID is zero.
+func (s *MemoryStore) DeleteTranslation(ctx context.Context, translation model.Translation) (bool, error) {
+	translation.SortTranslation()
+	d, release := s.write()
+	defer release()
+	deleted := false
+	for stored := range d.translations {
+		if stored.WordID == translation.WordID && stored.TranslationID == translation.TranslationID &&
+			(translation.SenseID == 0 || stored.SenseID == translation.SenseID) &&
+			(translation.TranslationSenseID == 0 || stored.TranslationSenseID == translation.TranslationSenseID) {
+			delete(d.translations, stored)
+			deleted = true
+		}
+	}
+	return deleted, nil
+}
+
+func (s *MemoryStore) ListTranslations(ctx context.Context) ([]model.Translation, error) {
+	d, release := s.read()
+	defer release()
+	translations := make([]model.Translation, 0, len(d.translations))
+	for translation := range d.translations {
+		translations = append(translations, translation)
+	}
+	sortTranslations(translations)
+	return translations, nil
+}
+
+func (s *MemoryStore) TranslationsOfSenses(ctx context.Context, senseIDs []int) ([]model.Translation, error) {
+	d, release := s.read()
+	defer release()
+	wanted := make(map[int]bool, len(senseIDs))
+	for _, id := range senseIDs {
+		wanted[id] = true
+	}
+	translations := make([]model.Translation, 0)
+	for translation := range d.translations {
+		if wanted[translation.SenseID] || wanted[translation.TranslationSenseID] {
+			translations = append(translations, translation)
+		}
+	}
+	sortTranslations(translations)
+	return translations, nil
+}
+
+func (s *MemoryStore) TranslationsOf(ctx context.Context, wordID int) ([]*model.Word, error) {
+	d, release := s.read()
+	defer release()
+	ids := d.translationIDs(wordID, "")
+	return d.sortedWords(func(word *model.Word) bool { return ids[word.ID] }), nil
+}
+
+func (s *MemoryStore) TranslationsOfWords(ctx context.Context, wordIDs []int) (map[int][]*model.Word, error) {
+	d, release := s.read()
+	defer release()
+	translated := make(map[int][]*model.Word, len(wordIDs))
+	for _, id := range wordIDs {
+		ids := d.translationIDs(id, "")
+		translated[id] = d.sortedWords(func(word *model.Word) bool { return ids[word.ID] })
+	}
+	return translated, nil
+}
+
+// TranslationPaths walks the same paths as the Postgres query and picks the same one for every word:
+// the shortest, and of those the first when comparing paths as comma separated IDs.
+func (s *MemoryStore) TranslationPaths(ctx context.Context, sourceID int, targetLanguage string, maxHops int) ([][]int, error) {
+	d, release := s.read()
+	defer release()
+	if d.words[sourceID] == nil {
+		return nil, nil
+	}
+
+	type walk struct {
+		path []int
+		key  string
+	}
+	best := make(map[int]walk)
+	frontier := []walk{{path: []int{sourceID}, key: "," + strconv.Itoa(sourceID) + ","}}
+	for hops := 1; hops <= maxHops && len(frontier) > 0; hops++ {
+		var next []walk
+		for _, w := range frontier {
+			last := w.path[len(w.path)-1]
+			if hops > 1 && d.words[last].Language == targetLanguage {
+				continue
+			}
+			for id := range d.translationIDs(last, "") {
+				if strings.Contains(w.key, ","+strconv.Itoa(id)+",") {
+					continue
+				}
+				path := append(append([]int{}, w.path...), id)
+				next = append(next, walk{path: path, key: w.key + strconv.Itoa(id) + ","})
+			}
+		}
+		for _, w := range next {
+			id := w.path[len(w.path)-1]
+			if d.words[id].Language != targetLanguage {
+				continue
+			}
+			if found, ok := best[id]; !ok || len(w.path) == len(found.path) && w.key < found.key {
+				best[id] = w
+			}
+		}
+		frontier = next
+	}
+
+	walks := make([]walk, 0, len(best))
+	for _, w := range best {
+		walks = append(walks, w)
+	}
+	sort.Slice(walks, func(i, j int) bool {
+		if len(walks[i].path) != len(walks[j].path) {
+			return len(walks[i].path) < len(walks[j].path)
+		}
+		return walks[i].key < walks[j].key
+	})
+	paths := make([][]int, len(walks))
+	for i, w := range walks {
+		paths[i] = w.path
+	}
+	return paths, nil
+}
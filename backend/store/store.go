@@ -0,0 +1,204 @@
+// Package store defines how the dictionary is persisted, independently of the GraphQL layer.
+package store
+
+import (
+	"backend/graph/model"
+	"context"
+	"errors"
+)
+
+var (
+	// ErrNotFound is returned when a looked up record does not exist.
+	ErrNotFound = errors.New("record not found")
+	// ErrTimeout is returned when an operation hits its deadline.
+	ErrTimeout = errors.New("operation timed out")
+	// ErrConflict is returned when a change would duplicate a record which has to be unique.
+	ErrConflict = errors.New("record already exists")
+	// ErrUnsupportedLanguage is returned when a language code resolves to no registered language.
+	ErrUnsupportedLanguage = errors.New("unsupported language")
+)
+
+// wordKey identifies a word, texts are unique within a language.
+type wordKey struct {
+	text     string
+	language string
+}
+
+// WordFilter restricts listed words, zero fields match any word.
+type WordFilter struct {
+	Language string
+	// TextPrefix matches normalized texts starting with it.
+	TextPrefix string
+	// TranslationOf matches translations of the word with this ID.
+	TranslationOf int
+	// PartOfSpeech matches words with a sense of this part of speech. Along with TranslationOf, it matches
+	// translations of the senses of that word with this part of speech instead.
+	PartOfSpeech model.PartOfSpeech
+	// Tags matches words with every tag of these names.
+	Tags []string
+	// CollectionID matches words of the collection with this ID.
+	CollectionID int
+}
+
+// Page selects a range of words ordered by ID. Zero AfterID and BeforeID leave the range unbounded.
+// Backward pages take the last Limit words of the range instead of the first.
+type Page struct {
+	AfterID  int
+	BeforeID int
+	Limit    int
+	Backward bool
+}
+
+// WordPage is a page of words ordered by ID, along with the number of words matching the filter.
+type WordPage struct {
+	Words           []*model.Word
+	Total           int
+	HasNextPage     bool
+	HasPreviousPage bool
+}
+
+// DictionaryStore persists words with their senses, examples and pronunciations, the translations between them,
+// languages they are in and tags and collections grouping them. Texts passed to it are expected to be normalized,
+// see NormalizeWord. Audio of recordings is not kept by the store, it only stores the keys of their blobs.
+type DictionaryStore interface {
+	// Transaction runs fn in a transaction, which is committed when fn returns nil and rolled back otherwise.
+	// Calling Transaction on the store passed to fn runs in the same transaction.
+	Transaction(ctx context.Context, fn func(tx DictionaryStore) error) error
+
+	// FindLanguage returns the language with given canonical code.
+	FindLanguage(ctx context.Context, code string) (*model.Language, error)
+	// FindLanguageByName returns the language with given English or native name, compared case insensitively.
+	FindLanguageByName(ctx context.Context, name string) (*model.Language, error)
+	ListLanguages(ctx context.Context) ([]*model.Language, error)
+	// AddLanguage stores the language unless one with the same code exists, in which case language is set to it.
+	AddLanguage(ctx context.Context, language *model.Language) (created bool, err error)
+
+	FindWord(ctx context.Context, text string, language string) (*model.Word, error)
+	FindWordsByIDs(ctx context.Context, ids []int) ([]*model.Word, error)
+	// FindOrCreateWord stores the word unless one with the same text and language exists, in which case word is set to it.
+	// Created words get a default sense.
+	FindOrCreateWord(ctx context.Context, word *model.Word) (created bool, err error)
+	// FindOrCreateWords is FindOrCreateWord for many words at once. Words repeated in the slice are created once.
+	FindOrCreateWords(ctx context.Context, words []*model.Word) (created []bool, err error)
+	UpdateWord(ctx context.Context, word *model.Word) error
+	// DeleteWord deletes the word along with its senses, examples, pronunciations, recordings and translations,
+	// removing it from its tags and collections.
+	DeleteWord(ctx context.Context, word *model.Word) error
+	ListWords(ctx context.Context, filter WordFilter, page Page) (*WordPage, error)
+	// SimilarWords returns candidates for words of given language within maxDistance edits of text, best first.
+	// Candidates are not guaranteed to be within the distance, callers are expected to rank them.
+	SimilarWords(ctx context.Context, text string, language string, maxDistance int, limit int) ([]*model.Word, error)
+
+	// FindSensesByIDs returns the senses with given IDs, ordered by ID.
+	FindSensesByIDs(ctx context.Context, ids []int) ([]*model.Sense, error)
+	// SensesOf returns the senses of the words with given IDs, ordered by ID. Every word gets an entry.
+	SensesOf(ctx context.Context, wordIDs []int) (map[int][]*model.Sense, error)
+	// FindOrCreateSense stores the sense unless its word has one with the same part of speech and gloss,
+	// in which case sense is set to it.
+	FindOrCreateSense(ctx context.Context, sense *model.Sense) (created bool, err error)
+	UpdateSense(ctx context.Context, sense *model.Sense) error
+	// DeleteSense deletes the sense along with its translations, its examples are kept for the word.
+	DeleteSense(ctx context.Context, sense *model.Sense) error
+
+	// FindExamplesByIDs returns the examples with given IDs, ordered by ID.
+	FindExamplesByIDs(ctx context.Context, ids []int) ([]*model.Example, error)
+	// ExamplesOf returns the examples of the words with given IDs, ordered by ID. Every word gets an entry.
+	ExamplesOf(ctx context.Context, wordIDs []int) (map[int][]*model.Example, error)
+	// AddExample stores the example under a new ID, which is set in example. Links to translated examples
+	// are kept mutual like in UpdateExample.
+	AddExample(ctx context.Context, example *model.Example) error
+	// UpdateExample stores the example. The example it is a translation of is linked back to it,
+	// examples previously linked to either of the two are unlinked.
+	UpdateExample(ctx context.Context, example *model.Example) error
+	// DeleteExample deletes the example, unlinking the example it is a translation of.
+	DeleteExample(ctx context.Context, example *model.Example) error
+
+	// FindPronunciationsByIDs returns the pronunciations with given IDs, ordered by ID.
+	FindPronunciationsByIDs(ctx context.Context, ids []int) ([]*model.Pronunciation, error)
+	// PronunciationsOf returns the pronunciations of the words with given IDs, ordered by ID. Every word gets an entry.
+	PronunciationsOf(ctx context.Context, wordIDs []int) (map[int][]*model.Pronunciation, error)
+	// FindOrCreatePronunciation stores the pronunciation unless its word has one with the same transcription
+	// and accent, in which case pronunciation is set to it.
+	FindOrCreatePronunciation(ctx context.Context, pronunciation *model.Pronunciation) (created bool, err error)
+	UpdatePronunciation(ctx context.Context, pronunciation *model.Pronunciation) error
+	DeletePronunciation(ctx context.Context, pronunciation *model.Pronunciation) error
+
+	// FindRecordingsByIDs returns the recordings with given IDs, ordered by ID.
+	FindRecordingsByIDs(ctx context.Context, ids []int) ([]*model.Recording, error)
+	// RecordingsOf returns the recordings of the words with given IDs, ordered by ID. Every word gets an entry.
+	RecordingsOf(ctx context.Context, wordIDs []int) (map[int][]*model.Recording, error)
+	// AddRecording stores the recording under a new ID, which is set in recording.
+	AddRecording(ctx context.Context, recording *model.Recording) error
+	DeleteRecording(ctx context.Context, recording *model.Recording) error
+
+	// ListTags returns all tags ordered by name.
+	ListTags(ctx context.Context) ([]*model.Tag, error)
+	// FindTagsByIDs returns the tags with given IDs, ordered by name.
+	FindTagsByIDs(ctx context.Context, ids []int) ([]*model.Tag, error)
+	// TagsOf returns the tags of the words with given IDs, ordered by name. Every word gets an entry.
+	TagsOf(ctx context.Context, wordIDs []int) (map[int][]*model.Tag, error)
+	// TagWords tags the words with given IDs with the tags of given names, creating the missing tags.
+	// Words keep the tags they have already.
+	TagWords(ctx context.Context, wordIDs []int, names []string) error
+	// UntagWords removes the tags of given names from the words with given IDs. Tags are kept without words.
+	UntagWords(ctx context.Context, wordIDs []int, names []string) error
+	// DeleteTag deletes the tag, removing it from its words.
+	DeleteTag(ctx context.Context, tag *model.Tag) error
+
+	// FindCollection returns the collection with given name.
+	FindCollection(ctx context.Context, name string) (*model.Collection, error)
+	// FindCollectionsByIDs returns the collections with given IDs, ordered by name.
+	FindCollectionsByIDs(ctx context.Context, ids []int) ([]*model.Collection, error)
+	// ListCollections returns all collections ordered by name.
+	ListCollections(ctx context.Context) ([]*model.Collection, error)
+	// CollectionsOf returns the collections of the words with given IDs, ordered by name. Every word gets an entry.
+	CollectionsOf(ctx context.Context, wordIDs []int) (map[int][]*model.Collection, error)
+	// AddCollection stores the collection under a new ID, which is set in collection. Fails with ErrConflict
+	// when another collection has its name, as does UpdateCollection.
+	AddCollection(ctx context.Context, collection *model.Collection) error
+	UpdateCollection(ctx context.Context, collection *model.Collection) error
+	// DeleteCollection deletes the collection, its words are kept.
+	DeleteCollection(ctx context.Context, collection *model.Collection) error
+	// AddToCollection adds the words with given IDs to the collection, words in it already are kept.
+	AddToCollection(ctx context.Context, collectionID int, wordIDs []int) error
+	// RemoveFromCollection removes the words with given IDs from the collection.
+	RemoveFromCollection(ctx context.Context, collectionID int, wordIDs []int) error
+
+	// AddTranslation stores the translation unless it exists.
+	AddTranslation(ctx context.Context, translation model.Translation) error
+	// AddTranslations stores the translations which do not exist, reporting which of them were created.
+	// Translations repeated in the slice are created once. Translations without sense IDs link the default
+	// senses of their words, which are created when missing and set in the slice.
+	AddTranslations(ctx context.Context, translations []model.Translation) (created []bool, err error)
+	// DeleteTranslation deletes the translation, reporting whether it existed. A translation without sense IDs
+	// deletes every translation between the senses of its words.
+	DeleteTranslation(ctx context.Context, translation model.Translation) (deleted bool, err error)
+	// ListTranslations returns all translations ordered by WordID, TranslationID, SenseID and TranslationSenseID.
+	// Words translated in several senses are listed once per pair of senses.
+	ListTranslations(ctx context.Context) ([]model.Translation, error)
+	// TranslationsOfSenses returns the translations linking any of the senses with given IDs,
+	// ordered like ListTranslations.
+	TranslationsOfSenses(ctx context.Context, senseIDs []int) ([]model.Translation, error)
+	// TranslationsOf returns the words translations of the word with given ID point to, ordered by ID.
+	TranslationsOf(ctx context.Context, wordID int) ([]*model.Word, error)
+	// TranslationsOfWords is TranslationsOf for many words at once, in a number of queries which does not grow
+	// with the number of words. Every word gets an entry, empty when it has no translations.
+	TranslationsOfWords(ctx context.Context, wordIDs []int) (map[int][]*model.Word, error)
+	// TranslationPaths returns paths of word IDs leading from the source word to words of targetLanguage
+	// through at most maxHops translations. Every reached word gets one path, the shortest one.
+	// Words of targetLanguage are not used as intermediate steps.
+	TranslationPaths(ctx context.Context, sourceID int, targetLanguage string, maxHops int) ([][]int, error)
+}
+
+// uniqueStrings returns the strings without repetitions, in the order they first appear.
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	unique := make([]string, 0, len(values))
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
@@ -1,27 +1,67 @@
 package tests
 
 import (
+	"backend/blob"
 	"backend/database"
+	"backend/events"
+	"backend/exchange"
 	"backend/graph"
+	"backend/store"
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/stretchr/testify/require"
+	"io"
 	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"backend/graph/model"
+	"github.com/99designs/gqlgen/client"
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
 	"github.com/joho/godotenv"
 	"github.com/stretchr/testify/assert"
+	"github.com/vektah/gqlparser/v2/gqlerror"
 	"gorm.io/gorm"
 )
 
 var dbInstance *gorm.DB
 var once sync.Once
 
+// testStoreKind selects the store the suite runs against: "memory" (the default), "sqlite" using a fresh
+// database file for every test, or "postgres" using the database started with docker-compose-tests.yml.
+var testStoreKind = os.Getenv("TEST_STORE")
+
+// testEnv is what a test runs against, db is nil for the memory store.
+type testEnv struct {
+	db    *gorm.DB
+	store store.DictionaryStore
+}
+
+// testEnvs holds the environment of every running test, so its mutation and query resolvers share data.
+var testEnvs sync.Map
+
 func init() {
+	switch testStoreKind {
+	case "", "memory", "sqlite":
+		return
+	case "postgres":
+	default:
+		log.Fatalf("unknown TEST_STORE %q, use memory, sqlite or postgres", testStoreKind)
+	}
+
 	envPath, err := filepath.Abs("../../.env.test")
 	if err != nil {
 		log.Fatalf("Error resolving .env.test path: %v", err)
@@ -32,32 +72,53 @@ func init() {
 	}
 }
 
-func setupTestMutation(t *testing.T) (*gorm.DB, graph.MutationResolver) {
-	db := setupTestDB()
-	r := (&graph.Resolver{DB: db}).Mutation()
-
-	t.Cleanup(func() {
-		err := clearTestTables(db)
-		if err != nil {
-			t.Fatalf("Failed to clear test tables: %v", err)
-		}
-	})
+func setupTestMutation(t *testing.T) (store.DictionaryStore, graph.MutationResolver) {
+	s := setupTestEnv(t).store
+	return s, (&graph.Resolver{Store: s}).Mutation()
+}
 
-	return db, r
+func setupTestQuery(t *testing.T) (store.DictionaryStore, graph.QueryResolver) {
+	s := setupTestEnv(t).store
+	return s, (&graph.Resolver{Store: s}).Query()
 }
 
-func setupTestQuery(t *testing.T) (*gorm.DB, graph.QueryResolver) {
-	db := setupTestDB()
-	r := (&graph.Resolver{DB: db}).Query()
+func setupTestEnv(t *testing.T) *testEnv {
+	if env, ok := testEnvs.Load(t); ok {
+		return env.(*testEnv)
+	}
 
-	t.Cleanup(func() {
-		err := clearTestTables(db)
-		if err != nil {
-			t.Fatalf("Failed to clear test tables: %v", err)
-		}
-	})
+	env := &testEnv{}
+	switch testStoreKind {
+	case "postgres":
+		env.db = setupTestDB()
+		t.Cleanup(func() {
+			err := clearTestTables(env.db)
+			if err != nil {
+				t.Fatalf("Failed to clear test tables: %v", err)
+			}
+		})
+	case "sqlite":
+		var err error
+		env.db, err = database.Open(database.Config{Driver: database.DriverSQLite, SQLitePath: filepath.Join(t.TempDir(), "test.db")})
+		require.NoError(t, err, "Failed to open SQLite database")
+		t.Cleanup(func() {
+			if sqlDB, err := env.db.DB(); err == nil {
+				sqlDB.Close()
+			}
+		})
+	}
 
-	return db, r
+	if env.db != nil {
+		env.store = store.NewGorm(env.db, 0)
+	} else {
+		memory := store.NewMemory(0)
+		require.NoError(t, database.SeedLanguages(context.Background(), memory), "Failed to seed languages")
+		env.store = memory
+	}
+
+	testEnvs.Store(t, env)
+	t.Cleanup(func() { testEnvs.Delete(t) })
+	return env
 }
 
 func setupTestDB() *gorm.DB {
@@ -75,11 +136,27 @@ func clearTestTables(db *gorm.DB) error {
 	if err := db.Exec("TRUNCATE TABLE translations CASCADE").Error; err != nil {
 		return fmt.Errorf("error truncating translations table: %v", err)
 	}
+
+	if err := db.Exec("TRUNCATE TABLE tags, collections RESTART IDENTITY CASCADE").Error; err != nil {
+		return fmt.Errorf("error truncating tags and collections tables: %v", err)
+	}
 	return nil
 }
 
+func countWords(t *testing.T, s store.DictionaryStore) int {
+	page, err := s.ListWords(context.Background(), store.WordFilter{}, store.Page{})
+	require.NoError(t, err, "Expected no error when counting words")
+	return page.Total
+}
+
+func countTranslations(t *testing.T, s store.DictionaryStore) int {
+	translations, err := s.ListTranslations(context.Background())
+	require.NoError(t, err, "Expected no error when counting translations")
+	return len(translations)
+}
+
 func TestAddTranslation(t *testing.T) {
-	db, r := setupTestMutation(t)
+	s, r := setupTestMutation(t)
 
 	englishWord := "hello"
 	polishWord := "cześć"
@@ -90,10 +167,9 @@ func TestAddTranslation(t *testing.T) {
 	assert.NotZero(t, translation.WordID, "Translation should have a valid WordID")
 	assert.NotZero(t, translation.TranslationID, "Translation should have a valid TranslationID")
 
-	var count int64
-	db.Model(&model.Translation{}).Where("word_id = ? AND translation_id = ?", translation.WordID, translation.TranslationID).Count(&count)
-
-	assert.Equal(t, int64(1), count, "Translation should be stored in the database")
+	translations, err := s.ListTranslations(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []model.Translation{*translation}, translations, "Translation should be stored in the database")
 }
 
 func TestAddTranslation_DuplicateEntry(t *testing.T) {
@@ -110,7 +186,7 @@ func TestAddTranslation_DuplicateEntry(t *testing.T) {
 }
 
 func TestAddTranslation_Concurrent(t *testing.T) {
-	db, r := setupTestMutation(t)
+	s, r := setupTestMutation(t)
 
 	englishWord := "hello"
 	polishWord := "cześć"
@@ -120,13 +196,11 @@ func TestAddTranslation_Concurrent(t *testing.T) {
 		return err
 	})
 
-	var count int64
-	db.Find(&model.Translation{}).Count(&count)
-	assert.Equal(t, int64(1), count, "Translation should be stored in the database")
+	assert.Equal(t, 1, countTranslations(t, s), "Translation should be stored in the database")
 }
 
 func TestAddTranslation_ConcurrencyWithDuplicate(t *testing.T) {
-	db, r := setupTestMutation(t)
+	s, r := setupTestMutation(t)
 
 	englishWord := "hello"
 	polishWord := "cześć"
@@ -139,13 +213,18 @@ func TestAddTranslation_ConcurrencyWithDuplicate(t *testing.T) {
 		return err
 	})
 
-	var count int64
-	db.Find(&model.Translation{}).Count(&count)
-	assert.Equal(t, int64(1), count, "One ranslation should be stored in the database")
+	assert.Equal(t, 1, countTranslations(t, s), "One ranslation should be stored in the database")
+}
+
+// resolveExampleUsage returns the deprecated exampleUsage field of the word.
+func resolveExampleUsage(t *testing.T, s store.DictionaryStore, word *model.Word) string {
+	usage, err := (&graph.Resolver{Store: s}).Word().ExampleUsage(context.Background(), word)
+	require.NoError(t, err)
+	return usage
 }
 
 func TestAddWord_NewWord(t *testing.T) {
-	_, r := setupTestMutation(t)
+	s, r := setupTestMutation(t)
 
 	word := "hello"
 	language := "EN"
@@ -156,12 +235,16 @@ func TestAddWord_NewWord(t *testing.T) {
 	require.NoError(t, err, "Expected no error while adding word")
 	assert.NotNil(t, addedWord, "Added word should not be nil")
 	assert.Equal(t, word, addedWord.Text, "The word should be correctly added")
-	assert.Equal(t, language, addedWord.Language, "The language should be correctly added")
-	assert.Equal(t, exampleUsage, addedWord.ExampleUsage, "The example usage should be correctly added")
+	assert.Equal(t, "en", addedWord.Language, "The language should be canonicalized")
+	assert.Equal(t, exampleUsage, resolveExampleUsage(t, s, addedWord), "The example usage should be correctly added")
+	examples, err := s.ExamplesOf(context.Background(), []int{addedWord.ID})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(examples[addedWord.ID]), "The example usage is an example of the word")
+	assert.Equal(t, model.Example{ID: examples[addedWord.ID][0].ID, WordID: addedWord.ID, Text: exampleUsage, Language: "en"}, *examples[addedWord.ID][0])
 }
 
 func TestAddWord_ExistingWord(t *testing.T) {
-	_, r := setupTestMutation(t)
+	s, r := setupTestMutation(t)
 
 	word := "hello"
 	language := "EN"
@@ -175,8 +258,8 @@ func TestAddWord_ExistingWord(t *testing.T) {
 	require.NoError(t, err, "Expected no error while adding existing word")
 	assert.NotNil(t, addedWord, "Added word should not be nil")
 	assert.Equal(t, word, addedWord.Text, "The word should be the same")
-	assert.Equal(t, language, addedWord.Language, "The language should be the same")
-	assert.Equal(t, exampleUsage, addedWord.ExampleUsage, "The example usage should be the same")
+	assert.Equal(t, "en", addedWord.Language, "The language should be the same")
+	assert.Equal(t, exampleUsage, resolveExampleUsage(t, s, addedWord), "The example usage should be the same")
 }
 
 func TestAddWord_ErrorHandling(t *testing.T) {
@@ -193,7 +276,7 @@ func TestAddWord_ErrorHandling(t *testing.T) {
 }
 
 func TestAddWord_ConcurrentSameWord(t *testing.T) {
-	db, r := setupTestMutation(t)
+	s, r := setupTestMutation(t)
 
 	word := "hello"
 	language := "EN"
@@ -206,13 +289,11 @@ func TestAddWord_ConcurrentSameWord(t *testing.T) {
 		return err
 	})
 
-	var count int64
-	db.Find(&model.Word{}).Count(&count)
-	assert.Equal(t, int64(1), count, "Only one word inserted")
+	assert.Equal(t, 1, countWords(t, s), "Only one word inserted")
 }
 
 func TestAddWord_ConcurrentSameWordNotExistingBefore(t *testing.T) {
-	db, r := setupTestMutation(t)
+	s, r := setupTestMutation(t)
 
 	word := "hello"
 	language := "EN"
@@ -223,30 +304,26 @@ func TestAddWord_ConcurrentSameWordNotExistingBefore(t *testing.T) {
 		return err
 	})
 
-	var count int64
-	db.Find(&model.Word{}).Count(&count)
-	assert.Equal(t, int64(1), count, "Only one word inserted")
+	assert.Equal(t, 1, countWords(t, s), "Only one word inserted")
 }
 
 func TestAddWord_ConcurrentDifferentWords(t *testing.T) {
-	db, r := setupTestMutation(t)
+	s, r := setupTestMutation(t)
 
 	word := "hello"
 	language := "EN"
 	exampleUsage := "A common greeting."
 
 	RunConcurrentTest(t, 1000, func(i int) error {
-		changedWord := word + string(rune(i+64)) //adding some salt
+		changedWord := word + strconv.Itoa(i) //adding some salt which stays distinct after case folding
 		_, err := r.AddWord(context.Background(), changedWord, language, exampleUsage)
 		return err
 	})
-	var count int64
-	db.Find(&model.Word{}).Count(&count)
-	assert.Equal(t, int64(1000), count, "1000 words inserted")
+	assert.Equal(t, 1000, countWords(t, s), "1000 words inserted")
 }
 
 func TestDeleteWord_ExistingWord(t *testing.T) {
-	db, r := setupTestMutation(t)
+	s, r := setupTestMutation(t)
 
 	word := "hello"
 	language := "EN"
@@ -260,12 +337,10 @@ func TestDeleteWord_ExistingWord(t *testing.T) {
 	require.NoError(t, err, "Expected no error while deleting word")
 	assert.NotNil(t, deletedWord, "Deleted word should not be nil")
 	assert.Equal(t, word, deletedWord.Text, "The deleted word should match")
-	assert.Equal(t, language, deletedWord.Language, "The deleted word's language should match")
+	assert.Equal(t, "en", deletedWord.Language, "The deleted word's language should match")
 
-	var count int64
-	err = db.Model(&model.Word{}).Where("text = ? AND language = ?", word, language).Count(&count).Error
-	require.NoError(t, err, "Expected no error when counting words in database")
-	assert.Equal(t, int64(0), count, "Text should be deleted from the database")
+	_, err = s.FindWord(context.Background(), word, "en")
+	assert.ErrorIs(t, err, store.ErrNotFound, "Text should be deleted from the database")
 }
 
 func TestDeleteWord_NonExistingWord(t *testing.T) {
@@ -277,31 +352,27 @@ func TestDeleteWord_NonExistingWord(t *testing.T) {
 	deletedWord, err := r.DeleteWord(context.Background(), word, language)
 
 	require.NoError(t, err, "Not expecting error for non-existing word")
-	assert.Equal(t, 0, deletedWord.ID, "Deleted word should be empty Word for non-existing word")
+	assert.Nil(t, deletedWord, "Nothing should be deleted for non-existing word")
 }
 
 func TestDeleteWord_TranslationsAlsoDeleted(t *testing.T) {
-	db, r := setupTestMutation(t)
+	s, r := setupTestMutation(t)
 
 	englishWord := "hello"
 	polishWord := "cześć"
 
 	_, err := r.AddTranslation(context.Background(), polishWord, "PL", englishWord, "EN")
+	require.NoError(t, err)
 
 	_, err = r.DeleteWord(context.Background(), englishWord, "EN")
+	require.NoError(t, err)
 
-	var count int64
-	err = db.Model(&model.Word{}).Count(&count).Error
-	require.NoError(t, err, "Expected no error when counting words in database")
-	assert.Equal(t, int64(1), count, "Only one word should be deleted from the database")
-
-	err = db.Model(&model.Translation{}).Count(&count).Error
-	require.NoError(t, err, "Expected no error when counting translations in database")
-	assert.Equal(t, int64(0), count, "Translation should be deleted from the database")
+	assert.Equal(t, 1, countWords(t, s), "Only one word should be deleted from the database")
+	assert.Equal(t, 0, countTranslations(t, s), "Translation should be deleted from the database")
 }
 
 func TestUpdateWord_Success(t *testing.T) {
-	_, r := setupTestMutation(t)
+	s, r := setupTestMutation(t)
 
 	sourceWord := "hello"
 	sourceLanguage := "EN"
@@ -311,10 +382,11 @@ func TestUpdateWord_Success(t *testing.T) {
 	_, err := r.AddWord(context.Background(), sourceWord, sourceLanguage, "old usage")
 	assert.NoError(t, err)
 
-	word, err := r.UpdateWord(context.Background(), sourceWord, sourceLanguage, updatedWord, updatedExampleUsage)
+	word, err := r.UpdateWord(context.Background(), nil, &model.WordKeyInput{Text: sourceWord, Language: sourceLanguage},
+		model.UpdateWordInput{Text: &updatedWord, ExampleUsage: &updatedExampleUsage})
 	assert.NoError(t, err)
 	assert.Equal(t, updatedWord, word.Text)
-	assert.Equal(t, updatedExampleUsage, word.ExampleUsage)
+	assert.Equal(t, updatedExampleUsage, resolveExampleUsage(t, s, word))
 }
 
 func TestUpdateWord_WordNotFound(t *testing.T) {
@@ -325,7 +397,8 @@ func TestUpdateWord_WordNotFound(t *testing.T) {
 	updatedWord := "hi"
 	updatedExampleUsage := "updated usage"
 
-	word, err := r.UpdateWord(context.Background(), sourceWord, sourceLanguage, updatedWord, updatedExampleUsage)
+	word, err := r.UpdateWord(context.Background(), nil, &model.WordKeyInput{Text: sourceWord, Language: sourceLanguage},
+		model.UpdateWordInput{Text: &updatedWord, ExampleUsage: &updatedExampleUsage})
 	assert.Error(t, err, "Raising error for updating non existing word")
 	assert.Nil(t, word)
 }
@@ -338,7 +411,8 @@ func TestUpdateWord_EmptyWord(t *testing.T) {
 	updatedWord := "hi"
 	updatedExampleUsage := "updated usage"
 
-	word, err := r.UpdateWord(context.Background(), sourceWord, sourceLanguage, updatedWord, updatedExampleUsage)
+	word, err := r.UpdateWord(context.Background(), nil, &model.WordKeyInput{Text: sourceWord, Language: sourceLanguage},
+		model.UpdateWordInput{Text: &updatedWord, ExampleUsage: &updatedExampleUsage})
 	assert.Error(t, err, "Raising error for updating empty word")
 	assert.Nil(t, word)
 }
@@ -351,15 +425,94 @@ func TestUpdateWord_EmptyLanguage(t *testing.T) {
 	updatedWord := "hi"
 	updatedExampleUsage := "updated usage"
 
-	word, err := r.UpdateWord(context.Background(), sourceWord, sourceLanguage, updatedWord, updatedExampleUsage)
+	word, err := r.UpdateWord(context.Background(), nil, &model.WordKeyInput{Text: sourceWord, Language: sourceLanguage},
+		model.UpdateWordInput{Text: &updatedWord, ExampleUsage: &updatedExampleUsage})
 	assert.Error(t, err, "Raising error for updating empty word")
 	assert.Nil(t, word)
 }
 
+func TestUpdateWord_PartialUpdate(t *testing.T) {
+	s, r := setupTestMutation(t)
+
+	added, err := r.AddWord(context.Background(), "Hello", "EN", "old usage")
+	require.NoError(t, err)
+
+	word, err := r.UpdateWord(context.Background(), globalID(t, added), nil, model.UpdateWordInput{ExampleUsage: ptr("new usage")})
+	require.NoError(t, err)
+	assert.Equal(t, "hello", word.Text, "Fields which are not given are left unchanged")
+	assert.Equal(t, "Hello", word.DisplayText)
+	assert.Equal(t, "new usage", resolveExampleUsage(t, s, word))
+
+	word, err = r.UpdateWord(context.Background(), nil, &model.WordKeyInput{Text: "hello", Language: "English"}, model.UpdateWordInput{Text: ptr("Hi")})
+	require.NoError(t, err)
+	assert.Equal(t, "hi", word.Text)
+	assert.Equal(t, "new usage", resolveExampleUsage(t, s, word))
+
+	word, err = r.UpdateWord(context.Background(), globalID(t, added), nil, model.UpdateWordInput{ExampleUsage: ptr("")})
+	require.NoError(t, err)
+	assert.Equal(t, "", resolveExampleUsage(t, s, word), "An empty string clears the example usage")
+}
+
+func TestUpdateWord_ChangeLanguage(t *testing.T) {
+	s, r := setupTestMutation(t)
+
+	_, err := r.AddTranslation(context.Background(), "Gift", "EN", "prezent", "PL")
+	require.NoError(t, err)
+
+	word, err := r.UpdateWord(context.Background(), nil, &model.WordKeyInput{Text: "gift", Language: "EN"}, model.UpdateWordInput{Language: ptr("German")})
+	require.NoError(t, err)
+	assert.Equal(t, "de", word.Language)
+	assert.Equal(t, "gift", word.Text)
+	assert.Equal(t, "Gift", word.DisplayText)
+
+	_, err = s.FindWord(context.Background(), "gift", "en")
+	assert.ErrorIs(t, err, store.ErrNotFound)
+	translations, err := s.TranslationsOf(context.Background(), word.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(translations), "Translations stay with the word")
+}
+
+func TestUpdateWord_Conflict(t *testing.T) {
+	s, r := setupTestMutation(t)
+
+	_, err := r.AddWord(context.Background(), "hello", "EN", "")
+	require.NoError(t, err)
+	hi, err := r.AddWord(context.Background(), "hi", "EN", "")
+	require.NoError(t, err)
+
+	word, err := r.UpdateWord(context.Background(), globalID(t, hi), nil, model.UpdateWordInput{Text: ptr("Hello"), ExampleUsage: ptr("usage")})
+	assert.ErrorContains(t, err, "already exists")
+	assert.Nil(t, word)
+	assert.Equal(t, "CONFLICT", graph.ErrorPresenter(context.Background(), err).Extensions["code"])
+
+	unchanged, err := s.FindWord(context.Background(), "hi", "en")
+	require.NoError(t, err)
+	assert.Equal(t, "", resolveExampleUsage(t, s, unchanged), "A conflicting update changes nothing")
+}
+
+func TestUpdateWord_InvalidArguments(t *testing.T) {
+	_, r := setupTestMutation(t)
+
+	added, err := r.AddWord(context.Background(), "hello", "EN", "")
+	require.NoError(t, err)
+
+	_, err = r.UpdateWord(context.Background(), nil, nil, model.UpdateWordInput{Text: ptr("hi")})
+	assert.Error(t, err, "Either id or key is required")
+	_, err = r.UpdateWord(context.Background(), globalID(t, added), &model.WordKeyInput{Text: "hello", Language: "EN"}, model.UpdateWordInput{Text: ptr("hi")})
+	assert.Error(t, err, "Only one of id and key is accepted")
+	_, err = r.UpdateWord(context.Background(), globalID(t, added), nil, model.UpdateWordInput{Text: ptr("")})
+	assert.Error(t, err, "Text can not be set to empty")
+	_, err = r.UpdateWord(context.Background(), globalID(t, added), nil, model.UpdateWordInput{Language: ptr("xx")})
+	assert.Error(t, err)
+	missing := globalID(t, &model.Word{ID: added.ID + 100})
+	_, err = r.UpdateWord(context.Background(), missing, nil, model.UpdateWordInput{Text: ptr("hi")})
+	assert.ErrorIs(t, err, store.ErrNotFound)
+}
+
 func TestTranslations_NoWord(t *testing.T) {
 	_, r := setupTestQuery(t)
 
-	words, err := r.GetTranslations(context.Background(), "nonexistent", "PL")
+	words, err := r.GetTranslations(context.Background(), "nonexistent", "PL", nil)
 	assert.Error(t, err, "Error for translation with no existing word")
 	assert.Nil(t, words)
 }
@@ -371,7 +524,7 @@ func TestTranslations_TwoTranslations(t *testing.T) {
 	_, _ = rm.AddTranslation(context.Background(), "biegać", "PL", "run", "EN")
 	_, _ = rm.AddTranslation(context.Background(), "truchtać", "PL", "run", "EN")
 
-	words, err := rq.GetTranslations(context.Background(), "run", "EN")
+	words, err := rq.GetTranslations(context.Background(), "run", "EN", nil)
 	fmt.Println("words:", words[0])
 	assert.Equal(t, 2, len(words))
 	assert.Nil(t, err)
@@ -383,13 +536,13 @@ func TestTranslations_NoTranslation(t *testing.T) {
 	_, rm := setupTestMutation(t)
 
 	_, _ = rm.AddWord(context.Background(), "run", "EN", "")
-	words, err := r.GetTranslations(context.Background(), "run", "EN")
+	words, err := r.GetTranslations(context.Background(), "run", "EN", nil)
 	assert.NoError(t, err, "No error for no translation")
 	assert.Equal(t, 0, len(words))
 }
 
 func TestDeleteTranslationPLtoEN_NormalCase(t *testing.T) {
-	DB, r := setupTestMutation(t)
+	s, r := setupTestMutation(t)
 	englishWord := "hello"
 	polishWord := "cześć"
 
@@ -398,9 +551,7 @@ func TestDeleteTranslationPLtoEN_NormalCase(t *testing.T) {
 	translation, err = r.DeleteTranslation(context.Background(), polishWord, "PL", englishWord, "EN")
 	assert.NoError(t, err)
 	assert.NotNil(t, translation)
-	var count int64
-	DB.Find(&model.Translation{}).Count(&count)
-	assert.Equal(t, int64(0), count)
+	assert.Equal(t, 0, countTranslations(t, s))
 }
 
 func TestDeleteTranslationPLtoEN_NoWords(t *testing.T) {
@@ -410,8 +561,7 @@ func TestDeleteTranslationPLtoEN_NoWords(t *testing.T) {
 
 	translation, err := r.DeleteTranslation(context.Background(), polishWord, "PL", englishWord, "EN")
 	assert.NoError(t, err, "Graceful deletion")
-	assert.Equal(t, 0, translation.TranslationID)
-	assert.Equal(t, 0, translation.WordID)
+	assert.Nil(t, translation, "Nothing should be deleted")
 }
 
 func TestDeleteTranslationPLtoEN_SecondWordNotFound(t *testing.T) {
@@ -422,8 +572,7 @@ func TestDeleteTranslationPLtoEN_SecondWordNotFound(t *testing.T) {
 	_, _ = r.AddWord(context.Background(), polishWord, "PL", "")
 	translation, err := r.DeleteTranslation(context.Background(), polishWord, "PL", englishWord, "EN")
 	assert.NoError(t, err, "Graceful deletion")
-	assert.Equal(t, 0, translation.TranslationID)
-	assert.Equal(t, 0, translation.WordID)
+	assert.Nil(t, translation, "Nothing should be deleted")
 }
 
 func TestDeleteTranslationPLtoEN_NoTranslation(t *testing.T) {
@@ -435,12 +584,11 @@ func TestDeleteTranslationPLtoEN_NoTranslation(t *testing.T) {
 	_, _ = r.AddWord(context.Background(), englishWord, "EN", "")
 	translation, err := r.DeleteTranslation(context.Background(), polishWord, "PL", englishWord, "EN")
 	assert.NoError(t, err, "Graceful deletion")
-	assert.Equal(t, 0, translation.TranslationID)
-	assert.Equal(t, 0, translation.WordID)
+	assert.Nil(t, translation, "Nothing should be deleted")
 }
 
 func TestDeleteWord_Concurrent(t *testing.T) {
-	db, r := setupTestMutation(t)
+	s, r := setupTestMutation(t)
 
 	word := "hello"
 	language := "EN"
@@ -452,13 +600,11 @@ func TestDeleteWord_Concurrent(t *testing.T) {
 		return err
 	})
 
-	var count int64
-	db.Find(&model.Word{}).Count(&count)
-	assert.Equal(t, int64(0), count, "No words in db")
+	assert.Equal(t, 0, countWords(t, s), "No words in db")
 }
 
 func TestDeleteTranslation_Concurrent(t *testing.T) {
-	db, r := setupTestMutation(t)
+	s, r := setupTestMutation(t)
 
 	englishWord := "hello"
 	polishWord := "cześć"
@@ -470,13 +616,11 @@ func TestDeleteTranslation_Concurrent(t *testing.T) {
 		return err
 	})
 
-	var count int64
-	db.Find(&model.Translation{}).Count(&count)
-	assert.Equal(t, int64(0), count, "No words in db")
+	assert.Equal(t, 0, countTranslations(t, s), "No words in db")
 }
 
 func TestUpdateWord_Concurrent(t *testing.T) {
-	db, r := setupTestMutation(t)
+	s, r := setupTestMutation(t)
 
 	word := "hello"
 	language := "EN"
@@ -485,15 +629,2284 @@ func TestUpdateWord_Concurrent(t *testing.T) {
 
 	RunConcurrentTest(t, 1000, func(i int) error {
 		changedExample := "updated " + strconv.Itoa(i)
-		_, err := r.UpdateWord(context.Background(), word, language, word, changedExample)
+		_, err := r.UpdateWord(context.Background(), nil, &model.WordKeyInput{Text: word, Language: language}, model.UpdateWordInput{ExampleUsage: &changedExample})
 		return err
 	})
 
-	var count int64
-	var updatedWord model.Word
-	db.Find(&updatedWord).Count(&count)
-	assert.Equal(t, int64(1), count, "One word in db")
-	assert.Contains(t, updatedWord.ExampleUsage, "updated ", "Word updated")
+	words, err := s.ListWords(context.Background(), store.WordFilter{}, store.Page{Limit: 1})
+	require.NoError(t, err)
+	require.Equal(t, 1, words.Total, "One word in db")
+	assert.Contains(t, resolveExampleUsage(t, s, words.Words[0]), "updated ", "Word updated")
+}
+
+func TestWords_ForwardPagination(t *testing.T) {
+	_, rq := setupTestQuery(t)
+	_, rm := setupTestMutation(t)
+
+	for _, word := range []string{"one", "two", "three", "four", "five"} {
+		_, err := rm.AddWord(context.Background(), word, "EN", "")
+		require.NoError(t, err)
+	}
+
+	first := int32(2)
+	page, err := rq.Words(context.Background(), &first, nil, nil, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int32(5), page.TotalCount)
+	require.Equal(t, 2, len(page.Edges))
+	assert.Equal(t, "one", page.Edges[0].Node.Text)
+	assert.Equal(t, "two", page.Edges[1].Node.Text)
+	assert.True(t, page.PageInfo.HasNextPage)
+	assert.False(t, page.PageInfo.HasPreviousPage)
+
+	page, err = rq.Words(context.Background(), &first, page.PageInfo.EndCursor, nil, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(page.Edges))
+	assert.Equal(t, "three", page.Edges[0].Node.Text)
+	assert.True(t, page.PageInfo.HasNextPage)
+	assert.True(t, page.PageInfo.HasPreviousPage)
+
+	page, err = rq.Words(context.Background(), &first, page.PageInfo.EndCursor, nil, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(page.Edges))
+	assert.Equal(t, "five", page.Edges[0].Node.Text)
+	assert.False(t, page.PageInfo.HasNextPage)
+}
+
+func TestWords_BackwardPagination(t *testing.T) {
+	_, rq := setupTestQuery(t)
+	_, rm := setupTestMutation(t)
+
+	for _, word := range []string{"one", "two", "three", "four", "five"} {
+		_, err := rm.AddWord(context.Background(), word, "EN", "")
+		require.NoError(t, err)
+	}
+
+	last := int32(2)
+	page, err := rq.Words(context.Background(), nil, nil, &last, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(page.Edges))
+	assert.Equal(t, "four", page.Edges[0].Node.Text)
+	assert.Equal(t, "five", page.Edges[1].Node.Text)
+	assert.True(t, page.PageInfo.HasPreviousPage)
+	assert.False(t, page.PageInfo.HasNextPage)
+
+	page, err = rq.Words(context.Background(), nil, nil, &last, page.PageInfo.StartCursor, nil)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(page.Edges))
+	assert.Equal(t, "two", page.Edges[0].Node.Text)
+	assert.Equal(t, "three", page.Edges[1].Node.Text)
+	assert.True(t, page.PageInfo.HasNextPage)
+}
+
+func TestListWords_PagesAroundDeletedWords(t *testing.T) {
+	s := setupTestEnv(t).store
+	ctx := context.Background()
+
+	words := make(map[string]*model.Word)
+	for _, text := range []string{"one", "two", "three", "four", "five", "sześć"} {
+		language := "en"
+		if text == "sześć" {
+			language = "pl"
+		}
+		words[text] = &model.Word{Text: text, DisplayText: text, Language: language}
+		_, err := s.FindOrCreateWord(ctx, words[text])
+		require.NoError(t, err)
+	}
+	require.NoError(t, s.DeleteWord(ctx, words["two"]))
+	err := s.Transaction(ctx, func(tx store.DictionaryStore) error {
+		require.NoError(t, tx.DeleteWord(ctx, words["four"]))
+		return errors.New("rolled back")
+	})
+	require.Error(t, err)
+
+	page, err := s.ListWords(ctx, store.WordFilter{Language: "en"}, store.Page{AfterID: words["one"].ID, Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, 4, page.Total)
+	require.Equal(t, 2, len(page.Words))
+	assert.Equal(t, "three", page.Words[0].Text, "Deleted words are left out")
+	assert.Equal(t, "four", page.Words[1].Text, "Words deleted by a rolled back transaction are kept")
+	assert.True(t, page.HasNextPage)
+	assert.True(t, page.HasPreviousPage)
+
+	page, err = s.ListWords(ctx, store.WordFilter{Language: "en"}, store.Page{AfterID: words["four"].ID, Limit: 2})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(page.Words))
+	assert.Equal(t, "five", page.Words[0].Text)
+	assert.False(t, page.HasNextPage, "Words not matching the filter do not make a next page")
+
+	page, err = s.ListWords(ctx, store.WordFilter{}, store.Page{BeforeID: words["three"].ID, Limit: 2, Backward: true})
+	require.NoError(t, err)
+	assert.Equal(t, 5, page.Total)
+	require.Equal(t, 1, len(page.Words))
+	assert.Equal(t, "one", page.Words[0].Text)
+	assert.False(t, page.HasPreviousPage)
+	assert.True(t, page.HasNextPage)
+}
+
+func TestWords_Filter(t *testing.T) {
+	_, rq := setupTestQuery(t)
+	_, rm := setupTestMutation(t)
+
+	_, _ = rm.AddWord(context.Background(), "run", "EN", "")
+	_, _ = rm.AddWord(context.Background(), "runner", "EN", "")
+	_, _ = rm.AddWord(context.Background(), "walk", "EN", "")
+	_, _ = rm.AddWord(context.Background(), "run", "DE", "")
+
+	language := "EN"
+	prefix := "run"
+	page, err := rq.Words(context.Background(), nil, nil, nil, nil, &model.WordFilter{Language: &language, TextPrefix: &prefix})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), page.TotalCount)
+	assert.Equal(t, 2, len(page.Edges))
+}
+
+func TestWords_InvalidArguments(t *testing.T) {
+	_, rq := setupTestQuery(t)
+
+	size := int32(1)
+	_, err := rq.Words(context.Background(), &size, nil, &size, nil, nil)
+	assert.Error(t, err, "first and last used together")
+
+	size = 1000
+	_, err = rq.Words(context.Background(), &size, nil, nil, nil, nil)
+	assert.Error(t, err, "page size too big")
+
+	cursor := "not a cursor"
+	_, err = rq.Words(context.Background(), nil, &cursor, nil, nil, nil)
+	assert.Error(t, err, "invalid cursor")
+}
+
+func TestTranslationsConnection_Pagination(t *testing.T) {
+	_, rq := setupTestQuery(t)
+	_, rm := setupTestMutation(t)
+
+	_, _ = rm.AddTranslation(context.Background(), "biegać", "PL", "run", "EN")
+	_, _ = rm.AddTranslation(context.Background(), "truchtać", "PL", "run", "EN")
+	_, _ = rm.AddTranslation(context.Background(), "laufen", "DE", "run", "EN")
+	_, _ = rm.AddWord(context.Background(), "walk", "EN", "")
+
+	first := int32(2)
+	page, err := rq.TranslationsConnection(context.Background(), "run", "EN", nil, &first, nil, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), page.TotalCount)
+	assert.Equal(t, 2, len(page.Edges))
+	assert.True(t, page.PageInfo.HasNextPage)
+
+	page, err = rq.TranslationsConnection(context.Background(), "run", "EN", nil, &first, page.PageInfo.EndCursor, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(page.Edges))
+	assert.Equal(t, "laufen", page.Edges[0].Node.Text)
+	assert.False(t, page.PageInfo.HasNextPage)
+}
+
+func TestTranslationsConnection_NoWord(t *testing.T) {
+	_, rq := setupTestQuery(t)
+
+	page, err := rq.TranslationsConnection(context.Background(), "nonexistent", "EN", nil, nil, nil, nil, nil)
+	assert.Error(t, err)
+	assert.Nil(t, page)
+}
+
+func TestSuggestWords_RankedByDistance(t *testing.T) {
+	_, rq := setupTestQuery(t)
+	_, rm := setupTestMutation(t)
+
+	_, _ = rm.AddWord(context.Background(), "hello", "EN", "")
+	_, _ = rm.AddWord(context.Background(), "hallo", "EN", "")
+	_, _ = rm.AddWord(context.Background(), "yellow", "EN", "")
+	_, _ = rm.AddWord(context.Background(), "hello", "DE", "")
+
+	suggestions, err := rq.SuggestWords(context.Background(), "helo", "EN", nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(suggestions))
+	assert.Equal(t, "hello", suggestions[0].Word.Text)
+	assert.Equal(t, int32(1), suggestions[0].Distance)
+	assert.Equal(t, "hallo", suggestions[1].Word.Text)
+	assert.Equal(t, int32(2), suggestions[1].Distance)
+}
+
+func TestSuggestWords_InvalidArguments(t *testing.T) {
+	_, rq := setupTestQuery(t)
+
+	distance := int32(10)
+	_, err := rq.SuggestWords(context.Background(), "helo", "EN", &distance, nil)
+	assert.Error(t, err)
+
+	limit := int32(-1)
+	_, err = rq.SuggestWords(context.Background(), "helo", "EN", nil, &limit)
+	assert.Error(t, err)
+}
+
+func TestTranslations_NoWordSuggestions(t *testing.T) {
+	_, rq := setupTestQuery(t)
+	_, rm := setupTestMutation(t)
+
+	_, _ = rm.AddTranslation(context.Background(), "biegać", "PL", "run", "EN")
+
+	words, err := rq.GetTranslations(context.Background(), "biegac", "PL", nil)
+	require.Error(t, err)
+	assert.Nil(t, words)
+
+	var gqlErr *gqlerror.Error
+	require.True(t, errors.As(err, &gqlErr), "Expected error with suggestions")
+	assert.Equal(t, []string{"biegać"}, gqlErr.Extensions["suggestions"])
+}
+
+func TestTranslateVia_PivotLanguage(t *testing.T) {
+	_, rq := setupTestQuery(t)
+	_, rm := setupTestMutation(t)
+
+	_, _ = rm.AddTranslation(context.Background(), "biegać", "PL", "run", "EN")
+	_, _ = rm.AddTranslation(context.Background(), "run", "EN", "laufen", "DE")
+	_, _ = rm.AddTranslation(context.Background(), "run", "EN", "rennen", "DE")
+
+	paths, err := rq.TranslateVia(context.Background(), "biegać", "PL", "DE", nil)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(paths))
+	for _, path := range paths {
+		assert.Equal(t, "de", path.Word.Language)
+		assert.Equal(t, int32(2), path.Hops)
+		require.Equal(t, 3, len(path.Path))
+		assert.Equal(t, "biegać", path.Path[0].Text)
+		assert.Equal(t, "run", path.Path[1].Text)
+		assert.Equal(t, path.Word.ID, path.Path[2].ID)
+	}
+}
+
+func TestTranslateVia_ShortestPathOnly(t *testing.T) {
+	_, rq := setupTestQuery(t)
+	_, rm := setupTestMutation(t)
+
+	_, _ = rm.AddTranslation(context.Background(), "biegać", "PL", "run", "EN")
+	_, _ = rm.AddTranslation(context.Background(), "run", "EN", "laufen", "DE")
+	_, _ = rm.AddTranslation(context.Background(), "biegać", "PL", "laufen", "DE")
+
+	paths, err := rq.TranslateVia(context.Background(), "biegać", "PL", "DE", nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(paths))
+	assert.Equal(t, "laufen", paths[0].Word.Text)
+	assert.Equal(t, int32(1), paths[0].Hops)
+}
+
+func TestTranslateVia_MaxHops(t *testing.T) {
+	_, rq := setupTestQuery(t)
+	_, rm := setupTestMutation(t)
+
+	_, _ = rm.AddTranslation(context.Background(), "biegać", "PL", "run", "EN")
+	_, _ = rm.AddTranslation(context.Background(), "run", "EN", "correr", "ES")
+	_, _ = rm.AddTranslation(context.Background(), "correr", "ES", "courir", "FR")
+
+	hops := int32(2)
+	paths, err := rq.TranslateVia(context.Background(), "biegać", "PL", "FR", &hops)
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(paths))
+
+	hops = 3
+	paths, err = rq.TranslateVia(context.Background(), "biegać", "PL", "FR", &hops)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(paths))
+	assert.Equal(t, "courir", paths[0].Word.Text)
+
+	hops = 10
+	_, err = rq.TranslateVia(context.Background(), "biegać", "PL", "FR", &hops)
+	assert.Error(t, err)
+}
+
+func TestAddWord_LanguageAliases(t *testing.T) {
+	s, r := setupTestMutation(t)
+
+	for _, language := range []string{"EN", "en", "eng", "English"} {
+		addedWord, err := r.AddWord(context.Background(), "hello", language, "")
+		require.NoError(t, err, "Expected no error for language %q", language)
+		assert.Equal(t, "en", addedWord.Language)
+	}
+
+	assert.Equal(t, 1, countWords(t, s), "All aliases should resolve to the same word")
+}
+
+func TestAddWord_UnsupportedLanguage(t *testing.T) {
+	_, r := setupTestMutation(t)
+
+	addedWord, err := r.AddWord(context.Background(), "hello", "Klingon", "")
+	assert.Error(t, err, "Expected an error for unknown language")
+	assert.Nil(t, addedWord)
+
+	addedWord, err = r.AddWord(context.Background(), "hello", "tlh", "")
+	assert.Error(t, err, "Expected an error for language which is not registered")
+	assert.Nil(t, addedWord)
+}
+
+func TestAddLanguage(t *testing.T) {
+	_, rq := setupTestQuery(t)
+	_, rm := setupTestMutation(t)
+
+	language, err := rm.AddLanguage(context.Background(), "FA", "Persian", nil, nil, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "fa", language.Code)
+	assert.Equal(t, "Arab", language.Script)
+	assert.Equal(t, model.TextDirectionRtl, language.Direction)
+
+	again, err := rm.AddLanguage(context.Background(), "fa", "Farsi", nil, nil, nil, nil)
+	require.NoError(t, err, "Adding existing language returns it")
+	assert.Equal(t, "Persian", again.Name)
+
+	_, err = rm.AddWord(context.Background(), "سلام", "Persian", "")
+	assert.NoError(t, err, "Registered language can be used")
+
+	languages, err := rq.Languages(context.Background())
+	require.NoError(t, err)
+	codes := make([]string, len(languages))
+	for i, l := range languages {
+		codes[i] = l.Code
+	}
+	assert.Contains(t, codes, "fa")
+	assert.Contains(t, codes, "en")
+}
+
+func TestAddLanguage_Invalid(t *testing.T) {
+	_, rm := setupTestMutation(t)
+
+	_, err := rm.AddLanguage(context.Background(), "not a code", "Nothing", nil, nil, nil, nil)
+	assert.Error(t, err, "Invalid BCP 47 code")
+
+	_, err = rm.AddLanguage(context.Background(), "eo", "", nil, nil, nil, nil)
+	assert.Error(t, err, "Empty name")
+
+	script := "Nope1"
+	_, err = rm.AddLanguage(context.Background(), "eo", "Esperanto", nil, &script, nil, nil)
+	assert.Error(t, err, "Invalid script")
+}
+
+func TestAddWord_NormalizedIdentity(t *testing.T) {
+	s, r := setupTestMutation(t)
+
+	decomposed := "cze\u015bc\u0301"
+	for _, text := range []string{"Cześć", "cześć", decomposed, "  CZEŚĆ "} {
+		addedWord, err := r.AddWord(context.Background(), text, "PL", "")
+		require.NoError(t, err, "Expected no error for %q", text)
+		assert.Equal(t, "cześć", addedWord.Text)
+	}
+
+	words, err := s.ListWords(context.Background(), store.WordFilter{}, store.Page{Limit: 2})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(words.Words), "All spellings should resolve to the same word")
+	assert.Equal(t, "cześć", words.Words[0].Text)
+	assert.Equal(t, "Cześć", words.Words[0].DisplayText, "Display form of the first spelling is kept")
+}
+
+func TestTranslations_NormalizedLookup(t *testing.T) {
+	_, rq := setupTestQuery(t)
+	_, rm := setupTestMutation(t)
+
+	_, err := rm.AddTranslation(context.Background(), "Cześć", "PL", "Hello", "EN")
+	require.NoError(t, err)
+
+	words, err := rq.GetTranslations(context.Background(), " CZEŚĆ ", "PL", nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(words))
+	assert.Equal(t, "hello", words[0].Text)
+	assert.Equal(t, "Hello", words[0].DisplayText)
+}
+
+func TestAddWord_CaseSensitiveLanguage(t *testing.T) {
+	s, r := setupTestMutation(t)
+
+	caseSensitive := true
+	_, err := r.AddLanguage(context.Background(), "la", "Latin", nil, nil, nil, &caseSensitive)
+	require.NoError(t, err)
+
+	_, err = r.AddWord(context.Background(), "Roma", "la", "")
+	require.NoError(t, err)
+	_, err = r.AddWord(context.Background(), "roma", "la", "")
+	require.NoError(t, err)
+
+	words, err := s.ListWords(context.Background(), store.WordFilter{Language: "la"}, store.Page{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, words.Total, "Case sensitive language keeps both words")
+}
+
+func TestNormalizeWords_MergesCollisions(t *testing.T) {
+	db := setupTestEnv(t).db
+	if db == nil {
+		t.Skip("Words are normalized by a migration")
+	}
+
+	reverted := migrateDownTo(t, db, 6)
+	require.NoError(t, db.Exec("INSERT INTO words (id, text, language, example_usage) VALUES (1, 'Cześć', 'PL', ''), (2, 'cześć', 'pl', 'Cześć, jak się masz?'), (3, 'hello', 'EN', ''), (4, 'Hi', 'en', '')").Error)
+	require.NoError(t, db.Exec("INSERT INTO senses (id, word_id, part_of_speech, gloss) VALUES (1, 1, '', ''), (2, 2, '', ''), (3, 3, '', ''), (4, 4, '', ''), (5, 2, 'INTERJECTION', 'greeting')").Error)
+	require.NoError(t, db.Exec("INSERT INTO translations (word_id, translation_id, sense_id, translation_sense_id) VALUES (1, 3, 1, 3), (2, 3, 2, 3), (2, 4, 2, 4), (2, 4, 5, 4)").Error)
+	require.NoError(t, db.Exec("INSERT INTO examples (id, word_id, sense_id, text, language) VALUES (1, 2, 2, 'Cześć!', 'PL'), (2, 2, 5, 'Cześć, Tomku.', 'pl')").Error)
+	require.NoError(t, db.Exec("INSERT INTO pronunciations (id, word_id, ipa, accent) VALUES (1, 1, 'ˈt͡ʂɛɕt͡ɕ', ''), (2, 2, 'ˈt͡ʂɛɕt͡ɕ', ''), (3, 2, 'ˈt͡ʂɛɕt͡ɕ', 'Silesian')").Error)
+	require.NoError(t, db.Exec("INSERT INTO recordings (id, word_id, content_type, size, blob_key) VALUES (1, 2, 'audio/mpeg', 4, 'a1')").Error)
+	require.NoError(t, db.Exec("INSERT INTO tags (id, name) VALUES (1, 'greetings'), (2, 'informal')").Error)
+	require.NoError(t, db.Exec("INSERT INTO word_tags (word_id, tag_id) VALUES (1, 1), (2, 1), (2, 2)").Error)
+	require.NoError(t, db.Exec("INSERT INTO collections (id, name) VALUES (1, 'lesson 1')").Error)
+	require.NoError(t, db.Exec("INSERT INTO collection_words (collection_id, word_id) VALUES (1, 2)").Error)
+
+	applied, err := database.MigrateUp(db)
+	require.NoError(t, err)
+	require.Equal(t, reverted, len(applied))
+
+	var words []model.Word
+	db.Order("id").Find(&words)
+	require.Equal(t, 3, len(words))
+	assert.Equal(t, model.Word{ID: 1, Text: "cześć", DisplayText: "Cześć", Language: "pl"}, words[0])
+	assert.Equal(t, model.Word{ID: 3, Text: "hello", DisplayText: "hello", Language: "en"}, words[1])
+	assert.Equal(t, model.Word{ID: 4, Text: "hi", DisplayText: "Hi", Language: "en"}, words[2])
+
+	var senses []model.Sense
+	db.Order("id").Find(&senses)
+	assert.Equal(t, []model.Sense{{ID: 1, WordID: 1}, {ID: 3, WordID: 3}, {ID: 4, WordID: 4}, {ID: 5, WordID: 1, PartOfSpeech: model.PartOfSpeechInterjection, Gloss: "greeting"}}, senses,
+		"Default senses are merged, other senses move to the kept word")
+
+	var translations []model.Translation
+	db.Order("translation_id, sense_id").Find(&translations)
+	assert.Equal(t, []model.Translation{{WordID: 1, TranslationID: 3, SenseID: 1, TranslationSenseID: 3}, {WordID: 1, TranslationID: 4, SenseID: 1, TranslationSenseID: 4}, {WordID: 1, TranslationID: 4, SenseID: 5, TranslationSenseID: 4}}, translations)
+
+	var examples []model.Example
+	db.Order("id").Find(&examples)
+	assert.Equal(t, []model.Example{
+		{ID: 1, WordID: 1, SenseID: ptr(1), Text: "Cześć!", Language: "pl"},
+		{ID: 2, WordID: 1, SenseID: ptr(5), Text: "Cześć, Tomku.", Language: "pl"},
+		{ID: 3, WordID: 1, Text: "Cześć, jak się masz?", Language: "pl"},
+	}, examples, "Examples move to the kept word and its senses, so does the example usage")
+
+	var pronunciations []model.Pronunciation
+	db.Order("id").Find(&pronunciations)
+	assert.Equal(t, []model.Pronunciation{{ID: 1, WordID: 1, IPA: "ˈt͡ʂɛɕt͡ɕ"}, {ID: 3, WordID: 1, IPA: "ˈt͡ʂɛɕt͡ɕ", Accent: "Silesian"}}, pronunciations,
+		"Pronunciations move to the kept word unless it has them")
+	var recordings []model.Recording
+	db.Order("id").Find(&recordings)
+	assert.Equal(t, []model.Recording{{ID: 1, WordID: 1, ContentType: "audio/mpeg", Size: 4, BlobKey: "a1"}}, recordings)
+
+	var wordTags []model.WordTag
+	db.Order("tag_id").Find(&wordTags)
+	assert.Equal(t, []model.WordTag{{WordID: 1, TagID: 1}, {WordID: 1, TagID: 2}}, wordTags, "Tags move to the kept word unless it has them")
+	var collectionWords []model.CollectionWord
+	db.Find(&collectionWords)
+	assert.Equal(t, []model.CollectionWord{{CollectionID: 1, WordID: 1}}, collectionWords)
+}
+
+func TestImportTranslations_Report(t *testing.T) {
+	s, rm := setupTestMutation(t)
+
+	_, err := rm.AddTranslation(context.Background(), "kot", "PL", "cat", "EN")
+	require.NoError(t, err)
+
+	file := "source_text,source_language,target_text,target_language,example_usage\n" +
+		"Pies,pl,dog,en,Pies szczeka.\n" +
+		"kot,pl,cat,en\n" +
+		"Dog,EN,pies,PL\n" +
+		"zzz,xx,a,en\n" +
+		"bad,row\n" +
+		",pl,x,en\n" +
+		"Kot,PL,kot ,pl\n"
+	report, err := rm.ImportTranslations(context.Background(), graphql.Upload{File: strings.NewReader(file)}, nil, nil)
+	require.NoError(t, err)
+	assert.False(t, report.DryRun)
+	assert.Equal(t, int32(1), report.Created)
+	assert.Equal(t, int32(2), report.Duplicates)
+	assert.Equal(t, int32(4), report.Invalid)
+
+	lines := make([]int32, len(report.Issues))
+	statuses := make([]model.ImportRowStatus, len(report.Issues))
+	for i, issue := range report.Issues {
+		lines[i], statuses[i] = issue.Line, issue.Status
+	}
+	assert.Equal(t, []int32{3, 4, 5, 6, 7, 8}, lines, "Issues are reported with line numbers")
+	assert.Equal(t, []model.ImportRowStatus{
+		model.ImportRowStatusDuplicate, model.ImportRowStatusDuplicate,
+		model.ImportRowStatusInvalid, model.ImportRowStatusInvalid, model.ImportRowStatusInvalid,
+		model.ImportRowStatusInvalid,
+	}, statuses)
+	assert.Contains(t, report.Issues[5].Message, "translation of itself", "Words translated into themselves are rejected")
+
+	word, err := s.FindWord(context.Background(), "pies", "pl")
+	require.NoError(t, err)
+	assert.Equal(t, "Pies", word.DisplayText)
+	assert.Equal(t, "Pies szczeka.", resolveExampleUsage(t, s, word))
+	assert.Equal(t, 2, countTranslations(t, s))
+}
+
+func TestImportTranslations_DryRun(t *testing.T) {
+	s, rm := setupTestMutation(t)
+
+	dryRun := true
+	file := "hello,en,cześć,pl\nhello,en,hallo,de\n"
+	report, err := rm.ImportTranslations(context.Background(), graphql.Upload{File: strings.NewReader(file)}, nil, &dryRun)
+	require.NoError(t, err)
+	assert.True(t, report.DryRun)
+	assert.Equal(t, int32(2), report.Created, "Dry run reports what would be created")
+	assert.Equal(t, 0, countWords(t, s), "Dry run stores nothing")
+	assert.Equal(t, 0, countTranslations(t, s), "Dry run stores nothing")
+}
+
+func TestImportTranslations_TSV(t *testing.T) {
+	s, rm := setupTestMutation(t)
+	_, rq := setupTestQuery(t)
+
+	format := model.TranslationFileFormatTsv
+	file := "hello, world\ten\t\"cześć\" świecie\tpl\tSays \"hi\"\n"
+	report, err := rm.ImportTranslations(context.Background(), graphql.Upload{File: strings.NewReader(file)}, &format, nil)
+	require.NoError(t, err)
+	require.Empty(t, report.Issues)
+	assert.Equal(t, int32(1), report.Created)
+
+	words, err := rq.GetTranslations(context.Background(), "hello, world", "en", nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(words))
+	assert.Equal(t, "\"cześć\" świecie", words[0].DisplayText)
+	assert.Equal(t, 2, countWords(t, s))
+}
+
+func TestImportTranslations_ManyBatches(t *testing.T) {
+	s, rm := setupTestMutation(t)
+
+	var file strings.Builder
+	for i := 0; i < 1200; i++ {
+		fmt.Fprintf(&file, "word%d,en,słowo%d,pl\n", i, i)
+	}
+	report, err := rm.ImportTranslations(context.Background(), graphql.Upload{File: strings.NewReader(file.String())}, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1200), report.Created)
+	assert.Equal(t, 2400, countWords(t, s))
+	assert.Equal(t, 1200, countTranslations(t, s))
+}
+
+func TestTMX_RoundTrip(t *testing.T) {
+	s, rm := setupTestMutation(t)
+
+	_, err := rm.AddTranslation(context.Background(), "Pies", "PL", "dog", "EN")
+	require.NoError(t, err)
+	_, err = rm.AddTranslation(context.Background(), "pies", "PL", "Hund", "DE")
+	require.NoError(t, err)
+	_, err = rm.UpdateWord(context.Background(), nil, &model.WordKeyInput{Text: "pies", Language: "PL"}, model.UpdateWordInput{Text: ptr("Pies"), ExampleUsage: ptr("Pies szczeka & gryzie.")})
+	require.NoError(t, err)
+
+	var file bytes.Buffer
+	require.NoError(t, exchange.ExportTMX(context.Background(), s, &file))
+	assert.Contains(t, file.String(), `<tuv xml:lang="pl">`)
+	assert.Contains(t, file.String(), "<note>Pies szczeka &amp; gryzie.</note>")
+	assert.Equal(t, 2, strings.Count(file.String(), "<tu "), "Every translation is a translation unit")
+
+	target := store.NewMemory(0)
+	require.NoError(t, database.SeedLanguages(context.Background(), target))
+	report, err := exchange.ImportTranslations(context.Background(), target, bytes.NewReader(file.Bytes()), model.TranslationFileFormatTmx, false)
+	require.NoError(t, err)
+	require.Empty(t, report.Issues)
+	assert.Equal(t, int32(2), report.Created)
+	assert.Equal(t, 3, countWords(t, target))
+	assert.Equal(t, 2, countTranslations(t, target))
+
+	word, err := target.FindWord(context.Background(), "pies", "pl")
+	require.NoError(t, err)
+	assert.Equal(t, "Pies", word.DisplayText)
+	assert.Equal(t, "Pies szczeka & gryzie.", resolveExampleUsage(t, target, word))
+
+	report, err = exchange.ImportTranslations(context.Background(), s, bytes.NewReader(file.Bytes()), model.TranslationFileFormatTmx, false)
+	require.NoError(t, err)
+	assert.Equal(t, int32(0), report.Created)
+	assert.Equal(t, int32(2), report.Duplicates, "Importing an export again finds every translation")
+}
+
+func TestTMX_ImportUnits(t *testing.T) {
+	s, _ := setupTestMutation(t)
+
+	file := `<?xml version="1.0" encoding="UTF-8"?>
+<tmx version="1.4">
+  <header srclang="en" datatype="plaintext" segtype="phrase" adminlang="en" o-tmf="x" creationtool="x" creationtoolversion="1"/>
+  <body>
+    <tu>
+      <tuv xml:lang="en"><seg>house</seg></tuv>
+      <tuv xml:lang="de"><seg>Haus</seg></tuv>
+      <tuv lang="pl"><seg>dom</seg></tuv>
+    </tu>
+    <tu>
+      <tuv xml:lang="en"><seg>alone</seg></tuv>
+    </tu>
+    <tu>
+      <tuv xml:lang="en"><seg>broken</seg></tuv>
+  </body>
+</tmx>
+`
+	report, err := exchange.ImportTranslations(context.Background(), s, strings.NewReader(file), model.TranslationFileFormatTmx, false)
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), report.Created, "A unit holds a translation for every pair of its variants")
+	require.Equal(t, 2, len(report.Issues))
+	assert.Equal(t, int32(10), report.Issues[0].Line)
+	assert.Equal(t, model.ImportRowStatusInvalid, report.Issues[0].Status)
+	assert.Equal(t, model.ImportRowStatusInvalid, report.Issues[1].Status)
+	assert.Contains(t, report.Issues[1].Message, "malformed TMX")
+	assert.Equal(t, 3, countTranslations(t, s))
+}
+
+func TestTMX_HTTPHandlers(t *testing.T) {
+	s, rm := setupTestMutation(t)
+	_, err := rm.AddTranslation(context.Background(), "kot", "PL", "cat", "EN")
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	exchange.ExportHandler(s, exchange.ExportTMX, "application/x-tmx+xml", "dictionary.tmx").
+		ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/export/tmx", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Header().Get("Content-Disposition"), "dictionary.tmx")
+	assert.Contains(t, recorder.Body.String(), "<seg>kot</seg>")
+
+	importHandler := exchange.ImportHandler(s, model.TranslationFileFormatTmx)
+	recorder = httptest.NewRecorder()
+	importHandler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/import/tmx?dryRun=true", strings.NewReader(
+		`<tmx version="1.4"><body><tu><tuv xml:lang="en"><seg>cat</seg></tuv><tuv xml:lang="de"><seg>Katze</seg></tuv></tu></body></tmx>`)))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{"dryRun":true,"created":1,"duplicates":0,"invalid":0,"issues":[]}`, recorder.Body.String())
+	assert.Equal(t, 2, countWords(t, s), "Dry run stores nothing")
+
+	recorder = httptest.NewRecorder()
+	importHandler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/import/tmx", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	exchange.ExportHandler(store.NewMemory(time.Nanosecond), exchange.ExportTMX, "application/x-tmx+xml", "dictionary.tmx").
+		ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/export/tmx", nil))
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code, "An export failing before writing anything is an error")
+	assert.NotContains(t, recorder.Body.String(), "<tmx")
+	assert.Empty(t, recorder.Header().Get("Content-Disposition"))
+}
+
+// blockingWriter adds a word whenever it is written to, failing the test when the store does not accept it
+// while the export is being written.
+type blockingWriter struct {
+	t  *testing.T
+	rm graph.MutationResolver
+	n  int
+}
+
+func (w *blockingWriter) Write(data []byte) (int, error) {
+	w.n++
+	done := make(chan error, 1)
+	go func() {
+		_, err := w.rm.AddWord(context.Background(), fmt.Sprintf("written%d", w.n), "EN", "")
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		require.NoError(w.t, err)
+	case <-time.After(5 * time.Second):
+		w.t.Fatal("store blocked while the export was written")
+	}
+	return len(data), nil
+}
+
+func TestExports_WriteOutsideTransactions(t *testing.T) {
+	s, rm := setupTestMutation(t)
+	// The export is larger than what a buffered writer holds back.
+	for i := range 100 {
+		_, err := rm.AddTranslation(context.Background(), fmt.Sprintf("kot%d", i), "PL", fmt.Sprintf("cat%d", i), "EN")
+		require.NoError(t, err)
+	}
+	pets := &model.Collection{Name: "Pets"}
+	require.NoError(t, s.AddCollection(context.Background(), pets))
+	words, err := s.ListWords(context.Background(), store.WordFilter{Language: "pl"}, store.Page{Limit: 100})
+	require.NoError(t, err)
+	ids := make([]int, len(words.Words))
+	for i, word := range words.Words {
+		ids[i] = word.ID
+	}
+	require.Len(t, ids, 100)
+	require.NoError(t, s.AddToCollection(context.Background(), pets.ID, ids))
+
+	exports := map[string]func(context.Context, store.DictionaryStore, io.Writer) error{
+		"TMX": exchange.ExportTMX,
+		"TBX": exchange.ExportTBX,
+		"Anki": func(ctx context.Context, s store.DictionaryStore, w io.Writer) error {
+			return exchange.ExportAnki(ctx, s, w, "PL", "EN")
+		},
+		"collection": func(ctx context.Context, s store.DictionaryStore, w io.Writer) error {
+			return exchange.ExportCollection(ctx, s, w, "Pets", "")
+		},
+	}
+	for name, export := range exports {
+		w := &blockingWriter{t: t, rm: rm}
+		require.NoError(t, export(context.Background(), s, w), name)
+		assert.Positive(t, w.n, name)
+	}
+}
+
+func TestTBX_RoundTrip(t *testing.T) {
+	s, rm := setupTestMutation(t)
+
+	_, err := rm.AddTranslation(context.Background(), "Pies", "PL", "dog", "EN")
+	require.NoError(t, err)
+	_, err = rm.AddTranslation(context.Background(), "dog", "EN", "Hund", "DE")
+	require.NoError(t, err)
+	_, err = rm.AddTranslation(context.Background(), "kot", "PL", "cat", "EN")
+	require.NoError(t, err)
+	_, err = rm.UpdateWord(context.Background(), nil, &model.WordKeyInput{Text: "dog", Language: "EN"}, model.UpdateWordInput{ExampleUsage: ptr("The dog barks.")})
+	require.NoError(t, err)
+
+	var file bytes.Buffer
+	require.NoError(t, exchange.ExportTBX(context.Background(), s, &file))
+	assert.Equal(t, 2, strings.Count(file.String(), "<conceptEntry "), "Connected words are one concept")
+	assert.Contains(t, file.String(), `<descrip type="context">The dog barks.</descrip>`)
+
+	target := store.NewMemory(0)
+	require.NoError(t, database.SeedLanguages(context.Background(), target))
+	report, err := exchange.ImportTranslations(context.Background(), target, bytes.NewReader(file.Bytes()), model.TranslationFileFormatTbx, false)
+	require.NoError(t, err)
+	require.Empty(t, report.Issues)
+	assert.Equal(t, int32(3), report.Created)
+	assert.Equal(t, 5, countWords(t, target))
+	assert.Equal(t, 3, countTranslations(t, target), "Translations are restored as they were, not between every pair of terms")
+
+	word, err := target.FindWord(context.Background(), "dog", "en")
+	require.NoError(t, err)
+	assert.Equal(t, "The dog barks.", resolveExampleUsage(t, target, word))
+	_, err = target.FindWord(context.Background(), "pies", "pl")
+	require.NoError(t, err, "Display texts are restored along with normalized texts")
+
+	report, err = exchange.ImportTranslations(context.Background(), s, bytes.NewReader(file.Bytes()), model.TranslationFileFormatTbx, false)
+	require.NoError(t, err)
+	assert.Equal(t, int32(0), report.Created)
+	assert.Equal(t, int32(3), report.Duplicates)
+	assert.Equal(t, 5, countWords(t, s), "Importing an export again creates no words")
+	assert.Equal(t, 3, countTranslations(t, s))
+}
+
+func TestTBX_ImportTermEntries(t *testing.T) {
+	s, _ := setupTestMutation(t)
+
+	file := `<?xml version="1.0" encoding="UTF-8"?>
+<martif type="TBX" xml:lang="en">
+  <text>
+    <body>
+      <termEntry id="e1">
+        <langSet xml:lang="en">
+          <tig><term>house</term></tig>
+          <tig><term>home</term></tig>
+        </langSet>
+        <langSet xml:lang="pl">
+          <ntig>
+            <termGrp><term>dom</term></termGrp>
+            <descripGrp><descrip type="context">Dom stoi.</descrip></descripGrp>
+          </ntig>
+        </langSet>
+      </termEntry>
+      <termEntry id="e2">
+        <langSet xml:lang="en"><tig><term>alone</term></tig></langSet>
+      </termEntry>
+    </body>
+  </text>
+</martif>
+`
+	report, err := exchange.ImportTranslations(context.Background(), s, strings.NewReader(file), model.TranslationFileFormatTbx, false)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), report.Created, "Terms of different languages are translations, synonyms are not")
+	require.Equal(t, 1, len(report.Issues))
+	assert.Equal(t, int32(17), report.Issues[0].Line)
+	assert.Equal(t, model.ImportRowStatusInvalid, report.Issues[0].Status)
+
+	word, err := s.FindWord(context.Background(), "dom", "pl")
+	require.NoError(t, err)
+	assert.Equal(t, "Dom stoi.", resolveExampleUsage(t, s, word))
+}
+
+func TestAnki_Export(t *testing.T) {
+	s, rm := setupTestMutation(t)
+
+	_, err := rm.AddTranslation(context.Background(), "Pies", "PL", "dog", "EN")
+	require.NoError(t, err)
+	_, err = rm.AddTranslation(context.Background(), "hound", "EN", "pies", "PL")
+	require.NoError(t, err)
+	_, err = rm.AddTranslation(context.Background(), "pies", "PL", "Hund", "DE")
+	require.NoError(t, err)
+	_, err = rm.AddTranslation(context.Background(), "kot", "PL", "cat", "EN")
+	require.NoError(t, err)
+	_, err = rm.UpdateWord(context.Background(), nil, &model.WordKeyInput{Text: "pies", Language: "PL"}, model.UpdateWordInput{Text: ptr("Pies"), ExampleUsage: ptr("Pies <szczeka>.")})
+	require.NoError(t, err)
+	pies, err := s.FindWord(context.Background(), "pies", "pl")
+	require.NoError(t, err)
+
+	var deck bytes.Buffer
+	require.NoError(t, exchange.ExportAnki(context.Background(), s, &deck, "Polish", "en"))
+	var notes []string
+	for _, line := range strings.Split(strings.TrimSpace(deck.String()), "\n") {
+		if !strings.HasPrefix(line, "#") {
+			notes = append(notes, line)
+		}
+	}
+	require.Equal(t, 2, len(notes), "Every Polish word translated into English is a note")
+	assert.Equal(t, fmt.Sprintf("dictionary-%d-en\tPies\tdog, hound<br><br><i>Pies &lt;szczeka&gt;.</i>", pies.ID), notes[0],
+		"Translations in both directions are on the back, the example usage below them")
+	assert.Contains(t, deck.String(), "#guid column:1\n")
+
+	_, err = rm.AddTranslation(context.Background(), "doggy", "EN", "pies", "PL")
+	require.NoError(t, err)
+	var updated bytes.Buffer
+	require.NoError(t, exchange.ExportAnki(context.Background(), s, &updated, "pl", "en"))
+	assert.Contains(t, updated.String(), fmt.Sprintf("dictionary-%d-en\tPies\tdog, hound, doggy", pies.ID), "Notes keep their GUID")
+
+	err = exchange.ExportAnki(context.Background(), s, &updated, "pl", "xx")
+	assert.Error(t, err)
+}
+
+func TestDump_RestoreRemapsIDs(t *testing.T) {
+	s, rm := setupTestMutation(t)
+
+	_, err := rm.AddLanguage(context.Background(), "tlh", "Klingon", nil, nil, nil, nil)
+	require.NoError(t, err)
+	_, err = rm.AddTranslation(context.Background(), "Pies", "PL", "dog", "EN")
+	require.NoError(t, err)
+	_, err = rm.AddTranslation(context.Background(), "dog", "EN", "targh", "tlh")
+	require.NoError(t, err)
+	_, err = rm.UpdateWord(context.Background(), nil, &model.WordKeyInput{Text: "dog", Language: "EN"}, model.UpdateWordInput{ExampleUsage: ptr("The dog barks.")})
+	require.NoError(t, err)
+
+	var dump bytes.Buffer
+	require.NoError(t, exchange.Dump(context.Background(), s, &dump))
+	assert.True(t, strings.HasPrefix(dump.String(), `{"type":"dump","version":5}`+"\n"))
+
+	target := store.NewMemory(0)
+	require.NoError(t, database.SeedLanguages(context.Background(), target))
+	_, err = target.FindOrCreateWord(context.Background(), &model.Word{Text: "cat", DisplayText: "cat", Language: "en"})
+	require.NoError(t, err)
+
+	stats, err := exchange.Restore(context.Background(), target, bytes.NewReader(dump.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.LanguagesCreated, "Only the custom language is new")
+	assert.Equal(t, 3, stats.WordsCreated)
+	assert.Equal(t, 2, stats.TranslationsCreated)
+	assert.Equal(t, 4, countWords(t, target))
+
+	dog, err := target.FindWord(context.Background(), "dog", "en")
+	require.NoError(t, err)
+	assert.Equal(t, "The dog barks.", resolveExampleUsage(t, target, dog))
+	translations, err := target.TranslationsOf(context.Background(), dog.ID)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(translations), "Translations follow the words to their new IDs")
+	assert.Equal(t, "Pies", translations[0].DisplayText)
+	assert.Equal(t, "targh", translations[1].Text)
+
+	stats, err = exchange.Restore(context.Background(), target, bytes.NewReader(dump.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, 0, stats.WordsCreated+stats.TranslationsCreated, "Restoring twice adds nothing")
+}
+
+func TestDump_RestoreExampleUsages(t *testing.T) {
+	s := setupTestEnv(t).store
+	dump := `{"type":"dump","version":5}
+{"type":"word","id":7,"text":"dog","language":"en","exampleUsage":"The dog barks."}
+{"type":"example","id":3,"wordId":7,"text":"The dog barks.","language":"en"}
+{"type":"word","id":8,"text":"cat","language":"en","exampleUsage":"The cat meows."}
+`
+	stats, err := exchange.Restore(context.Background(), s, strings.NewReader(dump))
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.Examples)
+
+	for text, usage := range map[string]string{"dog": "The dog barks.", "cat": "The cat meows."} {
+		word, err := s.FindWord(context.Background(), text, "en")
+		require.NoError(t, err)
+		examples, err := s.ExamplesOf(context.Background(), []int{word.ID})
+		require.NoError(t, err)
+		require.Equal(t, 1, len(examples[word.ID]), "Example usages of older dumps become examples once")
+		assert.Equal(t, usage, examples[word.ID][0].Text)
+	}
+}
+
+func TestDump_RestoreLinksExistingExamples(t *testing.T) {
+	s := setupTestEnv(t).store
+	ctx := context.Background()
+	dump := `{"type":"dump","version":5}
+{"type":"word","id":1,"text":"pies","displayText":"Pies","language":"pl"}
+{"type":"example","id":2,"wordId":1,"text":"Pies szczeka.","language":"pl"}
+{"type":"word","id":3,"text":"dog","language":"en"}
+{"type":"example","id":4,"wordId":3,"text":"The dog barks.","language":"en","translationId":2}
+`
+	dog := &model.Word{Text: "dog", DisplayText: "dog", Language: "en"}
+	_, err := s.FindOrCreateWord(ctx, dog)
+	require.NoError(t, err)
+	require.NoError(t, s.AddExample(ctx, &model.Example{WordID: dog.ID, Text: "The dog barks.", Language: "en"}))
+
+	stats, err := exchange.Restore(ctx, s, strings.NewReader(dump))
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.ExamplesCreated)
+
+	pies, err := s.FindWord(ctx, "pies", "pl")
+	require.NoError(t, err)
+	examples, err := s.ExamplesOf(ctx, []int{dog.ID, pies.ID})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(examples[dog.ID]))
+	require.Equal(t, 1, len(examples[pies.ID]))
+	assert.Equal(t, &examples[pies.ID][0].ID, examples[dog.ID][0].TranslatedExampleID, "The existing example is linked to its translation")
+	assert.Equal(t, &examples[dog.ID][0].ID, examples[pies.ID][0].TranslatedExampleID)
+}
+
+func TestDump_RestoreRejectsBrokenDumps(t *testing.T) {
+	s, _ := setupTestMutation(t)
+
+	_, err := exchange.Restore(context.Background(), s, strings.NewReader(`{"type":"dump","version":6}`+"\n"))
+	assert.ErrorContains(t, err, "unsupported dump version 6")
+
+	_, err = exchange.Restore(context.Background(), s, strings.NewReader(`{"type":"word","id":1,"text":"a","language":"en"}`+"\n"))
+	assert.ErrorContains(t, err, "not a dictionary dump")
+
+	dump := `{"type":"dump","version":1}
+{"type":"word","id":1,"text":"kot","language":"pl"}
+{"type":"word","id":2,"text":"cat","language":"en"}
+{"type":"translation","wordId":1,"translationId":3}
+`
+	_, err = exchange.Restore(context.Background(), s, strings.NewReader(dump))
+	assert.ErrorContains(t, err, "line 4")
+	assert.Equal(t, 0, countWords(t, s), "A failing restore stores nothing")
+}
+
+func TestAddWords_AtomicRejectsAll(t *testing.T) {
+	s, rm := setupTestMutation(t)
+
+	input := []*model.WordInput{
+		{Text: "dog", Language: "en"},
+		{Text: "pies", Language: "xx"},
+		{Text: "", Language: "pl"},
+	}
+	payload, err := rm.AddWords(context.Background(), input, nil)
+	require.NoError(t, err)
+	assert.False(t, payload.Committed, "Batches are atomic by default")
+	require.Equal(t, 3, len(payload.Results))
+	assert.Nil(t, payload.Results[0].Error)
+	assert.Nil(t, payload.Results[0].Word, "Nothing is stored")
+	require.NotNil(t, payload.Results[1].Error)
+	assert.Contains(t, payload.Results[1].Error.Message, "unsupported language")
+	require.NotNil(t, payload.Results[2].Error)
+	assert.Equal(t, int32(2), payload.Results[2].Index)
+	assert.Equal(t, 0, countWords(t, s))
+}
+
+func TestAddWords_NonAtomic(t *testing.T) {
+	s, rm := setupTestMutation(t)
+	_, err := rm.AddWord(context.Background(), "cat", "en", "")
+	require.NoError(t, err)
+
+	atomic := false
+	usage := "The dog barks."
+	input := []*model.WordInput{
+		{Text: "Dog", Language: "en", ExampleUsage: &usage},
+		{Text: "pies", Language: "xx"},
+		{Text: "cat", Language: "EN"},
+		{Text: "dog", Language: "English"},
+	}
+	payload, err := rm.AddWords(context.Background(), input, &atomic)
+	require.NoError(t, err)
+	assert.True(t, payload.Committed)
+	assert.True(t, payload.Results[0].Created)
+	assert.Equal(t, "The dog barks.", resolveExampleUsage(t, s, payload.Results[0].Word))
+	require.NotNil(t, payload.Results[1].Error)
+	assert.Equal(t, model.ErrorCodeValidation, payload.Results[1].Error.Code)
+	assert.False(t, payload.Results[2].Created, "Existing words are returned")
+	assert.False(t, payload.Results[3].Created, "Words repeated in the batch are created once")
+	assert.Equal(t, payload.Results[0].Word.ID, payload.Results[3].Word.ID)
+	assert.Equal(t, 2, countWords(t, s))
+}
+
+func TestAddTranslations_Batch(t *testing.T) {
+	s, rm := setupTestMutation(t)
+	_, err := rm.AddTranslation(context.Background(), "kot", "pl", "cat", "en")
+	require.NoError(t, err)
+
+	input := []*model.TranslationInput{
+		{SourceText: "pies", SourceTextLanguage: "pl", TranslatedText: "dog", TranslatedTextLanguage: "en"},
+		{SourceText: "cat", SourceTextLanguage: "en", TranslatedText: "Kot", TranslatedTextLanguage: "pl"},
+		{SourceText: "pies", SourceTextLanguage: "pl", TranslatedText: "Hund", TranslatedTextLanguage: "de"},
+	}
+	payload, err := rm.AddTranslations(context.Background(), input, nil)
+	require.NoError(t, err)
+	assert.True(t, payload.Committed)
+	assert.True(t, payload.Results[0].Created)
+	assert.False(t, payload.Results[1].Created, "Translations are found in either direction")
+	assert.True(t, payload.Results[2].Created)
+	assert.Equal(t, payload.Results[0].Translation.WordID, payload.Results[2].Translation.WordID)
+	assert.Equal(t, 5, countWords(t, s))
+	assert.Equal(t, 3, countTranslations(t, s))
+
+	input = append(input, &model.TranslationInput{SourceText: "a", SourceTextLanguage: "en", TranslatedText: "b", TranslatedTextLanguage: "xx"})
+	input[0].TranslatedText = "doggy"
+	payload, err = rm.AddTranslations(context.Background(), input, nil)
+	require.NoError(t, err)
+	assert.False(t, payload.Committed)
+	assert.NotNil(t, payload.Results[3].Error)
+	assert.Equal(t, 3, countTranslations(t, s), "A failed item rolls back an atomic batch")
+}
+
+func TestDeleteTranslations_Batch(t *testing.T) {
+	s, rm := setupTestMutation(t)
+	_, err := rm.AddTranslation(context.Background(), "kot", "pl", "cat", "en")
+	require.NoError(t, err)
+	_, err = rm.AddTranslation(context.Background(), "pies", "pl", "dog", "en")
+	require.NoError(t, err)
+
+	atomic := false
+	input := []*model.TranslationInput{
+		{SourceText: "cat", SourceTextLanguage: "en", TranslatedText: "kot", TranslatedTextLanguage: "pl"},
+		{SourceText: "cat", SourceTextLanguage: "en", TranslatedText: "pies", TranslatedTextLanguage: "pl"},
+		{SourceText: "unknown", SourceTextLanguage: "en", TranslatedText: "pies", TranslatedTextLanguage: "pl"},
+		{SourceText: "dog", SourceTextLanguage: "xx", TranslatedText: "pies", TranslatedTextLanguage: "pl"},
+	}
+	payload, err := rm.DeleteTranslations(context.Background(), input, &atomic)
+	require.NoError(t, err)
+	assert.True(t, payload.Committed)
+	assert.True(t, payload.Results[0].Deleted)
+	assert.NotNil(t, payload.Results[0].Translation)
+	assert.False(t, payload.Results[1].Deleted)
+	assert.Nil(t, payload.Results[1].Error, "Missing translations are not errors")
+	assert.False(t, payload.Results[2].Deleted)
+	assert.NotNil(t, payload.Results[3].Error)
+	assert.Equal(t, 1, countTranslations(t, s))
+}
+
+func TestAddWords_TooLarge(t *testing.T) {
+	_, rm := setupTestMutation(t)
+
+	input := make([]*model.WordInput, 5001)
+	for i := range input {
+		input[i] = &model.WordInput{Text: fmt.Sprintf("word%d", i), Language: "en"}
+	}
+	_, err := rm.AddWords(context.Background(), input, nil)
+	assert.Error(t, err)
+}
+
+func TestMigrations_DownAndUp(t *testing.T) {
+	s, r := setupTestMutation(t)
+	db := setupTestEnv(t).db
+	if db == nil {
+		t.Skip("Migrations only apply to databases")
+	}
+
+	migrations, err := database.Migrations(db)
+	require.NoError(t, err)
+	require.NotEmpty(t, migrations)
+
+	reverted, err := database.MigrateDown(db, len(migrations))
+	require.NoError(t, err)
+	assert.Equal(t, len(migrations), len(reverted), "All migrations are reverted")
+	assert.Equal(t, migrations[0].Version, reverted[len(reverted)-1].Version, "Latest migration is reverted first")
+
+	statuses, err := database.MigrationStatuses(db)
+	require.NoError(t, err)
+	for _, status := range statuses {
+		assert.Nil(t, status.AppliedAt, "Migration %d should be pending", status.Version)
+	}
+
+	applied, err := database.MigrateUp(db)
+	require.NoError(t, err)
+	assert.Equal(t, len(migrations), len(applied), "All migrations are applied again")
+
+	applied, err = database.MigrateUp(db)
+	require.NoError(t, err)
+	assert.Empty(t, applied, "Applying again is a no-op")
+
+	require.NoError(t, database.SeedLanguages(context.Background(), s))
+	_, err = r.AddWord(context.Background(), "hello", "EN", "")
+	assert.NoError(t, err, "Migrated schema is usable")
+}
+
+func TestAddWord_Timeout(t *testing.T) {
+	var s store.DictionaryStore = store.NewMemory(time.Nanosecond)
+	if db := setupTestEnv(t).db; db != nil {
+		s = store.NewGorm(db, time.Nanosecond)
+	}
+	r := (&graph.Resolver{Store: s}).Mutation()
+
+	addedWord, err := r.AddWord(context.Background(), "hello", "EN", "")
+	require.Error(t, err, "Expected an error when the deadline is hit")
+	assert.Nil(t, addedWord)
+	assert.Equal(t, "TIMEOUT", graph.ErrorPresenter(context.Background(), err).Extensions["code"])
+
+	assert.Equal(t, 0, countWords(t, s), "Nothing should be inserted")
+}
+
+func TestErrorPresenter_Codes(t *testing.T) {
+	s, rm := setupTestMutation(t)
+	_, rq := setupTestQuery(t)
+	code := func(err error) interface{} {
+		require.Error(t, err)
+		return graph.ErrorPresenter(context.Background(), err).Extensions["code"]
+	}
+
+	_, err := rq.GetTranslations(context.Background(), "nonexistent", "PL", nil)
+	assert.Equal(t, "NOT_FOUND", code(err))
+	_, err = rm.UpdateWord(context.Background(), globalID(t, &model.Word{ID: 12345}), nil, model.UpdateWordInput{})
+	assert.Equal(t, "NOT_FOUND", code(err))
+
+	_, err = rm.AddWord(context.Background(), "", "EN", "")
+	assert.Equal(t, "VALIDATION", code(err))
+	_, err = rm.AddWord(context.Background(), "hello", "xx", "")
+	assert.Equal(t, "VALIDATION", code(err), "Unsupported languages are invalid input")
+
+	hello, err := rm.AddWord(context.Background(), "hello", "EN", "")
+	require.NoError(t, err)
+	hi, err := rm.AddWord(context.Background(), "hi", "EN", "")
+	require.NoError(t, err)
+	hi.Text = hello.Text
+	err = s.UpdateWord(context.Background(), hi)
+	assert.ErrorIs(t, err, store.ErrConflict, "Unique violations of the store are conflicts")
+	assert.Equal(t, "CONFLICT", code(err))
+
+	assert.Equal(t, "INTERNAL", code(errors.New("connection refused")))
+}
+
+func TestAddWord_CancelledRequest(t *testing.T) {
+	_, r := setupTestMutation(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	addedWord, err := r.AddWord(ctx, "hello", "EN", "")
+	require.Error(t, err, "Expected an error for cancelled request")
+	assert.Nil(t, addedWord)
+	assert.Nil(t, graph.ErrorPresenter(context.Background(), err).Extensions["code"], "Cancellation is not a timeout")
+}
+
+func TestNode_WordByGlobalID(t *testing.T) {
+	_, rm := setupTestMutation(t)
+	_, rq := setupTestQuery(t)
+
+	added, err := rm.AddWord(context.Background(), "Hello", "EN", "")
+	require.NoError(t, err)
+	id := globalID(t, added)
+	assert.NotEqual(t, strconv.Itoa(added.ID), *id, "IDs are opaque")
+
+	found, err := rq.Node(context.Background(), *id)
+	require.NoError(t, err)
+	require.IsType(t, &model.Word{}, found)
+	assert.Equal(t, added.ID, found.(*model.Word).ID)
+
+	word, err := rq.Word(context.Background(), *id)
+	require.NoError(t, err)
+	require.NotNil(t, word)
+	assert.Equal(t, "Hello", word.DisplayText)
+
+	missing := globalID(t, &model.Word{ID: added.ID + 100})
+	found, err = rq.Node(context.Background(), *missing)
+	require.NoError(t, err, "Missing objects are null")
+	assert.Nil(t, found)
+	word, err = rq.Word(context.Background(), *missing)
+	require.NoError(t, err)
+	assert.Nil(t, word)
+
+	for _, invalid := range []string{strconv.Itoa(added.ID), "not base64!", "V29yZDp4"} {
+		_, err = rq.Node(context.Background(), invalid)
+		assert.ErrorContains(t, err, "invalid ID", invalid)
+	}
+	_, err = rq.Word(context.Background(), "TGFuZ3VhZ2U6MQ==")
+	assert.ErrorContains(t, err, "does not refer to a word", "Language:1 is not a word")
+}
+
+func TestWord_Translations(t *testing.T) {
+	s, rm := setupTestMutation(t)
+	_, err := rm.AddTranslation(context.Background(), "dog", "EN", "pies", "PL")
+	require.NoError(t, err)
+	_, err = rm.AddTranslation(context.Background(), "Hund", "DE", "dog", "EN")
+	require.NoError(t, err)
+	dog, err := s.FindWord(context.Background(), "dog", "en")
+	require.NoError(t, err)
+
+	rw := (&graph.Resolver{Store: s}).Word()
+	translations, err := rw.Translations(context.Background(), dog, nil, nil)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"pies", "hund"}, wordTexts(translations))
+
+	translations, err = rw.Translations(context.Background(), dog, ptr("German"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"hund"}, wordTexts(translations))
+
+	_, err = rw.Translations(context.Background(), dog, ptr("xx"), nil)
+	assert.ErrorIs(t, err, store.ErrUnsupportedLanguage)
+
+	preloaded := &model.Word{ID: dog.ID, Translations: []*model.Word{{Text: "chien", Language: "fr"}}}
+	translations, err = rw.Translations(context.Background(), preloaded, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"chien"}, wordTexts(translations), "Preloaded translations are used")
+}
+
+func TestNode_Query(t *testing.T) {
+	s, rm := setupTestMutation(t)
+	_, err := rm.AddTranslation(context.Background(), "dog", "EN", "pies", "PL")
+	require.NoError(t, err)
+	dog, err := s.FindWord(context.Background(), "dog", "en")
+	require.NoError(t, err)
+	pies, err := s.FindWord(context.Background(), "pies", "pl")
+	require.NoError(t, err)
+
+	response := queryGraphQL(t, s, `query ($id: ID!) {
+		node(id: $id) { id ... on Word { text translations(language: "pl") { id text } } }
+	}`, map[string]interface{}{"id": *globalID(t, dog)})
+	assert.JSONEq(t, fmt.Sprintf(`{"data": {"node": {"id": %q, "text": "dog", "translations": [{"id": %q, "text": "pies"}]}}}`,
+		*globalID(t, dog), *globalID(t, pies)), response)
+}
+
+func TestSenses_PartOfSpeech(t *testing.T) {
+	s, rm := setupTestMutation(t)
+	_, rq := setupTestQuery(t)
+	ctx := context.Background()
+
+	run, err := rm.AddWord(ctx, "run", "EN", "")
+	require.NoError(t, err)
+	biegac, err := rm.AddWord(ctx, "biegać", "PL", "")
+	require.NoError(t, err)
+	bieg, err := rm.AddWord(ctx, "bieg", "PL", "")
+	require.NoError(t, err)
+	_, err = rm.AddTranslation(ctx, "run", "EN", "przebieżka", "PL")
+	require.NoError(t, err)
+
+	verb, err := rm.AddSense(ctx, *globalID(t, run), model.SenseInput{PartOfSpeech: ptr(model.PartOfSpeechVerb), Gloss: ptr(" move fast ")})
+	require.NoError(t, err)
+	assert.Equal(t, "move fast", verb.Gloss)
+	noun, err := rm.AddSense(ctx, *globalID(t, run), model.SenseInput{PartOfSpeech: ptr(model.PartOfSpeechNoun), Gloss: ptr("a jog"), Register: ptr("informal")})
+	require.NoError(t, err)
+	again, err := rm.AddSense(ctx, *globalID(t, run), model.SenseInput{PartOfSpeech: ptr(model.PartOfSpeechVerb), Gloss: ptr("move fast")})
+	require.NoError(t, err)
+	assert.Equal(t, verb.ID, again.ID, "Senses are unique by part of speech and gloss")
+
+	senses, err := s.SensesOf(ctx, []int{run.ID, biegac.ID})
+	require.NoError(t, err)
+	require.Equal(t, 3, len(senses[run.ID]))
+	assert.True(t, senses[run.ID][0].IsDefault(), "Words get a default sense")
+	require.Equal(t, 1, len(senses[biegac.ID]))
+
+	rs := (&graph.Resolver{Store: s}).Sense()
+	verbID, err := rs.ID(ctx, verb)
+	require.NoError(t, err)
+	nounID, err := rs.ID(ctx, noun)
+	require.NoError(t, err)
+	biegacID, err := rs.ID(ctx, senses[biegac.ID][0])
+	require.NoError(t, err)
+	translation, err := rm.AddSenseTranslation(ctx, verbID, biegacID)
+	require.NoError(t, err)
+	assert.Equal(t, model.Translation{WordID: run.ID, TranslationID: biegac.ID, SenseID: verb.ID, TranslationSenseID: senses[biegac.ID][0].ID}, *translation)
+	biegSenses, err := s.SensesOf(ctx, []int{bieg.ID})
+	require.NoError(t, err)
+	biegID, err := rs.ID(ctx, biegSenses[bieg.ID][0])
+	require.NoError(t, err)
+	_, err = rm.AddSenseTranslation(ctx, biegID, nounID)
+	require.NoError(t, err)
+
+	words, err := rq.GetTranslations(ctx, "run", "EN", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"biegać", "bieg", "przebieżka"}, wordTexts(words), "Translations of every sense are listed once")
+	words, err = rq.GetTranslations(ctx, "run", "EN", ptr(model.PartOfSpeechVerb))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"biegać"}, wordTexts(words))
+	words, err = rq.GetTranslations(ctx, "bieg", "PL", ptr(model.PartOfSpeechNoun))
+	require.NoError(t, err)
+	assert.Empty(t, words, "The part of speech is of the senses of the translated word")
+
+	page, err := rq.TranslationsConnection(ctx, "run", "EN", ptr(model.PartOfSpeechNoun), nil, nil, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(page.Edges))
+	assert.Equal(t, "bieg", page.Edges[0].Node.Text)
+	page, err = rq.Words(ctx, nil, nil, nil, nil, &model.WordFilter{PartOfSpeech: ptr(model.PartOfSpeechVerb)})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(page.Edges))
+	assert.Equal(t, "run", page.Edges[0].Node.Text)
+
+	_, err = rm.AddSenseTranslation(ctx, verbID, nounID)
+	assert.ErrorContains(t, err, "senses of a single word")
+	_, err = rm.AddSenseTranslation(ctx, verbID, *globalID(t, bieg))
+	assert.ErrorContains(t, err, "does not refer to a sense")
+	_, err = rm.UpdateSense(ctx, nounID, model.SenseInput{PartOfSpeech: ptr(model.PartOfSpeechVerb), Gloss: ptr("move fast")})
+	assert.Equal(t, "CONFLICT", graph.ErrorPresenter(ctx, err).Extensions["code"])
+	updated, err := rm.UpdateSense(ctx, nounID, model.SenseInput{Register: ptr("")})
+	require.NoError(t, err)
+	assert.Equal(t, model.Sense{ID: noun.ID, WordID: run.ID, PartOfSpeech: model.PartOfSpeechNoun, Gloss: "a jog"}, *updated, "Fields left out are kept")
+
+	deleted, err := rm.DeleteSenseTranslation(ctx, biegID, nounID)
+	require.NoError(t, err)
+	assert.Equal(t, noun.ID, deleted.SenseID)
+	deleted, err = rm.DeleteSenseTranslation(ctx, biegID, nounID)
+	require.NoError(t, err)
+	assert.Nil(t, deleted)
+
+	deletedSense, err := rm.DeleteSense(ctx, verbID)
+	require.NoError(t, err)
+	assert.Equal(t, verb.ID, deletedSense.ID)
+	words, err = rq.GetTranslations(ctx, "run", "EN", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"przebieżka"}, wordTexts(words), "Translations of a deleted sense are deleted")
+	deletedSense, err = rm.DeleteSense(ctx, verbID)
+	require.NoError(t, err)
+	assert.Nil(t, deletedSense)
+}
+
+func TestSenses_Query(t *testing.T) {
+	s, rm := setupTestMutation(t)
+	ctx := context.Background()
+	run, err := rm.AddWord(ctx, "run", "EN", "")
+	require.NoError(t, err)
+	_, err = rm.AddTranslation(ctx, "run", "EN", "bieg", "PL")
+	require.NoError(t, err)
+	verb, err := rm.AddSense(ctx, *globalID(t, run), model.SenseInput{PartOfSpeech: ptr(model.PartOfSpeechVerb)})
+	require.NoError(t, err)
+	biegac, err := rm.AddWord(ctx, "biegać", "PL", "")
+	require.NoError(t, err)
+	senses, err := s.SensesOf(ctx, []int{biegac.ID})
+	require.NoError(t, err)
+	rs := (&graph.Resolver{Store: s}).Sense()
+	verbID, err := rs.ID(ctx, verb)
+	require.NoError(t, err)
+	biegacID, err := rs.ID(ctx, senses[biegac.ID][0])
+	require.NoError(t, err)
+	_, err = rm.AddSenseTranslation(ctx, verbID, biegacID)
+	require.NoError(t, err)
+
+	response := queryGraphQL(t, s, `query ($id: ID!, $sense: ID!) {
+		word(id: $id) {
+			senses { partOfSpeech translations { word { text } } }
+			translations(partOfSpeech: VERB) { text }
+		}
+		node(id: $sense) { id ... on Sense { gloss word { text } } }
+	}`, map[string]interface{}{"id": *globalID(t, run), "sense": verbID})
+	assert.JSONEq(t, fmt.Sprintf(`{"data": {
+		"word": {
+			"senses": [
+				{"partOfSpeech": null, "translations": [{"word": {"text": "bieg"}}]},
+				{"partOfSpeech": "VERB", "translations": [{"word": {"text": "biegać"}}]}
+			],
+			"translations": [{"text": "biegać"}]
+		},
+		"node": {"id": %q, "gloss": "", "word": {"text": "run"}}
+	}}`, verbID), response)
+}
+
+func TestMigrations_DefaultSenses(t *testing.T) {
+	db := setupTestEnv(t).db
+	if db == nil {
+		t.Skip("Migrations only apply to databases")
+	}
+
+	reverted := migrateDownTo(t, db, 2)
+	require.NoError(t, db.Exec("INSERT INTO words (id, text, language) VALUES (1, 'run', 'en'), (2, 'bieg', 'pl'), (3, 'biegać', 'pl')").Error)
+	require.NoError(t, db.Exec("INSERT INTO translations (word_id, translation_id) VALUES (1, 2), (1, 3)").Error)
+	applied, err := database.MigrateUp(db)
+	require.NoError(t, err)
+	require.Equal(t, reverted, len(applied))
+
+	var senses []model.Sense
+	require.NoError(t, db.Order("word_id").Find(&senses).Error)
+	require.Equal(t, 3, len(senses), "Every word gets a default sense")
+	senseIDs := make(map[int]int)
+	for _, sense := range senses {
+		assert.True(t, sense.IsDefault())
+		senseIDs[sense.WordID] = sense.ID
+	}
+	var translations []model.Translation
+	require.NoError(t, db.Order("translation_id").Find(&translations).Error)
+	assert.Equal(t, []model.Translation{
+		{WordID: 1, TranslationID: 2, SenseID: senseIDs[1], TranslationSenseID: senseIDs[2]},
+		{WordID: 1, TranslationID: 3, SenseID: senseIDs[1], TranslationSenseID: senseIDs[3]},
+	}, translations, "Translations link the default senses")
+}
+
+func TestMigrations_ExampleUsages(t *testing.T) {
+	db := setupTestEnv(t).db
+	if db == nil {
+		t.Skip("Migrations only apply to databases")
+	}
+
+	reverted := migrateDownTo(t, db, 3)
+	require.NoError(t, db.Exec("INSERT INTO words (id, text, language, example_usage) VALUES (1, 'dog', 'en', ' The dog barks. '), (2, 'cat', 'en', ''), (3, 'pies', 'pl', NULL)").Error)
+	applied, err := database.MigrateUp(db)
+	require.NoError(t, err)
+	require.Equal(t, reverted, len(applied))
+
+	var examples []model.Example
+	require.NoError(t, db.Order("id").Find(&examples).Error)
+	assert.Equal(t, []model.Example{{ID: 1, WordID: 1, Text: "The dog barks.", Language: "en"}}, examples,
+		"Example usages become examples")
+}
+
+func TestMigrations_NormalizeWords(t *testing.T) {
+	db := setupTestEnv(t).db
+	if db == nil {
+		t.Skip("Migrations only apply to databases")
+	}
+
+	reverted := migrateDownTo(t, db, 6)
+	require.Equal(t, 2, reverted, "Reverting normalize_words only forgets it")
+	require.NoError(t, db.Exec("INSERT INTO words (id, text, display_text, language, example_usage) VALUES (1, 'Hi', '', 'EN', ''), (2, 'hi', 'hi', 'en', 'Hi there!'), (3, 'Dog', '', 'English', '')").Error)
+	applied, err := database.MigrateUp(db)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(applied))
+	assert.Equal(t, "normalize_words", applied[0].Name)
+
+	var words []model.Word
+	require.NoError(t, db.Order("id").Find(&words).Error)
+	assert.Equal(t, []model.Word{
+		{ID: 1, Text: "hi", DisplayText: "Hi", Language: "en"},
+		{ID: 3, Text: "dog", DisplayText: "Dog", Language: "en"},
+	}, words, "Newer words stored normalized already merge into older ones, default languages resolve by name")
+	var examples []model.Example
+	require.NoError(t, db.Find(&examples).Error)
+	assert.Equal(t, []model.Example{{ID: 1, WordID: 1, Text: "Hi there!", Language: "en"}}, examples, "Example usages of merged words are kept")
+
+	applied, err = database.MigrateUp(db)
+	require.NoError(t, err)
+	assert.Empty(t, applied, "Words are normalized once")
+}
+
+// migrateDownTo reverts the migrations newer than version, returning how many were reverted.
+func migrateDownTo(t *testing.T, db *gorm.DB, version int) int {
+	migrations, err := database.Migrations(db)
+	require.NoError(t, err)
+	steps := 0
+	for _, migration := range migrations {
+		if migration.Version > version {
+			steps++
+		}
+	}
+	reverted, err := database.MigrateDown(db, steps)
+	require.NoError(t, err)
+	return len(reverted)
+}
+
+func TestDump_Senses(t *testing.T) {
+	s, rm := setupTestMutation(t)
+	ctx := context.Background()
+	run, err := rm.AddWord(ctx, "run", "EN", "")
+	require.NoError(t, err)
+	_, err = rm.AddTranslation(ctx, "run", "EN", "bieg", "PL")
+	require.NoError(t, err)
+	verb, err := rm.AddSense(ctx, *globalID(t, run), model.SenseInput{PartOfSpeech: ptr(model.PartOfSpeechVerb), Register: ptr("formal")})
+	require.NoError(t, err)
+	biegac, err := rm.AddWord(ctx, "biegać", "PL", "")
+	require.NoError(t, err)
+	_, err = s.AddTranslations(ctx, []model.Translation{{WordID: run.ID, TranslationID: biegac.ID, SenseID: verb.ID}})
+	require.NoError(t, err)
+
+	var dump bytes.Buffer
+	require.NoError(t, exchange.Dump(ctx, s, &dump))
+
+	target := store.NewMemory(0)
+	require.NoError(t, database.SeedLanguages(ctx, target))
+	stats, err := exchange.Restore(ctx, target, bytes.NewReader(dump.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.SensesCreated, "Default senses are created with their words")
+	assert.Equal(t, 2, stats.TranslationsCreated)
+
+	restored, err := target.FindWord(ctx, "run", "en")
+	require.NoError(t, err)
+	page, err := target.ListWords(ctx, store.WordFilter{TranslationOf: restored.ID, PartOfSpeech: model.PartOfSpeechVerb}, store.Page{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"biegać"}, wordTexts(page.Words), "Translations keep their senses")
+	senses, err := target.SensesOf(ctx, []int{restored.ID})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(senses[restored.ID]))
+	assert.Equal(t, "formal", senses[restored.ID][1].Register)
+}
+
+func TestDump_TranslationsAcrossPages(t *testing.T) {
+	s, rm := setupTestMutation(t)
+	ctx := context.Background()
+	words := make([]*model.Word, 600)
+	for i := range words {
+		words[i] = &model.Word{Text: fmt.Sprintf("word%d", i), DisplayText: fmt.Sprintf("word%d", i), Language: "en"}
+	}
+	_, err := s.FindOrCreateWords(ctx, words)
+	require.NoError(t, err)
+	first, last := words[0], words[len(words)-1]
+	verb, err := rm.AddSense(ctx, *globalID(t, first), model.SenseInput{PartOfSpeech: ptr(model.PartOfSpeechVerb)})
+	require.NoError(t, err)
+	_, err = s.AddTranslations(ctx, []model.Translation{{WordID: first.ID, TranslationID: last.ID, SenseID: verb.ID}})
+	require.NoError(t, err)
+
+	var dump bytes.Buffer
+	require.NoError(t, exchange.Dump(ctx, s, &dump))
+	assert.Contains(t, dump.String(), fmt.Sprintf(`{"type":"translation","wordId":%d,"translationId":%d,"senseId":%d}`, first.ID, last.ID, verb.ID),
+		"Senses of words on earlier pages are kept")
+
+	target := store.NewMemory(0)
+	require.NoError(t, database.SeedLanguages(ctx, target))
+	stats, err := exchange.Restore(ctx, target, bytes.NewReader(dump.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, 600, stats.WordsCreated)
+	assert.Equal(t, 1, stats.TranslationsCreated)
+	restored, err := target.FindWord(ctx, "word0", "en")
+	require.NoError(t, err)
+	page, err := target.ListWords(ctx, store.WordFilter{TranslationOf: restored.ID, PartOfSpeech: model.PartOfSpeechVerb}, store.Page{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"word599"}, wordTexts(page.Words))
+}
+
+func TestExamples_Mutations(t *testing.T) {
+	s, rm := setupTestMutation(t)
+	ctx := context.Background()
+	dog, err := rm.AddWord(ctx, "dog", "EN", "")
+	require.NoError(t, err)
+	pies, err := rm.AddWord(ctx, "pies", "PL", "")
+	require.NoError(t, err)
+	noun, err := rm.AddSense(ctx, *globalID(t, dog), model.SenseInput{PartOfSpeech: ptr(model.PartOfSpeechNoun)})
+	require.NoError(t, err)
+	re := (&graph.Resolver{Store: s}).Example()
+	rs := (&graph.Resolver{Store: s}).Sense()
+	nounID, err := rs.ID(ctx, noun)
+	require.NoError(t, err)
+	exampleID := func(example *model.Example) string {
+		id, err := re.ID(ctx, example)
+		require.NoError(t, err)
+		return id
+	}
+	findExample := func(example *model.Example) *model.Example {
+		found, err := s.FindExamplesByIDs(ctx, []int{example.ID})
+		require.NoError(t, err)
+		require.Equal(t, 1, len(found))
+		return found[0]
+	}
+
+	barks, err := rm.AddExample(ctx, *globalID(t, dog), model.ExampleInput{Text: " The dog barks. ", Source: ptr("Tatoeba"), SenseID: &nounID})
+	require.NoError(t, err)
+	assert.Equal(t, model.Example{ID: barks.ID, WordID: dog.ID, SenseID: &noun.ID, Text: "The dog barks.", Language: "en", Source: "Tatoeba"}, *barks,
+		"Examples are in the language of their word unless given")
+	szczeka, err := rm.AddExample(ctx, *globalID(t, pies), model.ExampleInput{Text: "Pies szczeka.", TranslationID: ptr(exampleID(barks))})
+	require.NoError(t, err)
+	assert.Equal(t, &barks.ID, szczeka.TranslatedExampleID)
+	assert.Equal(t, &szczeka.ID, findExample(barks).TranslatedExampleID, "Translated examples are linked both ways")
+
+	_, err = rm.AddExample(ctx, *globalID(t, pies), model.ExampleInput{Text: "Mój pies.", TranslationID: ptr(exampleID(szczeka))})
+	assert.ErrorContains(t, err, "same language")
+	_, err = rm.AddExample(ctx, *globalID(t, pies), model.ExampleInput{Text: "Mój pies.", SenseID: &nounID})
+	assert.ErrorContains(t, err, "is not a sense of the word")
+	_, err = rm.AddExample(ctx, *globalID(t, pies), model.ExampleInput{Text: " "})
+	assert.Equal(t, "VALIDATION", graph.ErrorPresenter(ctx, err).Extensions["code"])
+	_, err = rm.AddExample(ctx, *globalID(t, pies), model.ExampleInput{Text: "Mój pies.", Language: ptr("xx")})
+	assert.ErrorIs(t, err, store.ErrUnsupportedLanguage)
+	_, err = rm.UpdateExample(ctx, exampleID(barks), model.UpdateExampleInput{TranslationID: graphql.OmittableOf(ptr(exampleID(barks)))})
+	assert.ErrorContains(t, err, "translation of itself")
+	_, err = rm.UpdateExample(ctx, exampleID(barks), model.UpdateExampleInput{Language: ptr("PL")})
+	assert.ErrorContains(t, err, "same language", "Linked examples stay in different languages")
+
+	updated, err := rm.UpdateExample(ctx, exampleID(szczeka), model.UpdateExampleInput{Source: ptr("book"), TranslationID: graphql.OmittableOf[*string](nil)})
+	require.NoError(t, err)
+	assert.Equal(t, model.Example{ID: szczeka.ID, WordID: pies.ID, Text: "Pies szczeka.", Language: "pl", Source: "book"}, *updated,
+		"Fields left out are kept, those given as null are removed")
+	assert.Nil(t, findExample(barks).TranslatedExampleID, "Unlinking removes both links")
+
+	howls, err := rm.AddExample(ctx, *globalID(t, dog), model.ExampleInput{Text: "The dog howls.", TranslationID: ptr(exampleID(szczeka))})
+	require.NoError(t, err)
+	_, err = rm.UpdateExample(ctx, exampleID(barks), model.UpdateExampleInput{TranslationID: graphql.OmittableOf(ptr(exampleID(szczeka)))})
+	require.NoError(t, err)
+	assert.Equal(t, &barks.ID, findExample(szczeka).TranslatedExampleID)
+	assert.Nil(t, findExample(howls).TranslatedExampleID, "Relinking an example unlinks its previous translation")
+
+	_, err = rm.DeleteSense(ctx, nounID)
+	require.NoError(t, err)
+	assert.Nil(t, findExample(barks).SenseID, "Examples of a deleted sense are kept for the word")
+
+	deleted, err := rm.DeleteExample(ctx, exampleID(szczeka))
+	require.NoError(t, err)
+	assert.Equal(t, szczeka.ID, deleted.ID)
+	assert.Nil(t, findExample(barks).TranslatedExampleID)
+	deleted, err = rm.DeleteExample(ctx, exampleID(szczeka))
+	require.NoError(t, err)
+	assert.Nil(t, deleted)
+
+	_, err = rm.DeleteWord(ctx, "dog", "EN")
+	require.NoError(t, err)
+	examples, err := s.FindExamplesByIDs(ctx, []int{barks.ID, howls.ID})
+	require.NoError(t, err)
+	assert.Empty(t, examples, "Examples are deleted with their word")
+}
+
+func TestExamples_Query(t *testing.T) {
+	s, rm := setupTestMutation(t)
+	ctx := context.Background()
+	dog, err := rm.AddWord(ctx, "dog", "EN", "")
+	require.NoError(t, err)
+	pies, err := rm.AddWord(ctx, "pies", "PL", "")
+	require.NoError(t, err)
+	noun, err := rm.AddSense(ctx, *globalID(t, dog), model.SenseInput{PartOfSpeech: ptr(model.PartOfSpeechNoun)})
+	require.NoError(t, err)
+	nounID, err := (&graph.Resolver{Store: s}).Sense().ID(ctx, noun)
+	require.NoError(t, err)
+	barks, err := rm.AddExample(ctx, *globalID(t, dog), model.ExampleInput{Text: "The dog barks.", SenseID: &nounID})
+	require.NoError(t, err)
+	barksID, err := (&graph.Resolver{Store: s}).Example().ID(ctx, barks)
+	require.NoError(t, err)
+	_, err = rm.AddExample(ctx, *globalID(t, pies), model.ExampleInput{Text: "Pies szczeka.", Source: ptr("Tatoeba"), TranslationID: &barksID})
+	require.NoError(t, err)
+	_, err = rm.AddExample(ctx, *globalID(t, dog), model.ExampleInput{Text: "Hot dog.", Language: ptr("German")})
+	require.NoError(t, err)
+
+	response := queryGraphQL(t, s, `query ($id: ID!, $example: ID!) {
+		word(id: $id) {
+			examples { text language sense { partOfSpeech } translation { text source word { text } } }
+			senses { partOfSpeech examples { text } }
+		}
+		node(id: $example) { id ... on Example { text word { text } } }
+	}`, map[string]interface{}{"id": *globalID(t, dog), "example": barksID})
+	assert.JSONEq(t, fmt.Sprintf(`{"data": {
+		"word": {
+			"examples": [
+				{"text": "The dog barks.", "language": "en", "sense": {"partOfSpeech": "NOUN"},
+					"translation": {"text": "Pies szczeka.", "source": "Tatoeba", "word": {"text": "pies"}}},
+				{"text": "Hot dog.", "language": "de", "sense": null, "translation": null}
+			],
+			"senses": [
+				{"partOfSpeech": null, "examples": []},
+				{"partOfSpeech": "NOUN", "examples": [{"text": "The dog barks."}]}
+			]
+		},
+		"node": {"id": %q, "text": "The dog barks.", "word": {"text": "dog"}}
+	}}`, barksID), response)
+}
+
+func TestDump_Examples(t *testing.T) {
+	s, rm := setupTestMutation(t)
+	ctx := context.Background()
+	dog, err := rm.AddWord(ctx, "dog", "EN", "")
+	require.NoError(t, err)
+	pies, err := rm.AddWord(ctx, "pies", "PL", "")
+	require.NoError(t, err)
+	senses, err := s.SensesOf(ctx, []int{pies.ID})
+	require.NoError(t, err)
+	defaultSense := senses[pies.ID][0]
+	szczeka := &model.Example{WordID: pies.ID, SenseID: &defaultSense.ID, Text: "Pies szczeka.", Language: "pl", Source: "Tatoeba"}
+	require.NoError(t, s.AddExample(ctx, szczeka))
+	require.NoError(t, s.AddExample(ctx, &model.Example{WordID: dog.ID, Text: "The dog barks.", Language: "en", TranslatedExampleID: &szczeka.ID}))
+
+	var dump bytes.Buffer
+	require.NoError(t, exchange.Dump(ctx, s, &dump))
+	assert.Contains(t, dump.String(), `{"type":"sense","id":`, "Default senses of examples are dumped")
+
+	target := store.NewMemory(0)
+	require.NoError(t, database.SeedLanguages(ctx, target))
+	stats, err := exchange.Restore(ctx, target, bytes.NewReader(dump.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, 2, stats.ExamplesCreated)
+
+	restored, err := target.FindWord(ctx, "pies", "pl")
+	require.NoError(t, err)
+	examples, err := target.ExamplesOf(ctx, []int{restored.ID})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(examples[restored.ID]))
+	example := examples[restored.ID][0]
+	assert.Equal(t, "Tatoeba", example.Source)
+	restoredSenses, err := target.SensesOf(ctx, []int{restored.ID})
+	require.NoError(t, err)
+	assert.Equal(t, &restoredSenses[restored.ID][0].ID, example.SenseID, "Examples keep their senses")
+	require.NotNil(t, example.TranslatedExampleID)
+	translated, err := target.FindExamplesByIDs(ctx, []int{*example.TranslatedExampleID})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(translated))
+	assert.Equal(t, "The dog barks.", translated[0].Text, "Examples keep their translations")
+	assert.Equal(t, &example.ID, translated[0].TranslatedExampleID)
+
+	stats, err = exchange.Restore(ctx, target, bytes.NewReader(dump.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, 0, stats.ExamplesCreated, "Restoring twice adds no examples")
+}
+
+func TestPronunciations_Mutations(t *testing.T) {
+	s, rm := setupTestMutation(t)
+	ctx := context.Background()
+	word, err := rm.AddWord(ctx, "tomato", "EN", "")
+	require.NoError(t, err)
+	rp := (&graph.Resolver{Store: s}).Pronunciation()
+
+	british, err := rm.AddPronunciation(ctx, *globalID(t, word), model.PronunciationInput{IPA: " /təˈmɑːtəʊ/ ", Accent: ptr(" en-GB ")})
+	require.NoError(t, err)
+	assert.Equal(t, model.Pronunciation{ID: british.ID, WordID: word.ID, IPA: "/təˈmɑːtəʊ/", Accent: "en-GB"}, *british)
+	again, err := rm.AddPronunciation(ctx, *globalID(t, word), model.PronunciationInput{IPA: "/təˈmɑːtəʊ/", Accent: ptr("en-GB")})
+	require.NoError(t, err)
+	assert.Equal(t, british.ID, again.ID, "Adding a pronunciation twice returns the first one")
+	american, err := rm.AddPronunciation(ctx, *globalID(t, word), model.PronunciationInput{IPA: "/təˈmeɪtoʊ/"})
+	require.NoError(t, err)
+
+	_, err = rm.AddPronunciation(ctx, *globalID(t, word), model.PronunciationInput{IPA: " "})
+	assert.Equal(t, "VALIDATION", graph.ErrorPresenter(ctx, err).Extensions["code"])
+	_, err = rm.AddPronunciation(ctx, *globalID(t, &model.Word{ID: word.ID + 100}), model.PronunciationInput{IPA: "/x/"})
+	assert.Equal(t, "NOT_FOUND", graph.ErrorPresenter(ctx, err).Extensions["code"])
+
+	americanID, err := rp.ID(ctx, american)
+	require.NoError(t, err)
+	updated, err := rm.UpdatePronunciation(ctx, americanID, model.UpdatePronunciationInput{Accent: ptr("en-US")})
+	require.NoError(t, err)
+	assert.Equal(t, model.Pronunciation{ID: american.ID, WordID: word.ID, IPA: "/təˈmeɪtoʊ/", Accent: "en-US"}, *updated)
+	_, err = rm.UpdatePronunciation(ctx, americanID, model.UpdatePronunciationInput{IPA: ptr("/təˈmɑːtəʊ/"), Accent: ptr("en-GB")})
+	assert.Equal(t, "CONFLICT", graph.ErrorPresenter(ctx, err).Extensions["code"])
+
+	response := queryGraphQL(t, s, `query ($id: ID!, $pronunciation: ID!) {
+		word(id: $id) { pronunciations { ipa accent } }
+		node(id: $pronunciation) { ... on Pronunciation { ipa word { text } } }
+	}`, map[string]interface{}{"id": *globalID(t, word), "pronunciation": americanID})
+	assert.JSONEq(t, `{"data": {
+		"word": {"pronunciations": [{"ipa": "/təˈmɑːtəʊ/", "accent": "en-GB"}, {"ipa": "/təˈmeɪtoʊ/", "accent": "en-US"}]},
+		"node": {"ipa": "/təˈmeɪtoʊ/", "word": {"text": "tomato"}}
+	}}`, response)
+
+	deleted, err := rm.DeletePronunciation(ctx, americanID)
+	require.NoError(t, err)
+	assert.Equal(t, american.ID, deleted.ID)
+	deleted, err = rm.DeletePronunciation(ctx, americanID)
+	require.NoError(t, err)
+	assert.Nil(t, deleted)
+
+	_, err = rm.DeleteWord(ctx, "tomato", "EN")
+	require.NoError(t, err)
+	pronunciations, err := s.FindPronunciationsByIDs(ctx, []int{british.ID})
+	require.NoError(t, err)
+	assert.Empty(t, pronunciations, "Pronunciations are deleted with their word")
+}
+
+func TestRecordings_UploadAndStream(t *testing.T) {
+	s := setupTestEnv(t).store
+	ctx := context.Background()
+	blobs, err := blob.NewLocal(t.TempDir())
+	require.NoError(t, err)
+	resolver := &graph.Resolver{Store: s, Blobs: blobs}
+	rm := resolver.Mutation()
+	word, err := rm.AddWord(ctx, "tomato", "EN", "")
+	require.NoError(t, err)
+	upload := func(content string, contentType string) graphql.Upload {
+		return graphql.Upload{File: strings.NewReader(content), Filename: "tomato", Size: int64(len(content)), ContentType: contentType}
+	}
+
+	mp3 := "ID3\x03\x00 tomato"
+	recording, err := rm.UploadRecording(ctx, *globalID(t, word), upload(mp3, "audio/mpeg"), ptr(" en-GB "))
+	require.NoError(t, err)
+	assert.Equal(t, word.ID, recording.WordID)
+	assert.Equal(t, "en-GB", recording.Accent)
+	assert.Equal(t, "audio/mpeg", recording.ContentType)
+	assert.Equal(t, int64(len(mp3)), recording.Size)
+	ogg, err := rm.UploadRecording(ctx, *globalID(t, word), upload("OggS\x00 tomato", "application/octet-stream"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "audio/ogg", ogg.ContentType, "Types of uploads which are not audio are sniffed")
+
+	_, err = rm.UploadRecording(ctx, *globalID(t, word), upload("tomato", "text/plain"), nil)
+	assert.Equal(t, "VALIDATION", graph.ErrorPresenter(ctx, err).Extensions["code"])
+	_, err = rm.UploadRecording(ctx, *globalID(t, word), upload("", "audio/mpeg"), nil)
+	assert.Equal(t, "VALIDATION", graph.ErrorPresenter(ctx, err).Extensions["code"])
+	_, err = rm.UploadRecording(ctx, *globalID(t, &model.Word{ID: word.ID + 100}), upload(mp3, "audio/mpeg"), nil)
+	assert.Equal(t, "NOT_FOUND", graph.ErrorPresenter(ctx, err).Extensions["code"])
+	_, err = (&graph.Resolver{Store: s}).Mutation().UploadRecording(ctx, *globalID(t, word), upload(mp3, "audio/mpeg"), nil)
+	require.Error(t, err, "Uploads need a blob store")
+	assert.Equal(t, "VALIDATION", graph.ErrorPresenter(ctx, err).Extensions["code"])
+
+	url, err := resolver.Recording().URL(ctx, recording)
+	require.NoError(t, err)
+	response := queryGraphQL(t, s, `query ($id: ID!) { word(id: $id) { recordings { accent contentType size url } } }`,
+		map[string]interface{}{"id": *globalID(t, word)})
+	assert.JSONEq(t, fmt.Sprintf(`{"data": {"word": {"recordings": [
+		{"accent": "en-GB", "contentType": "audio/mpeg", "size": %d, "url": %q},
+		{"accent": "", "contentType": "audio/ogg", "size": 12, "url": "%s"}
+	]}}}`, len(mp3), url, "/audio/"+mustRecordingID(t, resolver, ogg)), response)
+
+	mux := http.NewServeMux()
+	mux.Handle(graph.RecordingPattern, graph.RecordingHandler(s, blobs))
+	get := func(url string, header http.Header) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, url, nil)
+		for key, values := range header {
+			request.Header[key] = values
+		}
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, request)
+		return recorder
+	}
+	served := get(url, nil)
+	assert.Equal(t, http.StatusOK, served.Code)
+	assert.Equal(t, "audio/mpeg", served.Header().Get("Content-Type"))
+	assert.Equal(t, mp3, served.Body.String())
+	partial := get(url, http.Header{"Range": {"bytes=0-2"}})
+	assert.Equal(t, http.StatusPartialContent, partial.Code, "Players can seek in recordings")
+	assert.Equal(t, "ID3", partial.Body.String())
+	cached := get(url, http.Header{"If-None-Match": {served.Header().Get("ETag")}})
+	assert.Equal(t, http.StatusNotModified, cached.Code)
+	assert.Equal(t, http.StatusNotFound, get("/audio/"+*globalID(t, word), nil).Code)
+
+	recordingID := mustRecordingID(t, resolver, recording)
+	deleted, err := rm.DeleteRecording(ctx, recordingID)
+	require.NoError(t, err)
+	assert.Equal(t, recording.ID, deleted.ID)
+	assert.Equal(t, http.StatusNotFound, get(url, nil).Code)
+	_, err = blobs.Open(ctx, recording.BlobKey)
+	assert.ErrorIs(t, err, blob.ErrNotFound, "Audio is deleted with its recording")
+	deleted, err = rm.DeleteRecording(ctx, recordingID)
+	require.NoError(t, err)
+	assert.Nil(t, deleted)
+
+	_, err = rm.DeleteWord(ctx, "tomato", "EN")
+	require.NoError(t, err)
+	_, err = blobs.Open(ctx, ogg.BlobKey)
+	assert.ErrorIs(t, err, blob.ErrNotFound, "Audio is deleted with the word")
+}
+
+// mustRecordingID returns the global ID of the recording.
+func mustRecordingID(t *testing.T, resolver *graph.Resolver, recording *model.Recording) string {
+	id, err := resolver.Recording().ID(context.Background(), recording)
+	require.NoError(t, err)
+	return id
+}
+
+func TestDump_Pronunciations(t *testing.T) {
+	s, rm := setupTestMutation(t)
+	ctx := context.Background()
+	word, err := rm.AddWord(ctx, "tomato", "EN", "")
+	require.NoError(t, err)
+	_, err = rm.AddPronunciation(ctx, *globalID(t, word), model.PronunciationInput{IPA: "/təˈmɑːtəʊ/", Accent: ptr("en-GB")})
+	require.NoError(t, err)
+	_, err = rm.AddPronunciation(ctx, *globalID(t, word), model.PronunciationInput{IPA: "/təˈmeɪtoʊ/"})
+	require.NoError(t, err)
+
+	var dump bytes.Buffer
+	require.NoError(t, exchange.Dump(ctx, s, &dump))
+	assert.Contains(t, dump.String(), `{"type":"pronunciation","wordId":`+strconv.Itoa(word.ID)+`,"ipa":"/təˈmɑːtəʊ/","accent":"en-GB"}`)
+
+	target := store.NewMemory(0)
+	require.NoError(t, database.SeedLanguages(ctx, target))
+	stats, err := exchange.Restore(ctx, target, bytes.NewReader(dump.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, 2, stats.PronunciationsCreated)
+	restored, err := target.FindWord(ctx, "tomato", "en")
+	require.NoError(t, err)
+	pronunciations, err := target.PronunciationsOf(ctx, []int{restored.ID})
+	require.NoError(t, err)
+	assert.Equal(t, []*model.Pronunciation{
+		{ID: pronunciations[restored.ID][0].ID, WordID: restored.ID, IPA: "/təˈmɑːtəʊ/", Accent: "en-GB"},
+		{ID: pronunciations[restored.ID][1].ID, WordID: restored.ID, IPA: "/təˈmeɪtoʊ/"},
+	}, pronunciations[restored.ID])
+
+	stats, err = exchange.Restore(ctx, target, bytes.NewReader(dump.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, 2, stats.Pronunciations)
+	assert.Equal(t, 0, stats.PronunciationsCreated, "Restoring twice adds no pronunciations")
+}
+
+func TestTags_Mutations(t *testing.T) {
+	s, rm := setupTestMutation(t)
+	ctx := context.Background()
+	var ids []string
+	for _, text := range []string{"passport", "ticket", "bandage"} {
+		word, err := rm.AddWord(ctx, text, "EN", "")
+		require.NoError(t, err)
+		ids = append(ids, *globalID(t, word))
+	}
+
+	tagged, err := rm.TagWords(ctx, ids[:2], []string{" Travel ", "travel", "documents"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, len(tagged))
+	_, err = rm.TagWords(ctx, ids[1:], []string{"travel"})
+	require.NoError(t, err, "Tagging a word twice keeps a single tag")
+	_, err = rm.TagWords(ctx, ids, []string{" "})
+	assert.Equal(t, "VALIDATION", graph.ErrorPresenter(ctx, err).Extensions["code"])
+	_, err = rm.TagWords(ctx, []string{ids[0], *globalID(t, &model.Word{ID: 1000})}, []string{"travel"})
+	assert.Equal(t, "NOT_FOUND", graph.ErrorPresenter(ctx, err).Extensions["code"])
+
+	response := queryGraphQL(t, s, `query ($id: ID!) {
+		word(id: $id) { tags { name } }
+		tags { name }
+		travel: words(filter: {tags: ["Travel"]}) { edges { node { text } } }
+		both: words(filter: {tags: ["travel", "documents"]}) { edges { node { text } } }
+	}`, map[string]interface{}{"id": ids[0]})
+	assert.JSONEq(t, `{"data": {
+		"word": {"tags": [{"name": "documents"}, {"name": "travel"}]},
+		"tags": [{"name": "documents"}, {"name": "travel"}],
+		"travel": {"edges": [{"node": {"text": "passport"}}, {"node": {"text": "ticket"}}, {"node": {"text": "bandage"}}]},
+		"both": {"edges": [{"node": {"text": "passport"}}, {"node": {"text": "ticket"}}]}
+	}}`, response, "Words match when they have every tag of the filter")
+
+	_, err = rm.UntagWords(ctx, ids[2:], []string{"travel"})
+	require.NoError(t, err)
+	tags, err := s.ListTags(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(tags))
+	page, err := s.ListWords(ctx, store.WordFilter{Tags: []string{"travel"}}, store.Page{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 2, page.Total)
+
+	tagID, err := (&graph.Resolver{Store: s}).Tag().ID(ctx, tags[0])
+	require.NoError(t, err)
+	deleted, err := rm.DeleteTag(ctx, tagID)
+	require.NoError(t, err)
+	assert.Equal(t, "documents", deleted.Name)
+	byWord, err := s.TagsOf(ctx, []int{tagged[0].ID})
+	require.NoError(t, err)
+	assert.Equal(t, []*model.Tag{{ID: tags[1].ID, Name: "travel"}}, byWord[tagged[0].ID])
+
+	_, err = rm.DeleteWord(ctx, "passport", "EN")
+	require.NoError(t, err)
+	page, err = s.ListWords(ctx, store.WordFilter{Tags: []string{"travel"}}, store.Page{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 1, page.Total, "Deleted words lose their tags")
+}
+
+func TestCollections_Mutations(t *testing.T) {
+	s, rm := setupTestMutation(t)
+	ctx := context.Background()
+	rc := (&graph.Resolver{Store: s}).Collection()
+	var ids []string
+	for _, text := range []string{"aspirin", "bandage", "ticket"} {
+		word, err := rm.AddWord(ctx, text, "EN", "")
+		require.NoError(t, err)
+		ids = append(ids, *globalID(t, word))
+	}
+
+	medical, err := rm.AddCollection(ctx, model.CollectionInput{Name: " Medical ", Description: ptr("At the pharmacy")})
+	require.NoError(t, err)
+	assert.Equal(t, "Medical", medical.Name)
+	_, err = rm.AddCollection(ctx, model.CollectionInput{Name: "Medical"})
+	assert.Equal(t, "CONFLICT", graph.ErrorPresenter(ctx, err).Extensions["code"])
+	_, err = rm.AddCollection(ctx, model.CollectionInput{Name: ""})
+	assert.Equal(t, "VALIDATION", graph.ErrorPresenter(ctx, err).Extensions["code"])
+	lesson, err := rm.AddCollection(ctx, model.CollectionInput{Name: "Lesson 4"})
+	require.NoError(t, err)
+
+	medicalID, err := rc.ID(ctx, medical)
+	require.NoError(t, err)
+	lessonID, err := rc.ID(ctx, lesson)
+	require.NoError(t, err)
+	_, err = rm.UpdateCollection(ctx, lessonID, model.UpdateCollectionInput{Name: ptr("Medical")})
+	assert.Equal(t, "CONFLICT", graph.ErrorPresenter(ctx, err).Extensions["code"])
+	lesson, err = rm.UpdateCollection(ctx, lessonID, model.UpdateCollectionInput{Description: ptr("Travel words")})
+	require.NoError(t, err)
+	assert.Equal(t, model.Collection{ID: lesson.ID, Name: "Lesson 4", Description: "Travel words"}, *lesson)
+
+	_, err = rm.AddToCollection(ctx, medicalID, ids[:2])
+	require.NoError(t, err)
+	_, err = rm.AddToCollection(ctx, medicalID, ids[1:2])
+	require.NoError(t, err, "Adding a word twice keeps it once")
+	_, err = rm.AddToCollection(ctx, lessonID, ids[1:])
+	require.NoError(t, err)
+	_, err = rm.AddToCollection(ctx, lessonID, []string{*globalID(t, &model.Word{ID: 1000})})
+	assert.Equal(t, "NOT_FOUND", graph.ErrorPresenter(ctx, err).Extensions["code"])
+
+	response := queryGraphQL(t, s, `query ($collection: ID!, $word: ID!) {
+		collections { name description words(first: 1) { totalCount edges { node { text } } } }
+		node(id: $collection) { ... on Collection { name } }
+		word(id: $word) { collections { name } }
+		words(filter: {collection: $collection}) { edges { node { text } } }
+	}`, map[string]interface{}{"collection": medicalID, "word": ids[1]})
+	assert.JSONEq(t, `{"data": {
+		"collections": [
+			{"name": "Lesson 4", "description": "Travel words", "words": {"totalCount": 2, "edges": [{"node": {"text": "bandage"}}]}},
+			{"name": "Medical", "description": "At the pharmacy", "words": {"totalCount": 2, "edges": [{"node": {"text": "aspirin"}}]}}
+		],
+		"node": {"name": "Medical"},
+		"word": {"collections": [{"name": "Lesson 4"}, {"name": "Medical"}]},
+		"words": {"edges": [{"node": {"text": "aspirin"}}, {"node": {"text": "bandage"}}]}
+	}}`, response)
+
+	_, err = rm.RemoveFromCollection(ctx, medicalID, ids[:1])
+	require.NoError(t, err)
+	page, err := s.ListWords(ctx, store.WordFilter{CollectionID: medical.ID}, store.Page{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 1, page.Total)
+
+	deleted, err := rm.DeleteCollection(ctx, medicalID)
+	require.NoError(t, err)
+	assert.Equal(t, medical.ID, deleted.ID)
+	deleted, err = rm.DeleteCollection(ctx, medicalID)
+	require.NoError(t, err)
+	assert.Nil(t, deleted)
+	assert.Equal(t, 3, countWords(t, s), "Words are kept when their collection is deleted")
+	_, err = (&graph.Resolver{Store: s}).Query().Words(ctx, nil, nil, nil, nil, &model.WordFilter{Collection: &medicalID})
+	assert.Equal(t, "NOT_FOUND", graph.ErrorPresenter(ctx, err).Extensions["code"])
+}
+
+func TestExportCollection(t *testing.T) {
+	s, rm := setupTestMutation(t)
+	ctx := context.Background()
+	_, err := rm.AddTranslation(ctx, "Plaster", "PL", "band-aid", "EN")
+	require.NoError(t, err)
+	_, err = rm.AddTranslation(ctx, "plaster", "PL", "Pflaster", "DE")
+	require.NoError(t, err)
+	_, err = rm.AddTranslation(ctx, "bilet", "PL", "ticket", "EN")
+	require.NoError(t, err)
+	_, err = rm.AddWord(ctx, "bandaż", "PL", "")
+	require.NoError(t, err)
+	plaster, err := s.FindWord(ctx, "plaster", "pl")
+	require.NoError(t, err)
+	bandaz, err := s.FindWord(ctx, "bandaż", "pl")
+	require.NoError(t, err)
+
+	collection, err := rm.AddCollection(ctx, model.CollectionInput{Name: "Medical"})
+	require.NoError(t, err)
+	collectionID, err := (&graph.Resolver{Store: s}).Collection().ID(ctx, collection)
+	require.NoError(t, err)
+	_, err = rm.AddToCollection(ctx, collectionID, []string{*globalID(t, plaster), *globalID(t, bandaz)})
+	require.NoError(t, err)
+
+	var file bytes.Buffer
+	require.NoError(t, exchange.ExportCollection(ctx, s, &file, "Medical", "English"))
+	assert.Equal(t, "source_text,source_language,target_text,target_language,example_usage\nPlaster,pl,band-aid,en,\n", file.String(),
+		"Words of the collection are exported with their translations into the target language")
+
+	file.Reset()
+	require.NoError(t, exchange.ExportCollection(ctx, s, &file, "Medical", ""))
+	assert.Equal(t, 3, strings.Count(file.String(), "\n"), "Without a target language every translation is exported")
+
+	target := store.NewMemory(0)
+	require.NoError(t, database.SeedLanguages(ctx, target))
+	report, err := exchange.ImportTranslations(ctx, target, bytes.NewReader(file.Bytes()), model.TranslationFileFormatCSV, false)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), report.Created, "Exported collections can be imported")
+
+	recorder := httptest.NewRecorder()
+	exchange.CollectionHandler(s).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/export/collection?name=Medical&target=de", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Plaster,pl,Pflaster,de,\n")
+	assert.Contains(t, recorder.Header().Get("Content-Disposition"), fmt.Sprintf("collection-%d.csv", collection.ID))
+	recorder = httptest.NewRecorder()
+	exchange.CollectionHandler(s).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/export/collection?name=Travel", nil))
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestDump_TagsAndCollections(t *testing.T) {
+	s, rm := setupTestMutation(t)
+	ctx := context.Background()
+	word, err := rm.AddWord(ctx, "passport", "EN", "")
+	require.NoError(t, err)
+	_, err = rm.TagWords(ctx, []string{*globalID(t, word)}, []string{"travel", "documents"})
+	require.NoError(t, err)
+	collection, err := rm.AddCollection(ctx, model.CollectionInput{Name: "Lesson 4", Description: ptr("At the airport")})
+	require.NoError(t, err)
+	collectionID, err := (&graph.Resolver{Store: s}).Collection().ID(ctx, collection)
+	require.NoError(t, err)
+	_, err = rm.AddToCollection(ctx, collectionID, []string{*globalID(t, word)})
+	require.NoError(t, err)
+
+	var dump bytes.Buffer
+	require.NoError(t, exchange.Dump(ctx, s, &dump))
+	assert.Contains(t, dump.String(), `"tags":["documents","travel"]`)
+	assert.Contains(t, dump.String(), `{"type":"collection","name":"Lesson 4","description":"At the airport","wordIds":[`+strconv.Itoa(word.ID)+`]}`)
+
+	target := store.NewMemory(0)
+	require.NoError(t, database.SeedLanguages(ctx, target))
+	stats, err := exchange.Restore(ctx, target, bytes.NewReader(dump.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.CollectionsCreated)
+	restored, err := target.FindWord(ctx, "passport", "en")
+	require.NoError(t, err)
+	page, err := target.ListWords(ctx, store.WordFilter{Tags: []string{"travel", "documents"}}, store.Page{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []*model.Word{restored}, page.Words)
+	restoredCollection, err := target.FindCollection(ctx, "Lesson 4")
+	require.NoError(t, err)
+	assert.Equal(t, "At the airport", restoredCollection.Description)
+	page, err = target.ListWords(ctx, store.WordFilter{CollectionID: restoredCollection.ID}, store.Page{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []*model.Word{restored}, page.Words)
+
+	stats, err = exchange.Restore(ctx, target, bytes.NewReader(dump.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.Collections)
+	assert.Equal(t, 0, stats.CollectionsCreated, "Restoring twice merges into the existing collection")
+}
+
+func TestLoaders_ConstantQueryCount(t *testing.T) {
+	// Statements are counted on a database of the test's own, so the test runs whatever TEST_STORE is.
+	db, err := database.Open(database.Config{Driver: database.DriverSQLite, SQLitePath: filepath.Join(t.TempDir(), "test.db")})
+	require.NoError(t, err, "Failed to open SQLite database")
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	var statements atomic.Int32
+	count := func(*gorm.DB) { statements.Add(1) }
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:count_query", count))
+	require.NoError(t, db.Callback().Row().After("gorm:row").Register("test:count_row", count))
+	s := store.NewGorm(db, 0)
+	rm := (&graph.Resolver{Store: s}).Mutation()
+
+	query := `query ($first: Int) {
+		words(first: $first, filter: {language: "en"}) {
+			edges { node { text translations(language: "pl") { text translations { text } } } }
+		}
+	}`
+	countStatements := func(words int) int32 {
+		for i := 0; i < words; i++ {
+			_, err := rm.AddTranslation(context.Background(), fmt.Sprintf("word%d", i), "EN", fmt.Sprintf("słowo%d", i), "PL")
+			require.NoError(t, err)
+			_, err = rm.AddTranslation(context.Background(), fmt.Sprintf("word%d", i), "EN", fmt.Sprintf("Wort%d", i), "DE")
+			require.NoError(t, err)
+		}
+		statements.Store(0)
+		response := queryGraphQL(t, s, query, map[string]interface{}{"first": words})
+		assert.NotContains(t, response, "errors")
+		assert.Equal(t, words, strings.Count(response, `"translations":[{"text":"word`), "Every word has a nested translation")
+		return statements.Load()
+	}
+
+	single := countStatements(1)
+	assert.Equal(t, single, countStatements(50), "Nested fields of 50 words take as many queries as of a single word")
+	assert.LessOrEqual(t, single, int32(8))
+}
+
+// setupTestEvents returns resolvers publishing changes of the test store to the returned bus.
+func setupTestEvents(t *testing.T) (*events.Bus, *graph.Resolver) {
+	bus := events.NewBus()
+	return bus, &graph.Resolver{Store: events.NewStore(setupTestEnv(t).store, bus), Events: bus}
+}
+
+// receive returns the next change of a subscription, failing when none arrives in time.
+func receive[T any](t *testing.T, changes <-chan *T) *T {
+	t.Helper()
+	select {
+	case change, ok := <-changes:
+		require.True(t, ok, "Subscription ended")
+		return change
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "No change received")
+		return nil
+	}
+}
+
+func TestSubscriptions_WordChanged(t *testing.T) {
+	_, r := setupTestEvents(t)
+	rm := r.Mutation()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	english, err := r.Subscription().WordChanged(ctx, ptr("English"))
+	require.NoError(t, err)
+	all, err := r.Subscription().WordChanged(ctx, nil)
+	require.NoError(t, err)
+
+	_, err = rm.AddWord(context.Background(), "cześć", "PL", "")
+	require.NoError(t, err)
+	hello, err := rm.AddWord(context.Background(), "Hello", "EN", "")
+	require.NoError(t, err)
+	_, err = rm.AddWord(context.Background(), "hello", "EN", "")
+	require.NoError(t, err, "Adding an existing word changes nothing")
+	_, err = rm.UpdateWord(context.Background(), globalID(t, hello), nil, model.UpdateWordInput{Language: ptr("DE")})
+	require.NoError(t, err)
+	_, err = rm.DeleteWord(context.Background(), "hello", "DE")
+	require.NoError(t, err)
+	_, err = rm.AddWord(context.Background(), "bye", "EN", "")
+	require.NoError(t, err)
+
+	change := receive(t, english)
+	assert.Equal(t, model.ChangeKindCreated, change.Kind)
+	assert.Equal(t, "Hello", change.Word.DisplayText)
+	change = receive(t, english)
+	assert.Equal(t, model.ChangeKindUpdated, change.Kind, "Words moved out of the language are reported")
+	assert.Equal(t, "de", change.Word.Language)
+	change = receive(t, english)
+	assert.Equal(t, model.ChangeKindCreated, change.Kind, "Deletion of a German word is not reported")
+	assert.Equal(t, "bye", change.Word.Text)
+
+	var kinds []model.ChangeKind
+	for i := 0; i < 5; i++ {
+		kinds = append(kinds, receive(t, all).Kind)
+	}
+	assert.Equal(t, []model.ChangeKind{model.ChangeKindCreated, model.ChangeKindCreated, model.ChangeKindUpdated, model.ChangeKindDeleted, model.ChangeKindCreated}, kinds)
+
+	cancel()
+	for range english {
+	}
+}
+
+func TestSubscriptions_TranslationChanged(t *testing.T) {
+	_, r := setupTestEvents(t)
+	rm := r.Mutation()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dog, err := rm.AddWord(context.Background(), "dog", "EN", "")
+	require.NoError(t, err)
+	changes, err := r.Subscription().TranslationChanged(ctx, globalID(t, dog))
+	require.NoError(t, err)
+
+	_, err = rm.AddTranslation(context.Background(), "cat", "EN", "kot", "PL")
+	require.NoError(t, err)
+	_, err = rm.AddTranslations(context.Background(), []*model.TranslationInput{
+		{SourceText: "dog", SourceTextLanguage: "EN", TranslatedText: "Hund", TranslatedTextLanguage: "DE"},
+		{SourceText: "dog", SourceTextLanguage: "xx", TranslatedText: "Hund", TranslatedTextLanguage: "DE"},
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, countTranslations(t, r.Store), "The rejected batch is rolled back")
+	translation, err := rm.AddTranslation(context.Background(), "dog", "EN", "pies", "PL")
+	require.NoError(t, err)
+	_, err = rm.DeleteWord(context.Background(), "dog", "EN")
+	require.NoError(t, err)
+
+	change := receive(t, changes)
+	assert.Equal(t, model.ChangeKindCreated, change.Kind, "Rolled back changes are not reported")
+	assert.Equal(t, *translation, *change.Translation)
+	change = receive(t, changes)
+	assert.Equal(t, model.ChangeKindDeleted, change.Kind, "Translations deleted with the word are reported")
+	assert.Equal(t, *translation, *change.Translation)
+
+	_, err = r.Subscription().TranslationChanged(ctx, ptr("not an ID"))
+	assert.ErrorContains(t, err, "invalid ID")
+}
+
+func TestSubscriptions_Websocket(t *testing.T) {
+	bus, r := setupTestEvents(t)
+	c := client.New(newTestHandler(r.Store, bus))
+
+	subscription := c.Websocket(`subscription { wordChanged(language: "en") { kind word { text } } }`)
+	defer subscription.Close()
+
+	// The subscription starts asynchronously, so words are added until one of them is reported.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		for i := 0; ctx.Err() == nil; i++ {
+			if _, err := r.Mutation().AddWord(ctx, fmt.Sprintf("word%d", i), "EN", ""); err != nil {
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+
+	var response struct {
+		WordChanged struct {
+			Kind string
+			Word struct{ Text string }
+		}
+	}
+	require.NoError(t, subscription.Next(&response))
+	assert.Equal(t, "CREATED", response.WordChanged.Kind)
+	assert.True(t, strings.HasPrefix(response.WordChanged.Word.Text, "word"))
+}
+
+func ptr[T any](value T) *T {
+	return &value
+}
+
+func wordTexts(words []*model.Word) []string {
+	texts := make([]string, len(words))
+	for i, word := range words {
+		texts[i] = word.Text
+	}
+	return texts
+}
+
+// newTestHandler returns the GraphQL handler of the server for the store, bus may be nil.
+func newTestHandler(s store.DictionaryStore, bus *events.Bus) *handler.Server {
+	srv := handler.New(graph.NewExecutableSchema(graph.Config{Resolvers: &graph.Resolver{Store: s, Events: bus}}))
+	srv.SetErrorPresenter(graph.ErrorPresenter)
+	srv.AroundResponses(graph.LoaderMiddleware(s))
+	srv.AddTransport(transport.POST{})
+	srv.AddTransport(transport.Websocket{KeepAlivePingInterval: time.Second})
+	return srv
+}
+
+// queryGraphQL runs a GraphQL request against the store like the server does, returning the JSON response.
+func queryGraphQL(t *testing.T, s store.DictionaryStore, query string, variables map[string]interface{}) string {
+	srv := newTestHandler(s, nil)
+
+	body, err := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	require.NoError(t, err)
+	request := httptest.NewRequest(http.MethodPost, "/query", bytes.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	srv.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	return recorder.Body.String()
+}
+
+// globalID returns the ID of the word exposed by the schema.
+func globalID(t *testing.T, word *model.Word) *string {
+	id, err := (&graph.Resolver{}).Word().ID(context.Background(), word)
+	require.NoError(t, err)
+	return &id
 }
 
 func RunConcurrentTest(t *testing.T, numGoroutines int, testFunc func(i int) error) {